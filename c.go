@@ -1,8 +1,8 @@
 package colfer
 
 import (
-	"os"
-	"path/filepath"
+	"bytes"
+	"path"
 	"strings"
 	"text/template"
 
@@ -22,15 +22,34 @@ var cKeywords = map[string]struct{}{
 	"unsigned": {}, "void": {}, "volatile": {}, "while": {},
 }
 
-// GenerateC writes the code into file "Colfer.h" and "Colfer.c".
+// GenerateC writes the code of each package into a "<package>.h" and a
+// "<package>.c" file. The globals for limits and allocation have the package
+// name as a prefix, such that independently compiled packages can be linked
+// together.
 func GenerateC(basedir string, packages Packages) error {
+	files, err := GenerateCFiles(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// GenerateCFiles returns the code of GenerateC.
+func GenerateCFiles(packages Packages) (Files, error) {
+	return generateCFiles(packages, cKeywords)
+}
+
+// GenerateCFiles returns the code of GenerateC, with a suffix for field names
+// in keywords.
+func generateCFiles(packages Packages, keywords map[string]struct{}) (Files, error) {
 	for _, p := range packages {
+		p.NameNative = strings.ToLower(name.SnakeCase(p.Name))
 		for _, t := range p.Structs {
 			t.NameNative = strings.ToLower(name.SnakeCase(p.Name + "_" + t.Name))
 
 			for _, f := range t.Fields {
 				f.NameNative = strings.ToLower(name.SnakeCase(f.Name))
-				if _, ok := cKeywords[f.NameNative]; ok {
+				if _, ok := keywords[f.NameNative]; ok {
 					f.NameNative += "_"
 				}
 
@@ -52,61 +71,85 @@ func GenerateC(basedir string, packages Packages) error {
 		}
 	}
 
-	if err := os.MkdirAll(basedir, os.ModeDir|os.ModePerm); err != nil {
-		return err
-	}
-
-	f, err := os.Create(filepath.Join(basedir, "Colfer.h"))
-	if err != nil {
-		return err
-	}
-	if err := template.Must(template.New("C-header").Parse(cHeaderTemplate)).Execute(f, packages); err != nil {
-		return err
-	}
-	if err = f.Close(); err != nil {
-		return err
+	funcs := template.FuncMap{
+		"upper": strings.ToUpper,
+		"base":  path.Base,
+		// the header path of package to, relative to package from
+		"include": func(from, to *Package) string {
+			return relativePath(from.Name, to.Name) + ".h"
+		},
 	}
+	headerTemplate := template.Must(template.New("C-header").Funcs(funcs).Parse(cHeaderTemplate))
+	codeTemplate := template.Must(template.New("C").Funcs(funcs).Parse(cTemplate))
 
-	f, err = os.Create(filepath.Join(basedir, "Colfer.c"))
-	if err != nil {
-		return err
-	}
-	if err := template.Must(template.New("C").Parse(cTemplate)).Execute(f, packages); err != nil {
-		return err
+	files := make(Files, 2*len(packages))
+	for _, p := range packages {
+		var header, code bytes.Buffer
+		if err := headerTemplate.Execute(&header, p); err != nil {
+			return nil, err
+		}
+		if err := codeTemplate.Execute(&code, p); err != nil {
+			return nil, err
+		}
+		files[p.Name+".h"] = header.Bytes()
+		files[p.Name+".c"] = code.Bytes()
 	}
-	return f.Close()
+	return files, nil
 }
 
 const cHeaderTemplate = `// Code generated by colf(1); DO NOT EDIT.
-{{- range .}}
 // The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
-{{- end}}
 
-#ifndef COLFER_H
-#define COLFER_H
+#ifndef COLFER_{{upper .NameNative}}_H
+#define COLFER_{{upper .NameNative}}_H
 
 #include <limits.h>
+#include <stddef.h>
 #include <stdint.h>
 #include <string.h>
 {{- if .HasTimestamp}}
 #include <time.h>
 {{end}}
-
-#if CHAR_BIT != 8
-#error "octet byte size"
-#endif
+{{- range .Refs}}
+#include "{{include $ .}}"
+{{- end}}
 
 #ifdef __cplusplus
 extern "C" {
 #endif
 
+#ifndef COLFER_TYPES
+#define COLFER_TYPES
 
-// colfer_size_max is the upper limit for serial octet sizes.
-extern size_t colfer_size_max;
-
-// colfer_list_max is the upper limit for the number of elements in a list.
-extern size_t colfer_list_max;
+#if CHAR_BIT != 8
+#error "octet byte size"
+#endif
 
+// colfer_allocator has the memory management callbacks for unmarshalling.
+typedef struct {
+	// malloc_func returns size octets of memory, or NULL when out of memory.
+	void* (*malloc_func)(void* ctx, size_t size);
+	// free_func releases memory from malloc_func. It is never called with NULL.
+	void (*free_func)(void* ctx, void* ptr);
+	// ctx is passed to each call, e.g., for arena allocation.
+	void* ctx;
+} colfer_allocator;
+
+// colfer_reader buffers a stream of serials for the _read functions.
+typedef struct {
+	// read_func reads up to n octets into buf, like read(2) does. The
+	// return is the number of octets read, zero on end of stream, or a
+	// negative value with errno set on error.
+	ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n);
+	// ctx is passed to each call, e.g., for a file descriptor.
+	void* ctx;
+	// alloc manages buf.
+	colfer_allocator alloc;
+	uint8_t* buf;
+	size_t   cap;
+	size_t   off;
+	size_t   len;
+} colfer_reader;
 
 // colfer_text is a UTF-8 CLOB.
 typedef struct {
@@ -120,10 +163,31 @@ typedef struct {
 	size_t   len;
 } colfer_binary;
 
-{{range .}}{{range .Structs}}
+#endif // COLFER_TYPES
+
+
+// {{.NameNative}}_size_max is the upper limit for serial octet sizes.
+extern size_t {{.NameNative}}_size_max;
+
+// {{.NameNative}}_list_max is the upper limit for the number of list elements.
+extern size_t {{.NameNative}}_list_max;
+
+// {{.NameNative}}_alloc is the allocator in use, which defaults to
+// malloc(3) and free(3), both with a NULL ctx.
+extern colfer_allocator {{.NameNative}}_alloc;
+
+// {{.NameNative}}_reader_init sets up r for read_func with ctx. The buffer
+// grows on demand, up to {{.NameNative}}_size_max, with {{.NameNative}}_alloc.
+void {{.NameNative}}_reader_init(colfer_reader* r, ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n), void* ctx);
+
+// {{.NameNative}}_reader_release frees the buffer of r. Any data pending is
+// discarded.
+void {{.NameNative}}_reader_release(colfer_reader* r);
+
+{{range .Structs}}
 typedef struct {{.NameNative}} {{.NameNative}};
-{{end}}{{end}}
-{{range .}}{{range .Structs}}
+{{end}}
+{{range .Structs}}
 {{.DocText "// "}}
 struct {{.NameNative}} {
 {{- range .Fields}}
@@ -153,7 +217,7 @@ struct {{.NameNative}} {
  {{- if eq .Type "timestamp"}}
 	struct {{.TypeNative}}
  {{- else if .TypeRef}}
-	{{.TypeRef.NameNative}}*
+	struct {{.TypeRef.NameNative}}*
  {{- else}}
 	{{.TypeNative}}
  {{- end}}
@@ -163,7 +227,7 @@ struct {{.NameNative}} {
 
 // {{.NameNative}}_marshal_len returns the Colfer serial octet size.
 // When the return is zero then errno is set to EFBIG to indicate a breach of
-// either colfer_size_max or colfer_list_max.
+// either {{$.NameNative}}_size_max or {{$.NameNative}}_list_max.
 size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o);
 
 // {{.NameNative}}_marshal encodes o as Colfer into buf and returns the number
@@ -172,12 +236,28 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf);
 
 // {{.NameNative}}_unmarshal decodes data as Colfer into o and returns the
 // number of octets read. The data is read up to a maximum of datalen or
-// colfer_size_max, whichever occurs first.
-// When the return is zero then errno is set to one of the following 3 values:
-// EWOULDBLOCK on incomplete data, EFBIG on a breach of either colfer_size_max
-// or colfer_list_max and EILSEQ on schema mismatch.
+// {{$.NameNative}}_size_max, whichever occurs first.
+// When the return is zero then errno is set to one of the following 4 values:
+// EWOULDBLOCK on incomplete data, EFBIG on a breach of either
+// {{$.NameNative}}_size_max or {{$.NameNative}}_list_max, EILSEQ on schema
+// mismatch and ENOMEM when {{$.NameNative}}_alloc failed. Memory is allocated
+// with {{$.NameNative}}_alloc, also on error.
 size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t datalen);
-{{end}}{{end}}
+
+// {{.NameNative}}_free releases all memory referenced by o, as allocated by
+// {{.NameNative}}_unmarshal, including any nested structs. The struct itself is
+// not released. Instead, o is zeroed, ready for reuse.
+void {{.NameNative}}_free({{.NameNative}}* o);
+
+// {{.NameNative}}_read decodes the next serial from r into o and returns the
+// number of octets read. When the return is zero then errno is set to zero on
+// end of stream, EIO when the stream ends halfway a serial, or to any of the
+// errors from either {{.NameNative}}_unmarshal or the read_func of r. Reads
+// may continue after an error from read_func, such as EWOULDBLOCK, as all data
+// received remains buffered. Memory is allocated as with
+// {{.NameNative}}_unmarshal.
+size_t {{.NameNative}}_read({{.NameNative}}* o, colfer_reader* r);
+{{end}}
 
 #ifdef __cplusplus
 } // extern "C"
@@ -187,11 +267,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 `
 
 const cTemplate = `// Code generated by colf(1); DO NOT EDIT.
-{{- range .}}
 // The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
-{{- end}}
 
-#include "Colfer.h"
+#include "{{base .Name}}.h"
 #include <errno.h>
 #include <stdlib.h>
 {{- if .HasTimestamp}}
@@ -207,12 +285,84 @@ const cTemplate = `// Code generated by colf(1); DO NOT EDIT.
 #define COLFER_ENDIAN
 #endif
 
-{{with index . 0}}
-size_t colfer_size_max = {{.SizeMax}};
-size_t colfer_list_max = {{.ListMax}};
-{{end}}
+size_t {{.NameNative}}_size_max = {{.SizeMax}};
+size_t {{.NameNative}}_list_max = {{.ListMax}};
+
+static void* colfer_std_malloc(void* ctx, size_t size) {
+	(void) ctx;
+	return malloc(size);
+}
+
+static void colfer_std_free(void* ctx, void* ptr) {
+	(void) ctx;
+	free(ptr);
+}
+
+colfer_allocator {{.NameNative}}_alloc = {colfer_std_malloc, colfer_std_free, NULL};
+
+// colfer_malloc allocates with {{.NameNative}}_alloc.
+static void* colfer_malloc(size_t size) {
+	return {{.NameNative}}_alloc.malloc_func({{.NameNative}}_alloc.ctx, size);
+}
+
+// colfer_free releases with {{.NameNative}}_alloc, if ptr is not NULL.
+static void colfer_free(const void* ptr) {
+	if (ptr) {{.NameNative}}_alloc.free_func({{.NameNative}}_alloc.ctx, (void*) ptr);
+}
+
+void {{.NameNative}}_reader_init(colfer_reader* r, ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n), void* ctx) {
+	memset(r, 0, sizeof(colfer_reader));
+	r->read_func = read_func;
+	r->ctx = ctx;
+	r->alloc = {{.NameNative}}_alloc;
+}
+
+void {{.NameNative}}_reader_release(colfer_reader* r) {
+	if (r->buf) r->alloc.free_func(r->alloc.ctx, r->buf);
+	r->buf = NULL;
+	r->cap = r->off = r->len = 0;
+}
+
+// colfer_reader_fill reads more data into r. The return is zero on end of
+// stream or error, with errno set accordingly.
+static size_t colfer_reader_fill(colfer_reader* r) {
+	if (r->off) {
+		memmove(r->buf, r->buf + r->off, r->len);
+		r->off = 0;
+	}
+
+	if (r->len == r->cap) {
+		size_t c = r->cap ? r->cap * 2 : 4096;
+		if (c > {{.NameNative}}_size_max || c < r->cap) c = {{.NameNative}}_size_max;
+		if (c <= r->len) {
+			errno = EFBIG;
+			return 0;
+		}
+
+		uint8_t* buf = r->alloc.malloc_func(r->alloc.ctx, c);
+		if (!buf) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (r->buf) {
+			memcpy(buf, r->buf, r->len);
+			r->alloc.free_func(r->alloc.ctx, r->buf);
+		}
+		r->buf = buf;
+		r->cap = c;
+	}
 
-{{range .}}{{range .Structs}}
+	ptrdiff_t n = r->read_func(r->ctx, r->buf + r->len, r->cap - r->len);
+	if (n < 0) return 0;
+	if (n == 0) {
+		errno = r->len ? EIO : 0;
+		return 0;
+	}
+	r->len += (size_t) n;
+	return (size_t) n;
+}
+
+{{range .Structs}}
 size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	size_t l = 1;
 {{range .Fields}}{{if eq .Type "bool"}}
@@ -270,7 +420,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{$.NameNative}}_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -285,7 +435,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{$.NameNative}}_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -306,7 +456,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
  {{- if not .TypeList}}
 	{
 		size_t n = o->{{.NameNative}}.len;
-		if (n > colfer_size_max) {
+		if (n > {{$.NameNative}}_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -316,21 +466,21 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{$.NameNative}}_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_text* a = o->{{.NameNative}}.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > {{$.NameNative}}_size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > {{$.NameNative}}_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -341,7 +491,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
  {{- if not .TypeList}}
 	{
 		size_t n = o->{{.NameNative}}.len;
-		if (n > colfer_size_max) {
+		if (n > {{$.NameNative}}_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -351,21 +501,21 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{$.NameNative}}_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_binary* a = o->{{.NameNative}}.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > {{$.NameNative}}_size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > {{$.NameNative}}_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -381,14 +531,14 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{$.NameNative}}_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			{{.TypeRef.NameNative}}* a = o->{{.NameNative}}.list;
 			for (size_t i = 0; i < n; ++i) l += {{.TypeRef.NameNative}}_marshal_len(&a[i]);
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > {{$.NameNative}}_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -396,7 +546,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	}
  {{- end}}
 {{end}}{{end}}
-	if (l > colfer_size_max) {
+	if (l > {{$.NameNative}}_size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -753,11 +903,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 	const uint8_t* p = data;
 	const uint8_t* end;
 	int enderr;
-	if (datalen < colfer_size_max) {
+	if (datalen < {{$.NameNative}}_size_max) {
 		end = p + datalen;
 		enderr = EWOULDBLOCK;
 	} else {
-		end = p + colfer_size_max;
+		end = p + {{$.NameNative}}_size_max;
 		enderr = EFBIG;
 	}
 
@@ -974,7 +1124,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{$.NameNative}}_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -982,10 +1132,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			errno = enderr;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		float* fp = malloc(n * 4);
+		float* fp = colfer_malloc(n * 4);
+		if (!fp && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->{{.NameNative}}.list = fp;
+		o->{{.NameNative}}.len = n;
 #ifdef COLFER_ENDIAN
 		memcpy(fp, p, n * 4);
 		p += n * 4;
@@ -1048,7 +1201,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{$.NameNative}}_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1056,10 +1209,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			errno = enderr;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		double* fp = malloc(n * 8);
+		double* fp = colfer_malloc(n * 8);
+		if (!fp && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->{{.NameNative}}.list = fp;
+		o->{{.NameNative}}.len = n;
 #ifdef COLFER_ENDIAN
 		memcpy(fp, p, n * 8);
 		p += n * 8;
@@ -1140,7 +1296,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > {{$.NameNative}}_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1148,10 +1304,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			errno = enderr;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		void* a = malloc(n);
+		void* a = colfer_malloc(n);
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->{{.NameNative}}.utf8 = (char*) a;
+		o->{{.NameNative}}.len = n;
 		if (n) {
 			memcpy(a, p, n);
 			p += n;
@@ -1180,14 +1339,18 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{$.NameNative}}_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		colfer_text* text = malloc(n * sizeof(colfer_text));
+		colfer_text* text = colfer_malloc(n * sizeof(colfer_text));
+		if (!text && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(text, 0, n * sizeof(colfer_text));
 		o->{{.NameNative}}.list = text;
+		o->{{.NameNative}}.len = n;
 		for (; n; --n, ++text) {
 			if (p >= end) {
 				errno = enderr;
@@ -1209,7 +1372,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > {{$.NameNative}}_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1217,10 +1380,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				errno = enderr;
 				return 0;
 			}
-			text->len = len;
-
-			char* a = malloc(len);
+			char* a = colfer_malloc(len);
+			if (!a && len) {
+				errno = ENOMEM;
+				return 0;
+			}
 			text->utf8 = a;
+			text->len = len;
 			if (len) {
 				memcpy(a, p, len);
 				p += len;
@@ -1257,7 +1423,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > {{$.NameNative}}_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1265,10 +1431,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			errno = enderr;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		void* a = malloc(n);
+		void* a = colfer_malloc(n);
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->{{.NameNative}}.octets = (uint8_t*) a;
+		o->{{.NameNative}}.len = n;
 		if (n) {
 			memcpy(a, p, n);
 			p += n;
@@ -1297,14 +1466,18 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{$.NameNative}}_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
-		o->{{.NameNative}}.len = n;
-
-		colfer_binary* binary = malloc(n * sizeof(colfer_binary));
+		colfer_binary* binary = colfer_malloc(n * sizeof(colfer_binary));
+		if (!binary && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(binary, 0, n * sizeof(colfer_binary));
 		o->{{.NameNative}}.list = binary;
+		o->{{.NameNative}}.len = n;
 		for (; n; --n, ++binary) {
 			if (p >= end) {
 				errno = enderr;
@@ -1326,7 +1499,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > {{$.NameNative}}_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1334,10 +1507,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				errno = enderr;
 				return 0;
 			}
-			binary->len = len;
-
-			uint8_t* a = malloc(len);
+			uint8_t* a = colfer_malloc(len);
+			if (!a && len) {
+				errno = ENOMEM;
+				return 0;
+			}
 			binary->octets = a;
+			binary->len = len;
 			if (len) {
 				memcpy(a, p, len);
 				p += len;
@@ -1354,7 +1530,13 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 {{else}}
  {{- if not .TypeList}}
 	if (header == {{.Index}}) {
-		o->{{.NameNative}} = calloc(1, sizeof({{.TypeRef.NameNative}}));
+		{{.TypeRef.NameNative}}* ref = colfer_malloc(sizeof({{.TypeRef.NameNative}}));
+		if (!ref) {
+			errno = ENOMEM;
+			return 0;
+		}
+		memset(ref, 0, sizeof({{.TypeRef.NameNative}}));
+		o->{{.NameNative}} = ref;
 		size_t read = {{.TypeRef.NameNative}}_unmarshal(o->{{.NameNative}}, p, (size_t) (end - p));
 		if (!read) {
 			if (errno == EWOULDBLOCK) errno = enderr;
@@ -1390,12 +1572,19 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{$.NameNative}}_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
 
-		{{.TypeRef.NameNative}}* a = calloc(n, sizeof({{.TypeRef.NameNative}}));
+		{{.TypeRef.NameNative}}* a = colfer_malloc(n * sizeof({{.TypeRef.NameNative}}));
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(a, 0, n * sizeof({{.TypeRef.NameNative}}));
+		o->{{.NameNative}}.list = a;
+		o->{{.NameNative}}.len = n;
 		for (size_t i = 0; i < n; ++i) {
 			size_t read = {{.TypeRef.NameNative}}_unmarshal(&a[i], p, (size_t) (end - p));
 			if (!read) {
@@ -1404,8 +1593,6 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			}
 			p += read;
 		}
-		o->{{.NameNative}}.len = n;
-		o->{{.NameNative}}.list = a;
 
 		if (p >= end) {
 			errno = enderr;
@@ -1422,4 +1609,54 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 
 	return (size_t) (p - (const uint8_t*) data);
 }
-{{end}}{{end}}`
+
+void {{.NameNative}}_free({{.NameNative}}* o) {
+{{- range .Fields}}
+ {{- if .TypeList}}
+  {{- if eq .Type "text"}}
+	for (size_t i = 0; o->{{.NameNative}}.list && i < o->{{.NameNative}}.len; ++i)
+		colfer_free(o->{{.NameNative}}.list[i].utf8);
+	colfer_free(o->{{.NameNative}}.list);
+  {{- else if eq .Type "binary"}}
+	for (size_t i = 0; o->{{.NameNative}}.list && i < o->{{.NameNative}}.len; ++i)
+		colfer_free(o->{{.NameNative}}.list[i].octets);
+	colfer_free(o->{{.NameNative}}.list);
+  {{- else if .TypeRef}}
+	for (size_t i = 0; o->{{.NameNative}}.list && i < o->{{.NameNative}}.len; ++i)
+		{{.TypeRef.NameNative}}_free(&o->{{.NameNative}}.list[i]);
+	colfer_free(o->{{.NameNative}}.list);
+  {{- else}}
+	colfer_free(o->{{.NameNative}}.list);
+  {{- end}}
+ {{- else if eq .Type "text"}}
+	colfer_free(o->{{.NameNative}}.utf8);
+ {{- else if eq .Type "binary"}}
+	colfer_free(o->{{.NameNative}}.octets);
+ {{- else if .TypeRef}}
+	if (o->{{.NameNative}}) {
+		{{.TypeRef.NameNative}}_free(o->{{.NameNative}});
+		colfer_free(o->{{.NameNative}});
+	}
+ {{- end}}
+{{- end}}
+	memset(o, 0, sizeof({{.NameNative}}));
+}
+
+size_t {{.NameNative}}_read({{.NameNative}}* o, colfer_reader* r) {
+	for (;;) {
+		if (r->len) {
+			size_t n = {{.NameNative}}_unmarshal(o, r->buf + r->off, r->len);
+			if (n) {
+				r->off += n;
+				r->len -= n;
+				return n;
+			}
+			if (errno != EWOULDBLOCK) return 0;
+			// partial allocations are redone on the next attempt
+			{{.NameNative}}_free(o);
+		}
+
+		if (!colfer_reader_fill(r)) return 0;
+	}
+}
+{{end}}`
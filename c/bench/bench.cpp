@@ -1,4 +1,4 @@
-#include "Colfer.h"
+#include "bench.h"
 #include "scheme.pb.h" // ProtoBuf
 #include "scheme_generated.h" // FlatBuffers
 
@@ -18,7 +18,7 @@ const size_t test_data_len = sizeof(test_data) / sizeof(bench_colfer);
 
 
 static void BM_marshal_colfer(benchmark::State& state) {
-	void* buf = malloc(colfer_size_max);
+	void* buf = malloc(bench_size_max);
 
 	for (int i = 0; state.KeepRunning(); i++) {
 		auto data = &test_data[i % test_data_len];
@@ -32,7 +32,7 @@ static void BM_marshal_colfer(benchmark::State& state) {
 static void BM_unmarshal_colfer(benchmark::State& state) {
 	void* serials[test_data_len];
 	for (size_t i = 0; i < test_data_len; i++) {
-		serials[i] = malloc(colfer_size_max);
+		serials[i] = malloc(bench_size_max);
 		bench_colfer_marshal(&test_data[i], serials[i]);
 	}
 
@@ -41,7 +41,7 @@ static void BM_unmarshal_colfer(benchmark::State& state) {
 	for (int i = 0; state.KeepRunning(); i++) {
 		auto serial = serials[i % test_data_len];
 
-                benchmark::DoNotOptimize(bench_colfer_unmarshal(o, serial, colfer_size_max));
+                benchmark::DoNotOptimize(bench_colfer_unmarshal(o, serial, bench_size_max));
                 benchmark::DoNotOptimize(o);
                 benchmark::ClobberMemory();
         }
@@ -104,7 +104,7 @@ static void BM_unmarshal_protobuf(benchmark::State& state) {
 }
 
 static void BM_marshal_flatbuffers(benchmark::State& state) {
-	flatbuffers::FlatBufferBuilder fbb(colfer_size_max);
+	flatbuffers::FlatBufferBuilder fbb(bench_size_max);
 
 	for (int i = 0; state.KeepRunning(); i++) {
 		auto data = test_data[i % test_data_len];
@@ -122,7 +122,7 @@ static void BM_marshal_flatbuffers(benchmark::State& state) {
 }
 
 static void BM_unmarshal_flatbuffers(benchmark::State& state) {
-	flatbuffers::FlatBufferBuilder fbb(colfer_size_max);
+	flatbuffers::FlatBufferBuilder fbb(bench_size_max);
 
 	void* serials[test_data_len];
 	for (size_t i = 0; i < test_data_len; ++i) {
@@ -1,7 +1,7 @@
 // Code generated by colf(1); DO NOT EDIT.
 // The compiler used schema file test.colf for package gen.
 
-#include "Colfer.h"
+#include "gen.h"
 #include <errno.h>
 #include <stdlib.h>
 #include <time.h>
@@ -16,10 +16,82 @@
 #define COLFER_ENDIAN
 #endif
 
+size_t gen_size_max = 16 * 1024 * 1024;
+size_t gen_list_max = 64 * 1024;
 
-size_t colfer_size_max = 16 * 1024 * 1024;
-size_t colfer_list_max = 64 * 1024;
+static void* colfer_std_malloc(void* ctx, size_t size) {
+	(void) ctx;
+	return malloc(size);
+}
+
+static void colfer_std_free(void* ctx, void* ptr) {
+	(void) ctx;
+	free(ptr);
+}
+
+colfer_allocator gen_alloc = {colfer_std_malloc, colfer_std_free, NULL};
+
+// colfer_malloc allocates with gen_alloc.
+static void* colfer_malloc(size_t size) {
+	return gen_alloc.malloc_func(gen_alloc.ctx, size);
+}
+
+// colfer_free releases with gen_alloc, if ptr is not NULL.
+static void colfer_free(const void* ptr) {
+	if (ptr) gen_alloc.free_func(gen_alloc.ctx, (void*) ptr);
+}
 
+void gen_reader_init(colfer_reader* r, ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n), void* ctx) {
+	memset(r, 0, sizeof(colfer_reader));
+	r->read_func = read_func;
+	r->ctx = ctx;
+	r->alloc = gen_alloc;
+}
+
+void gen_reader_release(colfer_reader* r) {
+	if (r->buf) r->alloc.free_func(r->alloc.ctx, r->buf);
+	r->buf = NULL;
+	r->cap = r->off = r->len = 0;
+}
+
+// colfer_reader_fill reads more data into r. The return is zero on end of
+// stream or error, with errno set accordingly.
+static size_t colfer_reader_fill(colfer_reader* r) {
+	if (r->off) {
+		memmove(r->buf, r->buf + r->off, r->len);
+		r->off = 0;
+	}
+
+	if (r->len == r->cap) {
+		size_t c = r->cap ? r->cap * 2 : 4096;
+		if (c > gen_size_max || c < r->cap) c = gen_size_max;
+		if (c <= r->len) {
+			errno = EFBIG;
+			return 0;
+		}
+
+		uint8_t* buf = r->alloc.malloc_func(r->alloc.ctx, c);
+		if (!buf) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (r->buf) {
+			memcpy(buf, r->buf, r->len);
+			r->alloc.free_func(r->alloc.ctx, r->buf);
+		}
+		r->buf = buf;
+		r->cap = c;
+	}
+
+	ptrdiff_t n = r->read_func(r->ctx, r->buf + r->len, r->cap - r->len);
+	if (n < 0) return 0;
+	if (n == 0) {
+		errno = r->len ? EIO : 0;
+		return 0;
+	}
+	r->len += (size_t) n;
+	return (size_t) n;
+}
 
 
 size_t gen_o_marshal_len(const gen_o* o) {
@@ -81,7 +153,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 
 	{
 		size_t n = o->s.len;
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -90,7 +162,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 
 	{
 		size_t n = o->a.len;
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -104,14 +176,14 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->os.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > gen_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			gen_o* a = o->os.list;
 			for (size_t i = 0; i < n; ++i) l += gen_o_marshal_len(&a[i]);
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > gen_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -121,21 +193,21 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->ss.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > gen_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_text* a = o->ss.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > gen_size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > gen_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -145,21 +217,21 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->as.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > gen_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_binary* a = o->as.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > gen_size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > gen_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -176,7 +248,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->f32s.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > gen_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -187,7 +259,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->f64s.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > gen_list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -195,7 +267,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 		}
 	}
 
-	if (l > colfer_size_max) {
+	if (l > gen_size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -542,11 +614,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 	const uint8_t* p = data;
 	const uint8_t* end;
 	int enderr;
-	if (datalen < colfer_size_max) {
+	if (datalen < gen_size_max) {
 		end = p + datalen;
 		enderr = EWOULDBLOCK;
 	} else {
-		end = p + colfer_size_max;
+		end = p + gen_size_max;
 		enderr = EFBIG;
 	}
 
@@ -795,7 +867,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -803,10 +875,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			errno = enderr;
 			return 0;
 		}
-		o->s.len = n;
-
-		void* a = malloc(n);
+		void* a = colfer_malloc(n);
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->s.utf8 = (char*) a;
+		o->s.len = n;
 		if (n) {
 			memcpy(a, p, n);
 			p += n;
@@ -835,7 +910,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -843,10 +918,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			errno = enderr;
 			return 0;
 		}
-		o->a.len = n;
-
-		void* a = malloc(n);
+		void* a = colfer_malloc(n);
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->a.octets = (uint8_t*) a;
+		o->a.len = n;
 		if (n) {
 			memcpy(a, p, n);
 			p += n;
@@ -855,7 +933,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 	}
 
 	if (header == 10) {
-		o->o = calloc(1, sizeof(gen_o));
+		gen_o* ref = colfer_malloc(sizeof(gen_o));
+		if (!ref) {
+			errno = ENOMEM;
+			return 0;
+		}
+		memset(ref, 0, sizeof(gen_o));
+		o->o = ref;
 		size_t read = gen_o_unmarshal(o->o, p, (size_t) (end - p));
 		if (!read) {
 			if (errno == EWOULDBLOCK) errno = enderr;
@@ -891,12 +975,19 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > gen_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
 
-		gen_o* a = calloc(n, sizeof(gen_o));
+		gen_o* a = colfer_malloc(n * sizeof(gen_o));
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(a, 0, n * sizeof(gen_o));
+		o->os.list = a;
+		o->os.len = n;
 		for (size_t i = 0; i < n; ++i) {
 			size_t read = gen_o_unmarshal(&a[i], p, (size_t) (end - p));
 			if (!read) {
@@ -905,8 +996,6 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			}
 			p += read;
 		}
-		o->os.len = n;
-		o->os.list = a;
 
 		if (p >= end) {
 			errno = enderr;
@@ -936,14 +1025,18 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > gen_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
-		o->ss.len = n;
-
-		colfer_text* text = malloc(n * sizeof(colfer_text));
+		colfer_text* text = colfer_malloc(n * sizeof(colfer_text));
+		if (!text && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(text, 0, n * sizeof(colfer_text));
 		o->ss.list = text;
+		o->ss.len = n;
 		for (; n; --n, ++text) {
 			if (p >= end) {
 				errno = enderr;
@@ -965,7 +1058,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > gen_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -973,10 +1066,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				errno = enderr;
 				return 0;
 			}
-			text->len = len;
-
-			char* a = malloc(len);
+			char* a = colfer_malloc(len);
+			if (!a && len) {
+				errno = ENOMEM;
+				return 0;
+			}
 			text->utf8 = a;
+			text->len = len;
 			if (len) {
 				memcpy(a, p, len);
 				p += len;
@@ -1011,14 +1107,18 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > gen_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
-		o->as.len = n;
-
-		colfer_binary* binary = malloc(n * sizeof(colfer_binary));
+		colfer_binary* binary = colfer_malloc(n * sizeof(colfer_binary));
+		if (!binary && n) {
+			errno = ENOMEM;
+			return 0;
+		}
+		if (n) memset(binary, 0, n * sizeof(colfer_binary));
 		o->as.list = binary;
+		o->as.len = n;
 		for (; n; --n, ++binary) {
 			if (p >= end) {
 				errno = enderr;
@@ -1040,7 +1140,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > gen_size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1048,10 +1148,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				errno = enderr;
 				return 0;
 			}
-			binary->len = len;
-
-			uint8_t* a = malloc(len);
+			uint8_t* a = colfer_malloc(len);
+			if (!a && len) {
+				errno = ENOMEM;
+				return 0;
+			}
 			binary->octets = a;
+			binary->len = len;
 			if (len) {
 				memcpy(a, p, len);
 				p += len;
@@ -1113,7 +1216,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > gen_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1121,10 +1224,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			errno = enderr;
 			return 0;
 		}
-		o->f32s.len = n;
-
-		float* fp = malloc(n * 4);
+		float* fp = colfer_malloc(n * 4);
+		if (!fp && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->f32s.list = fp;
+		o->f32s.len = n;
 #ifdef COLFER_ENDIAN
 		memcpy(fp, p, n * 4);
 		p += n * 4;
@@ -1162,7 +1268,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > gen_list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1170,10 +1276,13 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			errno = enderr;
 			return 0;
 		}
-		o->f64s.len = n;
-
-		double* fp = malloc(n * 8);
+		double* fp = colfer_malloc(n * 8);
+		if (!fp && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->f64s.list = fp;
+		o->f64s.len = n;
 #ifdef COLFER_ENDIAN
 		memcpy(fp, p, n * 8);
 		p += n * 8;
@@ -1202,19 +1311,58 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 	return (size_t) (p - (const uint8_t*) data);
 }
 
+void gen_o_free(gen_o* o) {
+	colfer_free(o->s.utf8);
+	colfer_free(o->a.octets);
+	if (o->o) {
+		gen_o_free(o->o);
+		colfer_free(o->o);
+	}
+	for (size_t i = 0; o->os.list && i < o->os.len; ++i)
+		gen_o_free(&o->os.list[i]);
+	colfer_free(o->os.list);
+	for (size_t i = 0; o->ss.list && i < o->ss.len; ++i)
+		colfer_free(o->ss.list[i].utf8);
+	colfer_free(o->ss.list);
+	for (size_t i = 0; o->as.list && i < o->as.len; ++i)
+		colfer_free(o->as.list[i].octets);
+	colfer_free(o->as.list);
+	colfer_free(o->f32s.list);
+	colfer_free(o->f64s.list);
+	memset(o, 0, sizeof(gen_o));
+}
+
+size_t gen_o_read(gen_o* o, colfer_reader* r) {
+	for (;;) {
+		if (r->len) {
+			size_t n = gen_o_unmarshal(o, r->buf + r->off, r->len);
+			if (n) {
+				r->off += n;
+				r->len -= n;
+				return n;
+			}
+			if (errno != EWOULDBLOCK) return 0;
+			// partial allocations are redone on the next attempt
+			gen_o_free(o);
+		}
+
+		if (!colfer_reader_fill(r)) return 0;
+	}
+}
+
 size_t gen_dromedary_case_marshal_len(const gen_dromedary_case* o) {
 	size_t l = 1;
 
 	{
 		size_t n = o->pascal_case.len;
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
 		if (n) for (l += 2 + n; n > 127; n >>= 7, ++l);
 	}
 
-	if (l > colfer_size_max) {
+	if (l > gen_size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -1249,11 +1397,11 @@ size_t gen_dromedary_case_unmarshal(gen_dromedary_case* o, const void* data, siz
 	const uint8_t* p = data;
 	const uint8_t* end;
 	int enderr;
-	if (datalen < colfer_size_max) {
+	if (datalen < gen_size_max) {
 		end = p + datalen;
 		enderr = EWOULDBLOCK;
 	} else {
-		end = p + colfer_size_max;
+		end = p + gen_size_max;
 		enderr = EFBIG;
 	}
 
@@ -1284,7 +1432,7 @@ size_t gen_dromedary_case_unmarshal(gen_dromedary_case* o, const void* data, siz
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > gen_size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1292,10 +1440,13 @@ size_t gen_dromedary_case_unmarshal(gen_dromedary_case* o, const void* data, siz
 			errno = enderr;
 			return 0;
 		}
-		o->pascal_case.len = n;
-
-		void* a = malloc(n);
+		void* a = colfer_malloc(n);
+		if (!a && n) {
+			errno = ENOMEM;
+			return 0;
+		}
 		o->pascal_case.utf8 = (char*) a;
+		o->pascal_case.len = n;
 		if (n) {
 			memcpy(a, p, n);
 			p += n;
@@ -1311,6 +1462,29 @@ size_t gen_dromedary_case_unmarshal(gen_dromedary_case* o, const void* data, siz
 	return (size_t) (p - (const uint8_t*) data);
 }
 
+void gen_dromedary_case_free(gen_dromedary_case* o) {
+	colfer_free(o->pascal_case.utf8);
+	memset(o, 0, sizeof(gen_dromedary_case));
+}
+
+size_t gen_dromedary_case_read(gen_dromedary_case* o, colfer_reader* r) {
+	for (;;) {
+		if (r->len) {
+			size_t n = gen_dromedary_case_unmarshal(o, r->buf + r->off, r->len);
+			if (n) {
+				r->off += n;
+				r->len -= n;
+				return n;
+			}
+			if (errno != EWOULDBLOCK) return 0;
+			// partial allocations are redone on the next attempt
+			gen_dromedary_case_free(o);
+		}
+
+		if (!colfer_reader_fill(r)) return 0;
+	}
+}
+
 size_t gen_embed_o_marshal_len(const gen_embed_o* o) {
 	size_t l = 1;
 
@@ -1318,7 +1492,7 @@ size_t gen_embed_o_marshal_len(const gen_embed_o* o) {
 		if (o->inner) l += 1 + gen_o_marshal_len(o->inner);
 	}
 
-	if (l > colfer_size_max) {
+	if (l > gen_size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -1347,11 +1521,11 @@ size_t gen_embed_o_unmarshal(gen_embed_o* o, const void* data, size_t datalen) {
 	const uint8_t* p = data;
 	const uint8_t* end;
 	int enderr;
-	if (datalen < colfer_size_max) {
+	if (datalen < gen_size_max) {
 		end = p + datalen;
 		enderr = EWOULDBLOCK;
 	} else {
-		end = p + colfer_size_max;
+		end = p + gen_size_max;
 		enderr = EFBIG;
 	}
 
@@ -1362,7 +1536,13 @@ size_t gen_embed_o_unmarshal(gen_embed_o* o, const void* data, size_t datalen) {
 	uint_fast8_t header = *p++;
 
 	if (header == 0) {
-		o->inner = calloc(1, sizeof(gen_o));
+		gen_o* ref = colfer_malloc(sizeof(gen_o));
+		if (!ref) {
+			errno = ENOMEM;
+			return 0;
+		}
+		memset(ref, 0, sizeof(gen_o));
+		o->inner = ref;
 		size_t read = gen_o_unmarshal(o->inner, p, (size_t) (end - p));
 		if (!read) {
 			if (errno == EWOULDBLOCK) errno = enderr;
@@ -1384,3 +1564,29 @@ size_t gen_embed_o_unmarshal(gen_embed_o* o, const void* data, size_t datalen) {
 
 	return (size_t) (p - (const uint8_t*) data);
 }
+
+void gen_embed_o_free(gen_embed_o* o) {
+	if (o->inner) {
+		gen_o_free(o->inner);
+		colfer_free(o->inner);
+	}
+	memset(o, 0, sizeof(gen_embed_o));
+}
+
+size_t gen_embed_o_read(gen_embed_o* o, colfer_reader* r) {
+	for (;;) {
+		if (r->len) {
+			size_t n = gen_embed_o_unmarshal(o, r->buf + r->off, r->len);
+			if (n) {
+				r->off += n;
+				r->len -= n;
+				return n;
+			}
+			if (errno != EWOULDBLOCK) return 0;
+			// partial allocations are redone on the next attempt
+			gen_embed_o_free(o);
+		}
+
+		if (!colfer_reader_fill(r)) return 0;
+	}
+}
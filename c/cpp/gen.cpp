@@ -0,0 +1,268 @@
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file test.colf for package gen.
+
+#include "gen.hpp"
+
+#include <cerrno>
+#include <system_error>
+
+namespace gen {
+
+O::O() = default;
+
+O::O(const O& x)
+	: b(x.b)
+	, u32(x.u32)
+	, u64(x.u64)
+	, i32(x.i32)
+	, i64(x.i64)
+	, f32(x.f32)
+	, f64(x.f64)
+	, t(x.t)
+	, s(x.s)
+	, a(x.a)
+	, o(x.o ? std::make_unique<::gen::O>(*x.o) : nullptr)
+	, os(x.os)
+	, ss(x.ss)
+	, as(x.as)
+	, u8(x.u8)
+	, u16(x.u16)
+	, f32s(x.f32s)
+	, f64s(x.f64s) {}
+
+O::O(O&&) noexcept = default;
+
+O& O::operator=(const O& x) {
+	if (this != &x) *this = O(x);
+	return *this;
+}
+
+O& O::operator=(O&&) noexcept = default;
+
+O::~O() = default;
+
+void O::marshal(std::vector<std::uint8_t>& buf) const {
+	gen_o c{};
+	std::vector<std::shared_ptr<void>> hold;
+	to_c(c, hold);
+
+	std::size_t n = gen_o_marshal_len(&c);
+	if (!n) throw std::system_error(errno, std::generic_category(), "gen_o_marshal_len");
+	buf.resize(n);
+	gen_o_marshal(&c, buf.data());
+}
+
+std::size_t O::unmarshal(const void* data, std::size_t len) {
+	gen_o c{};
+	std::unique_ptr<gen_o, void (*)(gen_o*)> guard(&c, gen_o_free);
+
+	std::size_t n = gen_o_unmarshal(&c, data, len);
+	if (!n) {
+		if (errno == EWOULDBLOCK) return 0;
+		throw std::system_error(errno, std::generic_category(), "gen_o_unmarshal");
+	}
+	from_c(c);
+	return n;
+}
+
+void O::to_c(gen_o& c, std::vector<std::shared_ptr<void>>& hold) const {
+	(void) hold;
+	c.b = this->b;
+	c.u32 = this->u32;
+	c.u64 = this->u64;
+	c.i32 = this->i32;
+	c.i64 = this->i64;
+	c.f32 = this->f32;
+	c.f64 = this->f64;
+	c.t = this->t;
+	c.s.utf8 = this->s.data();
+	c.s.len = this->s.size();
+	c.a.octets = const_cast<std::uint8_t*>(this->a.data());
+	c.a.len = this->a.size();
+	if (this->o) {
+		auto ref = std::make_shared<gen_o>();
+		this->o->to_c(*ref, hold);
+		c.o = ref.get();
+		hold.push_back(ref);
+	}
+	if (!this->os.empty()) {
+		std::size_t n = this->os.size();
+		std::shared_ptr<gen_o[]> list(new gen_o[n]());
+		for (std::size_t i = 0; i < n; ++i)
+			this->os[i].to_c(list[i], hold);
+		c.os.list = list.get();
+		c.os.len = n;
+		hold.push_back(list);
+	}
+	if (!this->ss.empty()) {
+		std::size_t n = this->ss.size();
+		std::shared_ptr<colfer_text[]> list(new colfer_text[n]());
+		for (std::size_t i = 0; i < n; ++i) {
+			list[i].utf8 = this->ss[i].data();
+			list[i].len = this->ss[i].size();
+		}
+		c.ss.list = list.get();
+		c.ss.len = n;
+		hold.push_back(list);
+	}
+	if (!this->as.empty()) {
+		std::size_t n = this->as.size();
+		std::shared_ptr<colfer_binary[]> list(new colfer_binary[n]());
+		for (std::size_t i = 0; i < n; ++i) {
+			list[i].octets = const_cast<std::uint8_t*>(this->as[i].data());
+			list[i].len = this->as[i].size();
+		}
+		c.as.list = list.get();
+		c.as.len = n;
+		hold.push_back(list);
+	}
+	c.u8 = this->u8;
+	c.u16 = this->u16;
+	c.f32s.list = const_cast<float*>(this->f32s.data());
+	c.f32s.len = this->f32s.size();
+	c.f64s.list = const_cast<double*>(this->f64s.data());
+	c.f64s.len = this->f64s.size();
+}
+
+void O::from_c(const gen_o& c) {
+	this->b = c.b;
+	this->u32 = c.u32;
+	this->u64 = c.u64;
+	this->i32 = c.i32;
+	this->i64 = c.i64;
+	this->f32 = c.f32;
+	this->f64 = c.f64;
+	this->t = c.t;
+	this->s.assign(c.s.utf8, c.s.utf8 + c.s.len);
+	this->a.assign(c.a.octets, c.a.octets + c.a.len);
+	if (c.o) {
+		this->o = std::make_unique<::gen::O>();
+		this->o->from_c(*c.o);
+	} else {
+		this->o.reset();
+	}
+	this->os.resize(c.os.len);
+	for (std::size_t i = 0; i < c.os.len; ++i)
+		this->os[i].from_c(c.os.list[i]);
+	this->ss.resize(c.ss.len);
+	for (std::size_t i = 0; i < c.ss.len; ++i)
+		this->ss[i].assign(c.ss.list[i].utf8, c.ss.list[i].utf8 + c.ss.list[i].len);
+	this->as.resize(c.as.len);
+	for (std::size_t i = 0; i < c.as.len; ++i)
+		this->as[i].assign(c.as.list[i].octets, c.as.list[i].octets + c.as.list[i].len);
+	this->u8 = c.u8;
+	this->u16 = c.u16;
+	this->f32s.assign(c.f32s.list, c.f32s.list + c.f32s.len);
+	this->f64s.assign(c.f64s.list, c.f64s.list + c.f64s.len);
+}
+
+DromedaryCase::DromedaryCase() = default;
+
+DromedaryCase::DromedaryCase(const DromedaryCase& x)
+	: pascal_case(x.pascal_case) {}
+
+DromedaryCase::DromedaryCase(DromedaryCase&&) noexcept = default;
+
+DromedaryCase& DromedaryCase::operator=(const DromedaryCase& x) {
+	if (this != &x) *this = DromedaryCase(x);
+	return *this;
+}
+
+DromedaryCase& DromedaryCase::operator=(DromedaryCase&&) noexcept = default;
+
+DromedaryCase::~DromedaryCase() = default;
+
+void DromedaryCase::marshal(std::vector<std::uint8_t>& buf) const {
+	gen_dromedary_case c{};
+	std::vector<std::shared_ptr<void>> hold;
+	to_c(c, hold);
+
+	std::size_t n = gen_dromedary_case_marshal_len(&c);
+	if (!n) throw std::system_error(errno, std::generic_category(), "gen_dromedary_case_marshal_len");
+	buf.resize(n);
+	gen_dromedary_case_marshal(&c, buf.data());
+}
+
+std::size_t DromedaryCase::unmarshal(const void* data, std::size_t len) {
+	gen_dromedary_case c{};
+	std::unique_ptr<gen_dromedary_case, void (*)(gen_dromedary_case*)> guard(&c, gen_dromedary_case_free);
+
+	std::size_t n = gen_dromedary_case_unmarshal(&c, data, len);
+	if (!n) {
+		if (errno == EWOULDBLOCK) return 0;
+		throw std::system_error(errno, std::generic_category(), "gen_dromedary_case_unmarshal");
+	}
+	from_c(c);
+	return n;
+}
+
+void DromedaryCase::to_c(gen_dromedary_case& c, std::vector<std::shared_ptr<void>>& hold) const {
+	(void) hold;
+	c.pascal_case.utf8 = this->pascal_case.data();
+	c.pascal_case.len = this->pascal_case.size();
+}
+
+void DromedaryCase::from_c(const gen_dromedary_case& c) {
+	this->pascal_case.assign(c.pascal_case.utf8, c.pascal_case.utf8 + c.pascal_case.len);
+}
+
+EmbedO::EmbedO() = default;
+
+EmbedO::EmbedO(const EmbedO& x)
+	: inner(x.inner ? std::make_unique<::gen::O>(*x.inner) : nullptr) {}
+
+EmbedO::EmbedO(EmbedO&&) noexcept = default;
+
+EmbedO& EmbedO::operator=(const EmbedO& x) {
+	if (this != &x) *this = EmbedO(x);
+	return *this;
+}
+
+EmbedO& EmbedO::operator=(EmbedO&&) noexcept = default;
+
+EmbedO::~EmbedO() = default;
+
+void EmbedO::marshal(std::vector<std::uint8_t>& buf) const {
+	gen_embed_o c{};
+	std::vector<std::shared_ptr<void>> hold;
+	to_c(c, hold);
+
+	std::size_t n = gen_embed_o_marshal_len(&c);
+	if (!n) throw std::system_error(errno, std::generic_category(), "gen_embed_o_marshal_len");
+	buf.resize(n);
+	gen_embed_o_marshal(&c, buf.data());
+}
+
+std::size_t EmbedO::unmarshal(const void* data, std::size_t len) {
+	gen_embed_o c{};
+	std::unique_ptr<gen_embed_o, void (*)(gen_embed_o*)> guard(&c, gen_embed_o_free);
+
+	std::size_t n = gen_embed_o_unmarshal(&c, data, len);
+	if (!n) {
+		if (errno == EWOULDBLOCK) return 0;
+		throw std::system_error(errno, std::generic_category(), "gen_embed_o_unmarshal");
+	}
+	from_c(c);
+	return n;
+}
+
+void EmbedO::to_c(gen_embed_o& c, std::vector<std::shared_ptr<void>>& hold) const {
+	(void) hold;
+	if (this->inner) {
+		auto ref = std::make_shared<gen_o>();
+		this->inner->to_c(*ref, hold);
+		c.inner = ref.get();
+		hold.push_back(ref);
+	}
+}
+
+void EmbedO::from_c(const gen_embed_o& c) {
+	if (c.inner) {
+		this->inner = std::make_unique<::gen::O>();
+		this->inner->from_c(*c.inner);
+	} else {
+		this->inner.reset();
+	}
+}
+
+} // namespace gen
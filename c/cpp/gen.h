@@ -0,0 +1,264 @@
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file test.colf for package gen.
+
+#ifndef COLFER_GEN_H
+#define COLFER_GEN_H
+
+#include <limits.h>
+#include <stddef.h>
+#include <stdint.h>
+#include <string.h>
+#include <time.h>
+
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#ifndef COLFER_TYPES
+#define COLFER_TYPES
+
+#if CHAR_BIT != 8
+#error "octet byte size"
+#endif
+
+// colfer_allocator has the memory management callbacks for unmarshalling.
+typedef struct {
+	// malloc_func returns size octets of memory, or NULL when out of memory.
+	void* (*malloc_func)(void* ctx, size_t size);
+	// free_func releases memory from malloc_func. It is never called with NULL.
+	void (*free_func)(void* ctx, void* ptr);
+	// ctx is passed to each call, e.g., for arena allocation.
+	void* ctx;
+} colfer_allocator;
+
+// colfer_reader buffers a stream of serials for the _read functions.
+typedef struct {
+	// read_func reads up to n octets into buf, like read(2) does. The
+	// return is the number of octets read, zero on end of stream, or a
+	// negative value with errno set on error.
+	ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n);
+	// ctx is passed to each call, e.g., for a file descriptor.
+	void* ctx;
+	// alloc manages buf.
+	colfer_allocator alloc;
+	uint8_t* buf;
+	size_t   cap;
+	size_t   off;
+	size_t   len;
+} colfer_reader;
+
+// colfer_text is a UTF-8 CLOB.
+typedef struct {
+	const char*  utf8;
+	size_t       len;
+} colfer_text;
+
+// colfer_binary is a BLOB.
+typedef struct {
+	uint8_t* octets;
+	size_t   len;
+} colfer_binary;
+
+#endif // COLFER_TYPES
+
+
+// gen_size_max is the upper limit for serial octet sizes.
+extern size_t gen_size_max;
+
+// gen_list_max is the upper limit for the number of list elements.
+extern size_t gen_list_max;
+
+// gen_alloc is the allocator in use, which defaults to
+// malloc(3) and free(3), both with a NULL ctx.
+extern colfer_allocator gen_alloc;
+
+// gen_reader_init sets up r for read_func with ctx. The buffer
+// grows on demand, up to gen_size_max, with gen_alloc.
+void gen_reader_init(colfer_reader* r, ptrdiff_t (*read_func)(void* ctx, void* buf, size_t n), void* ctx);
+
+// gen_reader_release frees the buffer of r. Any data pending is
+// discarded.
+void gen_reader_release(colfer_reader* r);
+
+
+typedef struct gen_o gen_o;
+
+typedef struct gen_dromedary_case gen_dromedary_case;
+
+typedef struct gen_embed_o gen_embed_o;
+
+
+// O contains all supported data types.
+struct gen_o {
+	// B tests booleans.
+	char b;
+	// U32 tests unsigned 32-bit integers.
+	uint32_t u32;
+	// U64 tests unsigned 64-bit integers.
+	uint64_t u64;
+	// I32 tests signed 32-bit integers.
+	int32_t i32;
+	// I64 tests signed 64-bit integers.
+	int64_t i64;
+	// F32 tests 32-bit floating points.
+	float f32;
+	// F64 tests 64-bit floating points.
+	double f64;
+	// T tests timestamps.
+	struct timespec t;
+	// S tests text.
+	colfer_text s;
+	// A tests binaries.
+	colfer_binary a;
+	// O tests nested data structures.
+	struct gen_o* o;
+	// Os tests data structure lists.
+	struct {
+		struct gen_o* list;
+		size_t len;
+	} os;
+	// Ss tests text lists.
+	struct {
+		colfer_text* list;
+		size_t len;
+	} ss;
+	// As tests binary lists.
+	struct {
+		colfer_binary* list;
+		size_t len;
+	} as;
+	// U8 tests unsigned 8-bit integers.
+	uint8_t u8;
+	// U16 tests unsigned 16-bit integers.
+	uint16_t u16;
+	// F32s tests 32-bit floating point lists.
+	struct {
+		float* list;
+		size_t len;
+	} f32s;
+	// F64s tests 64-bit floating point lists.
+	struct {
+		double* list;
+		size_t len;
+	} f64s;
+};
+
+// gen_o_marshal_len returns the Colfer serial octet size.
+// When the return is zero then errno is set to EFBIG to indicate a breach of
+// either gen_size_max or gen_list_max.
+size_t gen_o_marshal_len(const gen_o* o);
+
+// gen_o_marshal encodes o as Colfer into buf and returns the number
+// of octets written.
+size_t gen_o_marshal(const gen_o* o, void* buf);
+
+// gen_o_unmarshal decodes data as Colfer into o and returns the
+// number of octets read. The data is read up to a maximum of datalen or
+// gen_size_max, whichever occurs first.
+// When the return is zero then errno is set to one of the following 4 values:
+// EWOULDBLOCK on incomplete data, EFBIG on a breach of either
+// gen_size_max or gen_list_max, EILSEQ on schema
+// mismatch and ENOMEM when gen_alloc failed. Memory is allocated
+// with gen_alloc, also on error.
+size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen);
+
+// gen_o_free releases all memory referenced by o, as allocated by
+// gen_o_unmarshal, including any nested structs. The struct itself is
+// not released. Instead, o is zeroed, ready for reuse.
+void gen_o_free(gen_o* o);
+
+// gen_o_read decodes the next serial from r into o and returns the
+// number of octets read. When the return is zero then errno is set to zero on
+// end of stream, EIO when the stream ends halfway a serial, or to any of the
+// errors from either gen_o_unmarshal or the read_func of r. Reads
+// may continue after an error from read_func, such as EWOULDBLOCK, as all data
+// received remains buffered. Memory is allocated as with
+// gen_o_unmarshal.
+size_t gen_o_read(gen_o* o, colfer_reader* r);
+
+// DromedaryCase oposes name casings.
+struct gen_dromedary_case {
+
+	colfer_text pascal_case;
+};
+
+// gen_dromedary_case_marshal_len returns the Colfer serial octet size.
+// When the return is zero then errno is set to EFBIG to indicate a breach of
+// either gen_size_max or gen_list_max.
+size_t gen_dromedary_case_marshal_len(const gen_dromedary_case* o);
+
+// gen_dromedary_case_marshal encodes o as Colfer into buf and returns the number
+// of octets written.
+size_t gen_dromedary_case_marshal(const gen_dromedary_case* o, void* buf);
+
+// gen_dromedary_case_unmarshal decodes data as Colfer into o and returns the
+// number of octets read. The data is read up to a maximum of datalen or
+// gen_size_max, whichever occurs first.
+// When the return is zero then errno is set to one of the following 4 values:
+// EWOULDBLOCK on incomplete data, EFBIG on a breach of either
+// gen_size_max or gen_list_max, EILSEQ on schema
+// mismatch and ENOMEM when gen_alloc failed. Memory is allocated
+// with gen_alloc, also on error.
+size_t gen_dromedary_case_unmarshal(gen_dromedary_case* o, const void* data, size_t datalen);
+
+// gen_dromedary_case_free releases all memory referenced by o, as allocated by
+// gen_dromedary_case_unmarshal, including any nested structs. The struct itself is
+// not released. Instead, o is zeroed, ready for reuse.
+void gen_dromedary_case_free(gen_dromedary_case* o);
+
+// gen_dromedary_case_read decodes the next serial from r into o and returns the
+// number of octets read. When the return is zero then errno is set to zero on
+// end of stream, EIO when the stream ends halfway a serial, or to any of the
+// errors from either gen_dromedary_case_unmarshal or the read_func of r. Reads
+// may continue after an error from read_func, such as EWOULDBLOCK, as all data
+// received remains buffered. Memory is allocated as with
+// gen_dromedary_case_unmarshal.
+size_t gen_dromedary_case_read(gen_dromedary_case* o, colfer_reader* r);
+
+// EmbedO has an inner object only.
+// Covers regression of issue #66.
+struct gen_embed_o {
+
+	struct gen_o* inner;
+};
+
+// gen_embed_o_marshal_len returns the Colfer serial octet size.
+// When the return is zero then errno is set to EFBIG to indicate a breach of
+// either gen_size_max or gen_list_max.
+size_t gen_embed_o_marshal_len(const gen_embed_o* o);
+
+// gen_embed_o_marshal encodes o as Colfer into buf and returns the number
+// of octets written.
+size_t gen_embed_o_marshal(const gen_embed_o* o, void* buf);
+
+// gen_embed_o_unmarshal decodes data as Colfer into o and returns the
+// number of octets read. The data is read up to a maximum of datalen or
+// gen_size_max, whichever occurs first.
+// When the return is zero then errno is set to one of the following 4 values:
+// EWOULDBLOCK on incomplete data, EFBIG on a breach of either
+// gen_size_max or gen_list_max, EILSEQ on schema
+// mismatch and ENOMEM when gen_alloc failed. Memory is allocated
+// with gen_alloc, also on error.
+size_t gen_embed_o_unmarshal(gen_embed_o* o, const void* data, size_t datalen);
+
+// gen_embed_o_free releases all memory referenced by o, as allocated by
+// gen_embed_o_unmarshal, including any nested structs. The struct itself is
+// not released. Instead, o is zeroed, ready for reuse.
+void gen_embed_o_free(gen_embed_o* o);
+
+// gen_embed_o_read decodes the next serial from r into o and returns the
+// number of octets read. When the return is zero then errno is set to zero on
+// end of stream, EIO when the stream ends halfway a serial, or to any of the
+// errors from either gen_embed_o_unmarshal or the read_func of r. Reads
+// may continue after an error from read_func, such as EWOULDBLOCK, as all data
+// received remains buffered. Memory is allocated as with
+// gen_embed_o_unmarshal.
+size_t gen_embed_o_read(gen_embed_o* o, colfer_reader* r);
+
+
+#ifdef __cplusplus
+} // extern "C"
+#endif
+
+#endif
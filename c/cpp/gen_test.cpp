@@ -0,0 +1,181 @@
+#include "gen.hpp"
+
+#include <cerrno>
+#include <cmath>
+#include <cstdio>
+#include <functional>
+#include <limits>
+#include <string>
+#include <system_error>
+#include <vector>
+
+static int failures = 0;
+
+static void fail(const char* format, const std::string& hex) {
+	std::printf(format, hex.c_str());
+	std::putchar('\n');
+	++failures;
+}
+
+// hexstr returns the hex encoding of data.
+static std::string hexstr(const std::vector<std::uint8_t>& data) {
+	static const char table[] = "0123456789abcdef";
+	std::string s;
+	for (std::uint8_t c : data) {
+		s += table[c >> 4];
+		s += table[c & 15];
+	}
+	return s;
+}
+
+// unhex returns the decoding of a hex string.
+static std::vector<std::uint8_t> unhex(const std::string& s) {
+	std::vector<std::uint8_t> data;
+	for (std::size_t i = 0; i + 1 < s.size(); i += 2)
+		data.push_back(std::stoi(s.substr(i, 2), nullptr, 16));
+	return data;
+}
+
+struct golden {
+	std::string hex;
+	std::function<void(gen::O&)> set;
+};
+
+static const golden golden_cases[] = {
+	{"7f", [](gen::O&) {}},
+	{"007f", [](gen::O& o) { o.b = true; }},
+	{"01017f", [](gen::O& o) { o.u32 = 1; }},
+	{"81ffffffff7f", [](gen::O& o) { o.u32 = std::numeric_limits<std::uint32_t>::max(); }},
+	{"82ffffffffffffffff7f", [](gen::O& o) { o.u64 = std::numeric_limits<std::uint64_t>::max(); }},
+	{"83017f", [](gen::O& o) { o.i32 = -1; }},
+	{"8380808080087f", [](gen::O& o) { o.i32 = std::numeric_limits<std::int32_t>::min(); }},
+	{"04ffffffffffffffff7f7f", [](gen::O& o) { o.i64 = std::numeric_limits<std::int64_t>::max(); }},
+	{"848080808080808080807f", [](gen::O& o) { o.i64 = std::numeric_limits<std::int64_t>::min(); }},
+	{"057f7fffff7f", [](gen::O& o) { o.f32 = 3.4028235e+38f; }},
+	{"057fc000007f", [](gen::O& o) { o.f32 = NAN; }},
+	{"067fefffffffffffff7f", [](gen::O& o) { o.f64 = 1.7976931348623157e+308; }},
+	{"0755ef312a2e5da4e77f", [](gen::O& o) { o.t = {1441739050, 777888999}; }},
+	{"87fffff82457de8000000003e97f", [](gen::O& o) { o.t = {-8640000000000, 1001}; }},
+	{"080261007f", [](gen::O& o) { o.s = std::string("a\0", 2); }},
+	{"090202007f", [](gen::O& o) { o.a = {2, 0}; }},
+	{"0a007f7f", [](gen::O& o) { o.o = std::make_unique<gen::O>(); o.o->b = true; }},
+	{"0b01007f7f", [](gen::O& o) { o.os.resize(1); o.os[0].b = true; }},
+	{"0b027f7f7f", [](gen::O& o) { o.os.resize(2); }},
+	{"0c0300016101627f", [](gen::O& o) { o.ss = {"", "a", "b"}; }},
+	{"0d0201000201027f", [](gen::O& o) { o.as = {{0}, {1, 2}}; }},
+	{"0e017f", [](gen::O& o) { o.u8 = 1; }},
+	{"8f017f", [](gen::O& o) { o.u16 = 1; }},
+	{"1001000000007f", [](gen::O& o) { o.f32s = {0}; }},
+	{"11023ff00000000000007ff80000000000007f", [](gen::O& o) { o.f64s = {1, NAN}; }},
+};
+
+int main() {
+	const std::size_t n = sizeof(golden_cases) / sizeof(golden);
+	std::printf("got %zu golden cases\n", n);
+
+	std::printf("TEST marshalling...\n");
+	for (const golden& g : golden_cases) {
+		gen::O o;
+		g.set(o);
+		std::vector<std::uint8_t> buf;
+		o.marshal(buf);
+		if (hexstr(buf) != g.hex)
+			fail("0x%s: marshal mismatch", g.hex);
+	}
+
+	std::printf("TEST unmarshalling...\n");
+	for (const golden& g : golden_cases) {
+		std::vector<std::uint8_t> data = unhex(g.hex);
+		data.push_back(0x7f);  // tail
+
+		gen::O o;
+		std::size_t read = o.unmarshal(data.data(), data.size());
+		if (read != data.size() - 1) {
+			fail("0x%s: unmarshal read mismatch", g.hex);
+			continue;
+		}
+		std::vector<std::uint8_t> buf;
+		o.marshal(buf);
+		if (hexstr(buf) != g.hex)
+			fail("0x%s: marshal after unmarshal mismatch", g.hex);
+
+		for (std::size_t lim = 0; lim < read; ++lim) {
+			if (gen::O().unmarshal(data.data(), lim) != 0)
+				fail("0x%s: unmarshal of incomplete data", g.hex);
+		}
+	}
+
+#ifdef __cpp_lib_span
+	std::printf("TEST unmarshalling from span...\n");
+	for (const golden& g : golden_cases) {
+		std::vector<std::uint8_t> data = unhex(g.hex);
+		std::span<const std::uint8_t> span(data);
+
+		gen::O o;
+		if (o.unmarshal(span) != data.size()) {
+			fail("0x%s: span unmarshal read mismatch", g.hex);
+			continue;
+		}
+		std::vector<std::uint8_t> buf;
+		o.marshal(buf);
+		if (hexstr(buf) != g.hex)
+			fail("0x%s: marshal after span unmarshal mismatch", g.hex);
+
+		if (gen::O().unmarshal(span.first(data.size() - 1)) != 0)
+			fail("0x%s: span unmarshal of incomplete data", g.hex);
+	}
+#elif __cplusplus >= 202002L
+#error "no std::span with C++20"
+#endif
+
+	std::printf("TEST value semantics...\n");
+	{
+		gen::O o;
+		o.o = std::make_unique<gen::O>();
+		o.o->s = "nested";
+		o.os.resize(1);
+		o.os[0].ss = {"listed"};
+
+		gen::O copy = o;
+		o.o->s = "changed";
+		o.os[0].ss[0] = "changed";
+		if (copy.o->s != "nested" || copy.os[0].ss[0] != "listed")
+			fail("%s: copy shares state with its origin", "O");
+
+		gen::O moved = std::move(copy);
+		if (!moved.o || moved.o->s != "nested")
+			fail("%s: move lost state", "O");
+
+		copy = moved;
+		if (copy.o.get() == moved.o.get())
+			fail("%s: copy assignment shares state", "O");
+	}
+
+	std::printf("TEST errors...\n");
+	{
+		gen::O o;
+		o.s = std::string(100, 'x');
+		std::size_t saved = gen_size_max;
+		gen_size_max = 10;
+		try {
+			std::vector<std::uint8_t> buf;
+			o.marshal(buf);
+			fail("%s: no error on size maximum breach", "marshal");
+		} catch (const std::system_error& e) {
+			if (e.code().value() != EFBIG)
+				fail("%s: error code not EFBIG", "marshal");
+		}
+		gen_size_max = saved;
+
+		std::vector<std::uint8_t> data = unhex("7e");
+		try {
+			o.unmarshal(data.data(), data.size());
+			fail("%s: no error on schema mismatch", "unmarshal");
+		} catch (const std::system_error& e) {
+			if (e.code().value() != EILSEQ)
+				fail("%s: error code not EILSEQ", "unmarshal");
+		}
+	}
+
+	return failures != 0;
+}
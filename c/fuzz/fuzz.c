@@ -1,4 +1,4 @@
-#include "Colfer.h"
+#include "gen.h"
 
 #include <stdlib.h>
 #include <unistd.h>
@@ -6,8 +6,8 @@
 #include <string.h>
 
 int main() {
-	void* in = malloc(colfer_size_max);
-	ssize_t inlen = read(STDIN_FILENO, in, colfer_size_max);
+	void* in = malloc(gen_size_max);
+	ssize_t inlen = read(STDIN_FILENO, in, gen_size_max);
 	if (inlen < 0) {
 		perror(NULL);
 		return 1;
@@ -24,7 +24,7 @@ int main() {
 		return 2;
 	}
 
-	void* out = malloc(colfer_size_max);
+	void* out = malloc(gen_size_max);
 	size_t wrote = gen_o_marshal(&o, out);
 	if (wrote != read) {
 		return 3;
@@ -1,4 +1,4 @@
-#include "Colfer.h"
+#include "gen.h"
 #include "gen_test.h"
 
 #include <errno.h>
@@ -72,7 +72,7 @@ int gen_o_equal(const gen_o* pa, const gen_o* pb) {
 }
 
 void gen_o_dump(const gen_o o) {
-	char* buf = malloc(colfer_size_max * 2 + 1);
+	char* buf = malloc(gen_size_max * 2 + 1);
 
 	printf("{ ");
 	if (o.b) printf("b=true ");
@@ -140,6 +140,35 @@ void gen_o_dump(const gen_o o) {
 	free(buf);
 }
 
+// counting_malloc allocates with the count of live allocations in ctx.
+void* counting_malloc(void* ctx, size_t size) {
+	++*(long*) ctx;
+	return malloc(size);
+}
+
+// counting_free releases with the count of live allocations in ctx.
+void counting_free(void* ctx, void* ptr) {
+	--*(long*) ctx;
+	free(ptr);
+}
+
+// chunk_stream feeds data in chunks of at most size octets.
+typedef struct {
+	const uint8_t* data;
+	size_t len;
+	size_t size;
+} chunk_stream;
+
+ptrdiff_t chunk_read(void* ctx, void* buf, size_t n) {
+	chunk_stream* s = ctx;
+	if (n > s->size) n = s->size;
+	if (n > s->len) n = s->len;
+	memcpy(buf, s->data, n);
+	s->data += n;
+	s->len -= n;
+	return (ptrdiff_t) n;
+}
+
 int main() {
 	const int n = sizeof(golden_cases) / sizeof(golden);
 	printf("got %d golden cases\n", n);
@@ -169,19 +198,19 @@ int main() {
 			printf("0x%s: got marshal length %zu, want %zu\n", g.hex, got, want);
 
 		// size maximum
-		for (colfer_size_max = 0; colfer_size_max < want; ++colfer_size_max) {
+		for (gen_size_max = 0; gen_size_max < want; ++gen_size_max) {
 			got = gen_o_marshal_len(&g.o);
 			if (got || errno != EFBIG)
-				printf("0x%s: got marshal length %zu and errno %d with Colfer size maximum %zu\n", g.hex, got, errno, colfer_size_max);
+				printf("0x%s: got marshal length %zu and errno %d with Colfer size maximum %zu\n", g.hex, got, errno, gen_size_max);
 
 
 			errno = 0;
 		}
-		colfer_size_max = 16 * 1024 * 1024;
+		gen_size_max = 16 * 1024 * 1024;
 	}
 
-	void* buf = malloc(colfer_size_max);
-	void* hex = malloc(colfer_size_max * 2 + 1);
+	void* buf = malloc(gen_size_max);
+	void* hex = malloc(gen_size_max * 2 + 1);
 
 	printf("TEST marshalling...\n");
 	for (int i = 0; i < n; ++i) {
@@ -206,6 +235,7 @@ int main() {
 			gen_o_dump(g.o);
 			putchar('\n');
 		}
+		gen_o_free(&got);
 	}
 
 	printf("TEST unmarshal limits...\n");
@@ -225,19 +255,106 @@ int main() {
 				printf("0x%s[0:%zu]: unmarshal read %zu and errno %d\n", g.hex, lim, read, errno);
 
 			errno = 0;
+			gen_o_free(&o);
 		}
 
 		// size maximum:
-		for (colfer_size_max = 0; colfer_size_max < len; ++colfer_size_max) {
+		for (gen_size_max = 0; gen_size_max < len; ++gen_size_max) {
 			gen_o o = {0};
 			size_t read = gen_o_unmarshal(&o, buf, len);
 			if (read || errno != EFBIG)
-				printf("0x%s: unmarshal read %zu with errno %d for size maximum %zu\n", g.hex, read, errno, colfer_size_max);
+				printf("0x%s: unmarshal read %zu with errno %d for size maximum %zu\n", g.hex, read, errno, gen_size_max);
+
+			errno = 0;
+			gen_o_free(&o);
+		}
+		gen_size_max = 16 * 1024 * 1024;
+	}
+
+	printf("TEST free...\n");
+	long live = 0;
+	gen_alloc = (colfer_allocator) {counting_malloc, counting_free, &live};
+	for (int i = 0; i < n; ++i) {
+		golden g = golden_cases[i];
+		size_t len = gen_o_marshal(&g.o, buf);
 
+		// incomplete data leaves partial allocations
+		for (size_t lim = 0; lim <= len; lim++) {
+			gen_o o = {0};
+			gen_o_unmarshal(&o, buf, lim);
 			errno = 0;
+			gen_o_free(&o);
+			if (live)
+				printf("0x%s[0:%zu]: %ld allocations remain after free\n", g.hex, lim, live);
+			if (o.o || o.os.list || o.s.utf8)
+				printf("0x%s[0:%zu]: struct not zeroed after free\n", g.hex, lim);
+			live = 0;
+		}
+	}
+
+	printf("TEST reader...\n");
+	size_t streamlen = 0;
+	for (int i = 0; i < n; ++i)
+		streamlen += gen_o_marshal(&golden_cases[i].o, (uint8_t*) buf + streamlen);
+	for (size_t size = 1; size <= 64; size *= 4) {
+		chunk_stream s = {buf, streamlen, size};
+		colfer_reader r;
+		gen_reader_init(&r, chunk_read, &s);
+		for (int i = 0; i < n; ++i) {
+			golden g = golden_cases[i];
+			gen_o o = {0};
+			size_t read = gen_o_read(&o, &r);
+			if (!read) {
+				printf("0x%s: read with chunk size %zu got errno %d\n", g.hex, size, errno);
+				break;
+			}
+			if (read != strlen(g.hex) / 2 || !gen_o_equal(&o, &g.o))
+				printf("0x%s: read %zu octets with chunk size %zu\n", g.hex, read, size);
+			gen_o_free(&o);
 		}
-		colfer_size_max = 16 * 1024 * 1024;
+		gen_o o = {0};
+		errno = EINVAL;
+		if (gen_o_read(&o, &r) || errno != 0)
+			printf("read after stream end got errno %d, want 0\n", errno);
+		gen_reader_release(&r);
+		if (live)
+			printf("%ld allocations remain after reader release\n", live);
+		live = 0;
+	}
+	{
+		// stream ends halfway
+		chunk_stream s = {buf, streamlen - 1, 7};
+		colfer_reader r;
+		gen_reader_init(&r, chunk_read, &s);
+		gen_o o = {0};
+		for (int i = 0; i < n - 1; ++i) {
+			gen_o_read(&o, &r);
+			gen_o_free(&o);
+		}
+		if (gen_o_read(&o, &r) || errno != EIO)
+			printf("read of truncated stream got errno %d, want EIO\n", errno);
+		gen_o_free(&o);
+		gen_reader_release(&r);
+		errno = 0;
+	}
+	{
+		// serial exceeds size maximum
+		gen_o big = {.s = {"0123456789", 10}};
+		size_t len = gen_o_marshal(&big, buf);
+		chunk_stream s = {buf, len, len};
+		colfer_reader r;
+		gen_size_max = len - 1;
+		gen_reader_init(&r, chunk_read, &s);
+		gen_o o = {0};
+		if (gen_o_read(&o, &r) || errno != EFBIG)
+			printf("read beyond size maximum got errno %d, want EFBIG\n", errno);
+		gen_o_free(&o);
+		gen_reader_release(&r);
+		gen_size_max = 16 * 1024 * 1024;
+		errno = 0;
 	}
+	if (live)
+		printf("%ld allocations remain after reader tests\n", live);
 
 	free(buf);
 	free(hex);
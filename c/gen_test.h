@@ -1,4 +1,4 @@
-#include "Colfer.h"
+#include "gen.h"
 
 #include <math.h>
 #include <stdint.h>
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"go/scanner"
 	"io/ioutil"
 	"log"
 	"os"
@@ -18,6 +21,7 @@ var (
 	prefix  = flag.String("p", "", "Compile to a `package` prefix.")
 	format  = flag.Bool("f", false, "Normalize the format of all schema input on the fly.")
 	verbose = flag.Bool("v", false, "Enable verbose reporting to "+italic+"standard error"+clear+".")
+	jsonOut = flag.Bool("j", false, "Report lint findings in JSON, one object per line.")
 
 	sizeMax = flag.String("s", "16 * 1024 * 1024", "Set the default upper limit for serial byte sizes. The\n`expression` is applied to the target language under the name\nColferSizeMax.")
 	listMax = flag.String("l", "64 * 1024", "Set the default upper limit for the number of elements in a\nlist. The `expression` is applied to the target language under\nthe name ColferListMax.")
@@ -26,6 +30,7 @@ var (
 	interfaces  = flag.String("i", "", "Make all generated classes implement one or more `interfaces`.\nUse commas as a list separator.")
 	tagFiles    = flag.String("t", "", "Supply custom tags with one or more `files`. Use commas as a list\nseparator. See the TAGS section for details.")
 	snippetFile = flag.String("c", "", "Insert a code snippet from a `file`.")
+	optionList  = flag.String("o", "", "Enable language specific `options`. Use commas as a list\nseparator. See the LANGUAGE OPTIONS section for details.")
 )
 
 func init() {
@@ -54,80 +59,86 @@ func main() {
 		os.Exit(2)
 	}
 
+	switch strings.ToLower(flag.Arg(0)) {
+	case "schema":
+		mustDeriveSchema()
+		return
+	case "describe":
+		mustDescribe()
+		return
+	case "lint":
+		mustLint()
+		return
+	case "from-proto":
+		mustConvertFromProto()
+		return
+	case "to-proto":
+		mustConvertToProto()
+		return
+	}
+
 	// select language
-	var gen func(string, colfer.Packages) error
-	var tagOptions colfer.TagOptions
-	switch lang := flag.Arg(0); strings.ToLower(lang) {
-	case "c":
-		report.Print("set-up for C")
-		gen = colfer.GenerateC
-		if *superClass != "" {
-			log.Fatalf("%s: super class not supported with C", name)
-		}
-		if *interfaces != "" {
-			log.Fatalf("%s: interfaces not supported with C", name)
-		}
-		if *tagFiles != "" {
-			log.Fatalf("%s: tags not supported with C", name)
-		}
-		if *snippetFile != "" {
-			log.Fatalf("%s: snippet not supported with C", name)
+	lang := flag.Arg(0)
+	gen := colfer.LookupGenerator(lang)
+	if gen == nil {
+		plugin, err := colfer.LookupPlugin(lang)
+		if err != nil {
+			log.Fatalf("%s: unsupported language %q: %s", name, lang, err)
 		}
+		report.Print("set-up for plugin ", plugin.Path)
+		gen = plugin
+	}
+	features := colfer.GeneratorFeatures(gen)
+	if features.Title == "" {
+		features.Title = lang
+	} else {
+		report.Print("set-up for ", features.Title)
+	}
 
-	case "go":
-		report.Print("set-up for Go")
-		gen = colfer.GenerateGo
-		if *superClass != "" {
-			log.Fatalf("%s: super class not supported with Go", name)
-		}
-		if *interfaces != "" {
-			log.Fatalf("%s: interfaces not supported with Go", name)
-		}
-		if *snippetFile != "" {
-			log.Fatalf("%s: snippet not supported with Go", name)
-		}
-		tagOptions.FieldAllow = colfer.TagSingle
-
-	case "java":
-		report.Print("set-up for Java")
-		gen = colfer.GenerateJava
-		tagOptions.StructAllow = colfer.TagMulti
-		tagOptions.FieldAllow = colfer.TagMulti
-
-	case "javascript", "js", "ecmascript":
-		report.Print("set-up for ECMAScript")
-		gen = colfer.GenerateECMA
-		if *superClass != "" {
-			log.Fatalf("%s: super class not supported with ECMAScript", name)
-		}
-		if *interfaces != "" {
-			log.Fatalf("%s: interfaces not supported with ECMAScript", name)
+	if *superClass != "" && !features.SuperClass {
+		log.Fatalf("%s: super class not supported with %s", name, features.Title)
+	}
+	if *interfaces != "" && !features.Interfaces {
+		log.Fatalf("%s: interfaces not supported with %s", name, features.Title)
+	}
+	if *tagFiles != "" && features.Tags == (colfer.TagOptions{}) {
+		log.Fatalf("%s: tags not supported with %s", name, features.Title)
+	}
+	if *snippetFile != "" && !features.CodeSnippet {
+		log.Fatalf("%s: snippet not supported with %s", name, features.Title)
+	}
+
+	var options []string
+	if *optionList != "" {
+		options = strings.Split(*optionList, ",")
+	}
+	for _, o := range options {
+		if features.Options == nil {
+			break
 		}
-		if *tagFiles != "" {
-			log.Fatalf("%s: tags not supported with ECMAScript", name)
+		var ok bool
+		for _, allow := range features.Options {
+			ok = ok || o == allow
 		}
-		if *snippetFile != "" {
-			log.Fatalf("%s: snippet not supported with ECMAScript", name)
+		if !ok {
+			log.Fatalf("%s: option %q not supported with %s", name, o, features.Title)
 		}
-
-	default:
-		log.Fatalf("%s: unsupported language %q", name, lang)
 	}
 
 	if flag.NArg() > 1 {
-		mustResolveSchemaFiles(flag.Args()[1:]...)
+		mustResolveSchemaFiles("*.colf", flag.Args()[1:]...)
 	} else {
-		mustResolveSchemaFiles(".")
+		mustResolveSchemaFiles("*.colf", ".")
 	}
 	packages, err := colfer.ParseFiles(schemaPaths...)
 	if err != nil {
-		log.Fatal(err)
+		mustNotSchemaErr(err)
 	}
 
 	if *tagFiles != "" {
 		for _, path := range strings.Split(*tagFiles, ",") {
 			report.Print("using tag file: ", path)
-			if err = packages.ApplyTagFile(path, tagOptions); err != nil {
+			if err = packages.ApplyTagFile(path, features.Tags); err != nil {
 				log.Fatal(err)
 			}
 		}
@@ -146,7 +157,7 @@ func main() {
 	}
 
 	if len(packages) == 0 {
-		log.Fatalf("%s: no struct definitons found", name)
+		log.Fatalf("%s: no struct definitions found", name)
 	}
 
 	for _, p := range packages {
@@ -154,6 +165,7 @@ func main() {
 		p.SizeMax = *sizeMax
 		p.ListMax = *listMax
 		p.SuperClass = *superClass
+		p.Options = options
 		if *interfaces != "" {
 			p.Interfaces = strings.Split(*interfaces, ",")
 		}
@@ -166,12 +178,199 @@ func main() {
 		}
 	}
 
-	if err := gen(*basedir, packages); err != nil {
+	if err := colfer.Generate(gen, *basedir, packages); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// MustDeriveSchema writes a schema file per package from Go source.
+func mustDeriveSchema() {
+	report.Print("set-up for schema derivation")
+	if *prefix != "" || *superClass != "" || *interfaces != "" || *tagFiles != "" || *snippetFile != "" || *format {
+		log.Fatalf("%s: only the -b option is supported with schema", name)
+	}
+
+	if flag.NArg() > 1 {
+		mustResolveSchemaFiles("*.go", flag.Args()[1:]...)
+	} else {
+		mustResolveSchemaFiles("*.go", ".")
+	}
+	packages, err := colfer.ParseGoFiles(schemaPaths...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(packages) == 0 {
+		log.Fatalf("%s: no struct definitions with colfer tags found", name)
+	}
+
+	if err := os.MkdirAll(*basedir, os.ModeDir|os.ModePerm); err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range packages {
+		path := filepath.Join(*basedir, p.Name+".colf")
+		report.Print("writing schema file: ", path)
+		var buf bytes.Buffer
+		if err := p.WriteSchema(&buf); err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// MustDescribe writes the model in JSON and in the Colfer descriptor format.
+func mustDescribe() {
+	report.Print("set-up for description")
+	if *prefix != "" || *superClass != "" || *interfaces != "" || *tagFiles != "" || *snippetFile != "" || *format {
+		log.Fatalf("%s: only the -b option is supported with describe", name)
+	}
+
+	if flag.NArg() > 1 {
+		mustResolveSchemaFiles("*.colf", flag.Args()[1:]...)
+	} else {
+		mustResolveSchemaFiles("*.colf", ".")
+	}
+	packages, err := colfer.ParseFiles(schemaPaths...)
+	if err != nil {
+		mustNotSchemaErr(err)
+	}
+
+	text, err := json.MarshalIndent(packages, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	serial, err := packages.Descriptor().MarshalBinary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	files := colfer.Files{
+		"descriptor.json":   append(text, '\n'),
+		"descriptor.colfer": serial,
+	}
+	if err := files.Write(*basedir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// MustLint reports any findings on standard output.
+func mustLint() {
+	report.Print("set-up for lint")
+	if *prefix != "" || *superClass != "" || *interfaces != "" || *tagFiles != "" || *snippetFile != "" || *format {
+		log.Fatalf("%s: only the -j and -o options are supported with lint", name)
+	}
+	var enable []string
+	if *optionList != "" {
+		enable = strings.Split(*optionList, ",")
+	}
+	for _, rule := range enable {
+		var ok bool
+		for _, optional := range colfer.LintOptional {
+			ok = ok || rule == optional
+		}
+		if !ok {
+			log.Fatalf("%s: lint rule %q is not optional; use any of %s", name, rule, strings.Join(colfer.LintOptional, ", "))
+		}
+	}
+
+	if flag.NArg() > 1 {
+		mustResolveSchemaFiles("*.colf", flag.Args()[1:]...)
+	} else {
+		mustResolveSchemaFiles("*.colf", ".")
+	}
+	packages, err := colfer.ParseFiles(schemaPaths...)
+	if err != nil {
+		mustNotSchemaErr(err)
+	}
+
+	findings := colfer.Lint(packages, enable...)
+	for _, f := range findings {
+		if *jsonOut {
+			line, err := json.Marshal(f)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s\n", line)
+		} else {
+			fmt.Println(f)
+		}
+	}
+	if len(findings) != 0 {
+		os.Exit(1)
+	}
+}
+
+// MustConvertFromProto writes a schema file per Protocol Buffers file.
+func mustConvertFromProto() {
+	report.Print("set-up for conversion from Protocol Buffers")
+	if *prefix != "" || *superClass != "" || *interfaces != "" || *tagFiles != "" || *snippetFile != "" || *format {
+		log.Fatalf("%s: only the -b option is supported with from-proto", name)
+	}
+
+	if flag.NArg() > 1 {
+		mustResolveSchemaFiles("*.proto", flag.Args()[1:]...)
+	} else {
+		mustResolveSchemaFiles("*.proto", ".")
+	}
+
+	files := make(colfer.Files)
+	for _, path := range schemaPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p, warnings, err := colfer.ParseProto(path, f)
+		f.Close()
+		for _, s := range warnings {
+			log.Printf("%s: WARNING: %s", name, s)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := p.WriteSchema(&buf); err != nil {
+			log.Fatal(err)
+		}
+		files[strings.TrimSuffix(filepath.Base(path), ".proto")+".colf"] = buf.Bytes()
+	}
+	if err := files.Write(*basedir); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func mustResolveSchemaFiles(paths ...string) {
+// MustConvertToProto writes a Protocol Buffers file per package.
+func mustConvertToProto() {
+	report.Print("set-up for conversion to Protocol Buffers")
+	if *superClass != "" || *interfaces != "" || *tagFiles != "" || *snippetFile != "" {
+		log.Fatalf("%s: only the -b, -p and -f options are supported with to-proto", name)
+	}
+
+	if flag.NArg() > 1 {
+		mustResolveSchemaFiles("*.colf", flag.Args()[1:]...)
+	} else {
+		mustResolveSchemaFiles("*.colf", ".")
+	}
+	packages, err := colfer.ParseFiles(schemaPaths...)
+	if err != nil {
+		mustNotSchemaErr(err)
+	}
+	for _, p := range packages {
+		p.Name = path.Join(*prefix, p.Name)
+	}
+
+	files, warnings := colfer.ProtoFiles(packages)
+	for _, s := range warnings {
+		log.Printf("%s: WARNING: %s", name, s)
+	}
+	if err := files.Write(*basedir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// MustResolveSchemaFiles adds the paths, with directories expanded by the
+// glob pattern.
+func mustResolveSchemaFiles(pattern string, paths ...string) {
 	for _, path := range paths {
 		info, err := os.Stat(path)
 		if err != nil {
@@ -183,11 +382,14 @@ func mustResolveSchemaFiles(paths ...string) {
 			continue
 		}
 
-		children, err := filepath.Glob(filepath.Join(path, "*.colf"))
+		children, err := filepath.Glob(filepath.Join(path, pattern))
 		if err != nil {
 			log.Fatal(err)
 		}
 		for _, path = range children {
+			if strings.HasSuffix(path, "_test.go") {
+				continue // not part of the package
+			}
 			info, err = os.Stat(path)
 			if err != nil {
 				log.Fatal(err)
@@ -227,7 +429,7 @@ func printManual() {
 		bold + "-b" + clear + " directory] [" +
 		bold + "-p" + clear + " package] \\\n\t\t[" +
 		bold + "-s" + clear + " expression] [" +
-		bold + "-l" + clear + " expression] " + bold + "C" + clear +
+		bold + "-l" + clear + " expression] " + bold + "C" + clear + " | " + bold + "C++" + clear +
 		" [file ...]\n\t" +
 		bold + name + clear + " [" + bold + "-vf" + clear + "] [" +
 		bold + "-b" + clear + " directory] [" +
@@ -242,19 +444,71 @@ func printManual() {
 		bold + "-t" + clear + " files] \\\n\t\t[" +
 		bold + "-x" + clear + " class] [" +
 		bold + "-i" + clear + " interfaces] [" +
-		bold + "-c" + clear + " file] \\\n\t\t[" +
+		bold + "-c" + clear + " file] [" +
+		bold + "-o" + clear + " options] \\\n\t\t[" +
 		bold + "-s" + clear + " expression] [" +
 		bold + "-l" + clear + " expression] " + bold + "Java" + clear +
 		" [file ...]\n\t" +
 		bold + name + clear + " [" + bold + "-vf" + clear + "] [" +
 		bold + "-b" + clear + " directory] [" +
-		bold + "-p" + clear + " package] \\\n\t\t[" +
+		bold + "-p" + clear + " package] [" +
+		bold + "-o" + clear + " options] \\\n\t\t[" +
 		bold + "-s" + clear + " expression] [" +
 		bold + "-l" + clear + " expression] " + bold + "JavaScript" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-vf" + clear + "] [" +
+		bold + "-b" + clear + " directory] [" +
+		bold + "-p" + clear + " package] [" +
+		bold + "-t" + clear + " files] \\\n\t\t[" +
+		bold + "-i" + clear + " interfaces] [" +
+		bold + "-c" + clear + " file] \\\n\t\t[" +
+		bold + "-s" + clear + " expression] [" +
+		bold + "-l" + clear + " expression] " + bold + "Kotlin" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-vf" + clear + "] [" +
+		bold + "-b" + clear + " directory] [" +
+		bold + "-p" + clear + " package] \\\n\t\t[" +
+		bold + "-s" + clear + " expression] [" +
+		bold + "-l" + clear + " expression] " + bold + "JSONSchema" + clear + " | " + bold + "OpenAPI" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-vf" + clear + "] [" +
+		bold + "-b" + clear + " directory] [" +
+		bold + "-p" + clear + " package] [" +
+		bold + "-t" + clear + " files] \\\n\t\t[" +
+		bold + "-x" + clear + " class] [" +
+		bold + "-i" + clear + " interfaces] [" +
+		bold + "-c" + clear + " file] [" +
+		bold + "-o" + clear + " options] \\\n\t\t[" +
+		bold + "-s" + clear + " expression] [" +
+		bold + "-l" + clear + " expression] " + italic + "language" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-v" + clear + "] [" +
+		bold + "-b" + clear + " directory] " + bold + "schema" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-v" + clear + "] [" +
+		bold + "-b" + clear + " directory] " + bold + "describe" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-vj" + clear + "] [" +
+		bold + "-o" + clear + " rules] " + bold + "lint" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-v" + clear + "] [" +
+		bold + "-b" + clear + " directory] " + bold + "from-proto" + clear +
+		" [file ...]\n\t" +
+		bold + name + clear + " [" + bold + "-v" + clear + "] [" +
+		bold + "-b" + clear + " directory] [" +
+		bold + "-p" + clear + " package] " + bold + "to-proto" + clear +
 		" [file ...]\n"
 
 	descriptionSection := bold + "DESCRIPTION" + clear + "\n" +
-		"\tThe output is source code for either C, Go, Java or JavaScript.\n\n" +
+		"\tThe output is source code for either C, C++, Go, Java, JavaScript,\n" +
+		"\tKotlin or any language with a plugin. JSONSchema writes a JSON\n" +
+		"\tSchema per package, and OpenAPI writes the components of an\n" +
+		"\tOpenAPI 3.1 document, both for the JSON representation of the Go\n" +
+		"\tcode.\n\n" +
+		"\tC writes a header and a source file per package. C++ writes the\n" +
+		"\tC files plus a C++17 header and source file (.hpp and .cpp) with\n" +
+		"\tclasses on top of the C code. Kotlin writes a data class per\n" +
+		"\tstruct, with serials identical to the Java output.\n\n" +
 		"\tFor each operand that names a file of a type other than\n" +
 		"\tdirectory, " + bold + "colf" + clear + " reads the content as schema input. For each\n" +
 		"\tnamed directory, " + bold + "colf" + clear + " reads all files with a .colf extension\n" +
@@ -262,7 +516,40 @@ func printManual() {
 		"\tthe current directory are used.\n\n" +
 		"\tA package definition may be spread over several schema files.\n" +
 		"\tThe directory hierarchy of the input is not relevant to the\n" +
-		"\tgenerated code.\n"
+		"\tgenerated code.\n\n" +
+		"\tAny other language is delegated to an executable named " + bold + "colf-gen-" + clear + "\n" +
+		"\tfollowed by the language in lower case, as found in the PATH\n" +
+		"\tenvironment variable. The plugin receives the packages in JSON\n" +
+		"\ton standard input, and it responds on standard output with a\n" +
+		"\tJSON object of relative file paths to base64 encoded content.\n\n" +
+		"\tThe " + bold + "schema" + clear + " mode works the other way around. It reads Go\n" +
+		"\tsource files, with a .go extension for named directories, and it\n" +
+		"\twrites a schema file per package for all structs with colfer\n" +
+		"\ttags, as in `colfer:\"0\"`. Such structs also work with the\n" +
+		"\treflection of Marshal and Unmarshal from the colfer package.\n\n" +
+		"\tThe " + bold + "describe" + clear + " mode writes the parsed schema model to\n" +
+		"\tdescriptor.json, and to descriptor.colfer in the format of the\n" +
+		"\tbundled descriptor/descriptor.colf, for use by other tools.\n\n" +
+		"\tThe " + bold + "lint" + clear + " mode reports schema smells on " + italic + "standard output" + clear + ",\n" +
+		"\teach with a rule identifier: name-collision for names which are\n" +
+		"\tequal after CamelCase conversion, keyword for names which get\n" +
+		"\tan underscore in Java or JavaScript, field-limit for structs\n" +
+		"\tclose to 127 fields, undocumented for fields without comments,\n" +
+		"\tand unused for structs without references. The undocumented and\n" +
+		"\tunused rules are optional, as in " + bold + "-o" + clear + " unused, because root types\n" +
+		"\tare never referenced by definition. There is no rule for\n" +
+		"\trecursive types, as struct references are optional, i.e., a\n" +
+		"\tpointer or a nullable in all languages, so any recursion can\n" +
+		"\tterminate. A comment line\n" +
+		"\t//colf:lint-ignore followed by rule identifiers suppresses those\n" +
+		"\trules for the documented package, struct or field, including\n" +
+		"\tits members. Without identifiers all rules are suppressed.\n\n" +
+		"\tThe " + bold + "from-proto" + clear + " mode converts proto3 messages, with a\n" +
+		"\t.proto extension for named directories, into a schema file each.\n" +
+		"\tThe " + bold + "to-proto" + clear + " mode converts schemas into a .proto file per\n" +
+		"\tpackage. Features which can not be mapped are reported as a\n" +
+		"\twarning on " + italic + "standard error" + clear + ". Note that the serials remain\n" +
+		"\tincompatible.\n"
 
 	tagsSection := bold + "TAGS" + clear + "\n" +
 		"\tTags, a.k.a. annotations, are source code additions for structs\n" +
@@ -272,13 +559,33 @@ func printManual() {
 		"\t\t<line> :≡ <qual> <space> <code> ;\n" +
 		"\t\t<qual> :≡ <package> '.' <dest> ;\n" +
 		"\t\t<dest> :≡ <struct> | <struct> '.' <field> ;\n\n" +
-		"\tLines starting with a '#' are ignored (as comments). Java output\n" +
-		"\tcan take multiple tag lines for the same struct or field. Each\n" +
-		"\tcode line is applied in order of appearance.\n"
+		"\tLines starting with a '#' are ignored (as comments). Java and\n" +
+		"\tKotlin output can take multiple tag lines for the same struct or\n" +
+		"\tfield. Each code line is applied in order of appearance.\n"
+
+	languageOptionsSection := bold + "LANGUAGE OPTIONS" + clear + "\n" +
+		"\tThe " + bold + "-o" + clear + " flag enables the following options. Plugins receive\n" +
+		"\tany option as is.\n\n" +
+		"\t" + bold + "immutable" + clear + "\n" +
+		"\t\tJava classes get private fields with getters only. Values\n" +
+		"\t\tare composed with a nested Builder class instead. Arrays\n" +
+		"\t\tare copied defensively.\n\n" +
+		"\t" + bold + "unsigned" + clear + "\n" +
+		"\t\tJava fields of uint8, uint16 and uint32 get the next wider\n" +
+		"\t\ttype, i.e., short, int and long respectively. Marshal\n" +
+		"\t\tfails on values out of range. Fields of uint64 remain a\n" +
+		"\t\tlong, with an additional getter for the unsigned decimal.\n\n" +
+		"\t" + bold + "module" + clear + "\n" +
+		"\t\tJavaScript packages get an ES module each, with export\n" +
+		"\t\tclasses and an import per referenced package. The file\n" +
+		"\t\tpath is the package name with a .js extension.\n\n" +
+		"\t" + bold + "bigint" + clear + "\n" +
+		"\t\tJavaScript fields of uint64 and int64 get a BigInt value,\n" +
+		"\t\twhich covers the full 64-bit range.\n"
 
 	exitStatusSection := bold + "EXIT STATUS" + clear + "\n" +
 		"\tThe command exits 0 on success, 1 on error and 2 when invoked\n" +
-		"\twithout arguments.\n"
+		"\twithout arguments. Lint findings count as an error.\n"
 
 	examplesSection := bold + "EXAMPLES" + clear + "\n" +
 		"\tCompile ./io.colf with compact limits as C:\n\n" +
@@ -303,8 +610,18 @@ func printManual() {
 	flag.PrintDefaults()
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, tagsSection)
+	fmt.Fprintln(w, languageOptionsSection)
 	fmt.Fprintln(w, exitStatusSection)
 	fmt.Fprintln(w, examplesSection)
 	fmt.Fprintln(w, bugsSection)
 	fmt.Fprint(w, seeAlsoSection)
 }
+
+// MustNotSchemaErr exits with the diagnostics of err, one per line.
+func mustNotSchemaErr(err error) {
+	if list, ok := err.(scanner.ErrorList); ok {
+		scanner.PrintError(os.Stderr, list)
+		os.Exit(1)
+	}
+	log.Fatal(err)
+}
@@ -5,8 +5,11 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"go/token"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"unicode"
@@ -28,6 +31,35 @@ var datatypes = map[string]struct{}{
 	"binary":    {},
 }
 
+// Files is generated code, mapped by slash-separated paths which are relative
+// to the base directory.
+type Files map[string][]byte
+
+// Paths returns the keys in order.
+func (files Files) Paths() []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Write saves each file relative to basedir. Directories are created when
+// needed.
+func (files Files) Write(basedir string) error {
+	for _, path := range files.Paths() {
+		path, data := filepath.Join(basedir, filepath.FromSlash(path)), files[path]
+		if err := os.MkdirAll(filepath.Dir(path), os.ModeDir|os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type Packages []*Package
 
 func (p Packages) Len() int           { return len(p) }
@@ -70,6 +102,10 @@ type Package struct {
 	InterfaceNatives []string
 	// CodeSnippet is helpful in book-keeping functionality.
 	CodeSnippet string
+	// Options are generator specific settings, as in "immutable".
+	Options []string
+	// Pos is the location of the first package clause, if any.
+	Pos token.Position
 }
 
 // DocText returns the documentation lines prefixed with ident.
@@ -89,6 +125,16 @@ func (p *Package) SchemaFileList() string {
 	}
 }
 
+// HasOption returns whether name is in Options.
+func (p *Package) HasOption(name string) bool {
+	for _, o := range p.Options {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Refs returns all direct references sorted by name.
 func (p *Package) Refs() Packages {
 	found := make(map[*Package]struct{})
@@ -153,6 +199,8 @@ type Struct struct {
 	SchemaFile string
 	// TagAdd has optional source code additions.
 	TagAdd []string
+	// Pos is the location of the declaration, if any.
+	Pos token.Position
 }
 
 // DocText returns the documentation lines prefixed with ident.
@@ -247,6 +295,8 @@ type Field struct {
 	TypeList bool
 	// TagAdd has optional source code additions.
 	TagAdd []string
+	// Pos is the location of the declaration, if any.
+	Pos token.Position
 }
 
 // DocText returns the documentation lines prefixed with ident.
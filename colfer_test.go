@@ -1,6 +1,25 @@
 package colfer
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/scanner"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pascaldekloe/colfer/descriptor"
+	gen "github.com/pascaldekloe/colfer/go"
+)
 
 func GoldenTagPackages() Packages {
 	p := &Package{Name: "gen"}
@@ -53,3 +72,838 @@ func TestTagFileErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestParseReader(t *testing.T) {
+	const schema = "package demo\n\ntype course struct {\n\tname text\n\tholes []hole\n}\n\ntype hole struct {\n\tpar uint8\n}\n"
+	packages, err := ParseReader("demo.colf", strings.NewReader(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 1 || packages[0].Name != "demo" || len(packages[0].Structs) != 2 {
+		t.Fatalf("got %+v, want package demo with 2 structs", packages)
+	}
+	if got := packages[0].SchemaFiles; len(got) != 1 || got[0] != "demo.colf" {
+		t.Errorf("got schema files %q, want [demo.colf]", got)
+	}
+	if ref := packages[0].Structs[0].Fields[1].TypeRef; ref != packages[0].Structs[1] {
+		t.Errorf("got holes reference %v, want demo.hole", ref)
+	}
+
+	_, err = ParseReader("broken.colf", strings.NewReader("package demo\n\ntype x struct {\n\ty uint7\n}\n"))
+	if err == nil || err.Error() != `broken.colf:4:2: unknown datatype "uint7" for field demo.x.y` {
+		t.Errorf("got error %v, want unknown datatype", err)
+	}
+}
+
+func TestParseErrorList(t *testing.T) {
+	const schema = "package demo\n\ntype x struct {\n\ty uint7\n\tz []bool\n}\n\ntype e struct {\n}\n\ntype x struct {\n\ta bool\n}\n"
+	_, err := ParseReader("broken.colf", strings.NewReader(schema))
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		t.Fatalf("got error %T %v, want a scanner.ErrorList", err, err)
+	}
+
+	want := []string{
+		`broken.colf:4:2: unknown datatype "uint7" for field demo.x.y`,
+		`broken.colf:5:2: unsupported lists type "bool" for field demo.x.z`,
+		`broken.colf:8:8: demo.e has no fields`,
+		`broken.colf:11:6: duplicate demo.x declaration; previous declaration at broken.colf:3:6`,
+	}
+	if len(list) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(list), len(want), list)
+	}
+	for i, e := range list {
+		if got := e.Error(); got != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// TestParseErrorCascade checks for one error per mistake.
+func TestParseErrorCascade(t *testing.T) {
+	tests := []struct {
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			fsys: fstest.MapFS{
+				"a.colf": {Data: []byte("package a\n\ntype x struct {\n\tn uint8\n}\n}\n")},
+				"b.colf": {Data: []byte("package b\n\ntype y struct {\n\tr a.x\n\ts []a.x\n}\n")},
+			},
+			want: "a.colf:6:1: expected declaration, found '}'",
+		}, {
+			fsys: fstest.MapFS{
+				"a.colf": {Data: []byte("package a\n\ntype x struct {\n\tn uint8\n}\n")},
+				"b.colf": {Data: []byte("package b\n\ntype y struct {\n\tr a.x\n\tfoo\n\ts []a.x\n}\n")},
+			},
+			want: "b.colf:5:2: field 1 from b.y has no name",
+		},
+	}
+	for _, test := range tests {
+		_, err := ParseFS(test.fsys)
+		list, ok := err.(scanner.ErrorList)
+		if !ok {
+			t.Errorf("got error %T %v, want a scanner.ErrorList", err, err)
+			continue
+		}
+		if len(list) != 1 || list[0].Error() != test.want {
+			t.Errorf("got errors:\n%v\nwant only %q", list, test.want)
+		}
+	}
+}
+
+func TestParseFieldLimit(t *testing.T) {
+	var buf strings.Builder
+	buf.WriteString("package demo\n\ntype x struct {\n")
+	for i := 0; i < 127; i++ {
+		fmt.Fprintf(&buf, "\tf%d bool\n", i)
+	}
+	buf.WriteString("}\n")
+	if _, err := ParseReader("max.colf", strings.NewReader(buf.String())); err != nil {
+		t.Fatal("127 fields:", err)
+	}
+
+	schema := strings.Replace(buf.String(), "}\n", "\tf127 bool\n}\n", 1)
+	_, err := ParseReader("over.colf", strings.NewReader(schema))
+	const want = "over.colf:131:2: demo.x has 128 fields, which exceeds the maximum of 127"
+	if err == nil || err.Error() != want {
+		t.Errorf("128 fields: got error %v, want %q", err, want)
+	}
+}
+
+func TestParseFieldDuplicate(t *testing.T) {
+	const schema = "package demo\n\ntype x struct {\n\ta bool\n\tb text\n\ta uint8\n}\n"
+	_, err := ParseReader("dupe.colf", strings.NewReader(schema))
+	const want = "dupe.colf:6:2: duplicate field demo.x.a; previous declaration at dupe.colf:4:2"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestParseRecursion(t *testing.T) {
+	// references are optional, so any recursion can terminate
+	const schema = "package demo\n\ntype x struct {\n\tx x\n\ty y\n}\n\ntype y struct {\n\tx x\n\tys []y\n}\n"
+	packages, err := ParseReader("recursion.colf", strings.NewReader(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := packages[0].Structs[0]
+	if x.Fields[0].TypeRef != x {
+		t.Errorf("got reference %v, want self", x.Fields[0].TypeRef)
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := packages[0]
+	if pkg.Pos.Filename != "testdata/test.colf" || pkg.Pos.Line == 0 {
+		t.Errorf("got package position %s", pkg.Pos)
+	}
+	for _, s := range pkg.Structs {
+		if !s.Pos.IsValid() || s.Pos.Line <= pkg.Pos.Line {
+			t.Errorf("got %s position %s", s, s.Pos)
+		}
+		for _, f := range s.Fields {
+			if !f.Pos.IsValid() || f.Pos.Line <= s.Pos.Line {
+				t.Errorf("got %s position %s, not after struct at %s", f, f.Pos, s.Pos)
+			}
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	const schema = `package demo
+
+// A is the root.
+//colf:lint-ignore unused
+type a struct {
+	// B is documented.
+	b b
+	// Foo_bar collides.
+	foo_bar bool
+	// FooBar collides.
+	fooBar bool
+	// Class is a keyword.
+	class text
+	//colf:lint-ignore
+	new text
+}
+
+// B is referenced.
+type b struct {
+	// C is a reference.
+	c c
+	// Bs are references.
+	bs []b
+	d bool
+}
+
+// C is referenced.
+type c struct {
+	// B is a reference.
+	b b
+}
+
+// X is unused.
+type x struct {
+	// X is a self reference.
+	x x
+}
+`
+	var buf strings.Builder
+	buf.WriteString(schema)
+	buf.WriteString("\n// Wide is close to the field limit.\n//colf:lint-ignore unused undocumented\ntype wide struct {\n")
+	for i := 0; i < 112; i++ {
+		fmt.Fprintf(&buf, "\tf%d bool\n", i)
+	}
+	buf.WriteString("}\n")
+	packages, err := ParseReader("demo.colf", strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"demo.colf:11:2: demo.a.fooBar and demo.a.foo_bar both map to FooBar (name-collision)",
+		"demo.colf:13:2: demo.a.class gets name class_ in Java (keyword)",
+		"demo.colf:13:2: demo.a.class gets name class_ in ECMAScript (keyword)",
+		"demo.colf:24:2: demo.b.d has no documentation (undocumented)",
+		"demo.colf:34:6: demo.x is not referenced by any field (unused)",
+		"demo.colf:41:6: demo.wide has 112 fields, close to the maximum of 127 (field-limit)",
+	}
+	findings := Lint(packages, LintOptional...)
+	if len(findings) != len(want) {
+		t.Errorf("got %d findings, want %d", len(findings), len(want))
+	}
+	for i, f := range findings {
+		if i < len(want) && f.String() != want[i] {
+			t.Errorf("finding %d: got %q, want %q", i, f, want[i])
+		}
+	}
+
+	if len(findings) != 0 {
+		data, err := json.Marshal(findings[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		const want = `{"file":"demo.colf","line":11,"column":2,"rule":"name-collision","message":"demo.a.fooBar and demo.a.foo_bar both map to FooBar"}`
+		if string(data) != want {
+			t.Errorf("got JSON %s, want %s", data, want)
+		}
+	}
+}
+
+// TestLintReference checks the default rules on the reference schema.
+func TestLintReference(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range Lint(packages) {
+		t.Error("got finding:", f)
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.colf":     {Data: []byte("package demo\n\ntype a struct {\n\tb b\n}\n")},
+		"sub/b.colf": {Data: []byte("package demo\n\ntype b struct {\n\tc bool\n}\n")},
+		"README":     {Data: []byte("not a schema")},
+	}
+
+	packages, err := ParseFS(fsys, "*.colf", "sub/*.colf", "a.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 1 || len(packages[0].Structs) != 2 {
+		t.Fatalf("got %+v, want package demo with 2 structs", packages)
+	}
+	if got := packages[0].Structs[0].Fields[0].TypeRef; got != packages[0].Structs[1] {
+		t.Errorf("got reference %v, want demo.b", got)
+	}
+
+	if _, err := ParseFS(fsys); err == nil {
+		t.Error("root directory only: no error for unresolved reference to demo.b")
+	}
+	_, err = ParseFS(fsys, "*.txt")
+	if err == nil || err.Error() != `colfer: pattern "*.txt" matches no files` {
+		t.Errorf("got error %v, want pattern match failure", err)
+	}
+}
+
+func TestGenerateFiles(t *testing.T) {
+	const schema = "// Package demo is a test.\npackage demo\n\ntype course struct {\n\tname text\n}\n"
+	golden := []struct {
+		generate func(Packages) (Files, error)
+		paths    []string
+	}{
+		{GenerateCFiles, []string{"com/example/demo.c", "com/example/demo.h"}},
+		{GenerateCPPFiles, []string{"com/example/demo.c", "com/example/demo.cpp", "com/example/demo.h", "com/example/demo.hpp"}},
+		{GenerateGoFiles, []string{"com/example/demo/Colfer.go"}},
+		{GenerateJavaFiles, []string{"com/example/demo/Course.java", "com/example/demo/package-info.java"}},
+		{GenerateECMAFiles, []string{"Colfer.js", "ColferRPC.js"}},
+		{GenerateKotlinFiles, []string{"com/example/demo/Course.kt"}},
+	}
+	for _, gold := range golden {
+		packages, err := ParseReader("demo.colf", strings.NewReader(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		packages[0].Name = "com/example/demo"
+		packages[0].SizeMax = "16 * 1024 * 1024"
+		packages[0].ListMax = "64 * 1024"
+
+		files, err := gold.generate(packages)
+		if err != nil {
+			t.Errorf("%q: %s", gold.paths, err)
+			continue
+		}
+		if got := files.Paths(); !reflect.DeepEqual(got, gold.paths) {
+			t.Errorf("got paths %q, want %q", got, gold.paths)
+		}
+		for path, data := range files {
+			if len(data) == 0 {
+				t.Errorf("%s: no content", path)
+			}
+		}
+	}
+}
+
+// TestGenerateOptions is a smoke test for the language options. The test
+// suites per language cover the behaviour of the generated code.
+func TestGenerateOptions(t *testing.T) {
+	tests := []struct {
+		lang    string
+		options []string
+		// schemas default to testdata/test.colf
+		schemas []string
+		// edit is an optional schema change
+		edit func(Packages)
+		// fail flags an expected error
+		fail bool
+	}{
+		{lang: "java", options: []string{JavaImmutable}},
+		{lang: "java", options: []string{JavaImmutable}, edit: func(p Packages) { p[0].Structs[0].Name = "builder" }, fail: true},
+		{lang: "java", options: []string{JavaUnsigned}},
+		{lang: "kotlin"},
+		{lang: "kotlin", edit: func(p Packages) { p[0].Structs[0].Fields[16].Type = "int32" }, fail: true},
+		{lang: "js", options: []string{ECMAModule}},
+		{lang: "js", options: []string{ECMABigInt}},
+		{lang: "js", options: []string{ECMAModule}, schemas: []string{"testdata/break.colf", "testdata/break-refs.colf"}},
+		{lang: "js", options: []string{ECMAModule}, schemas: []string{"testdata/break.colf", "testdata/break-refs.colf"}, edit: func(p Packages) { p[0].Options = nil }, fail: true},
+	}
+	for _, test := range tests {
+		if test.schemas == nil {
+			test.schemas = []string{"testdata/test.colf"}
+		}
+		packages, err := ParseFiles(test.schemas...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range packages {
+			p.SizeMax = "16 * 1024 * 1024"
+			p.ListMax = "64 * 1024"
+			p.Options = test.options
+		}
+		if test.edit != nil {
+			test.edit(packages)
+		}
+
+		files, err := LookupGenerator(test.lang).Generate(packages)
+		switch {
+		case test.fail:
+			if err == nil {
+				t.Errorf("%s with options %q: no error for edited schema", test.lang, test.options)
+			}
+		case err != nil:
+			t.Errorf("%s with options %q: %s", test.lang, test.options, err)
+		case len(files) == 0:
+			t.Errorf("%s with options %q: no files", test.lang, test.options)
+		}
+	}
+}
+
+func TestPackagesJSON(t *testing.T) {
+	want, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want[0].Options = []string{"immutable"}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Packages
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON round trip mismatch on %s", data)
+	}
+}
+
+func TestGeneratorFeatures(t *testing.T) {
+	for _, name := range GeneratorNames() {
+		if _, ok := LookupGenerator(name).(FeatureGenerator); !ok && name != "feature-test" {
+			t.Errorf("generator %s has no features", name)
+		}
+	}
+	if got := GeneratorFeatures(LookupGenerator("JS")).Options; !reflect.DeepEqual(got, []string{ECMAModule, ECMABigInt}) {
+		t.Errorf("got JavaScript options %q", got)
+	}
+	if got := GeneratorFeatures(LookupGenerator("go")).Tags; got != (TagOptions{FieldAllow: TagSingle}) {
+		t.Errorf("got Go tag options %+v", got)
+	}
+
+	if LookupGenerator("feature-test") == nil {
+		RegisterGenerator("feature-test", GeneratorFunc(GenerateGoFiles))
+	}
+	got := GeneratorFeatures(LookupGenerator("Feature-Test"))
+	if !got.SuperClass || !got.Interfaces || !got.CodeSnippet || got.Options != nil || got.Tags.StructAllow != TagMulti || got.Tags.FieldAllow != TagMulti {
+		t.Errorf("got features %+v for a generator without declaration, want any", got)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell:", err)
+	}
+	path := filepath.Join(t.TempDir(), "colf-gen-test")
+	script := "#!/bin/sh\ngrep -q '\"name\":\"gen\"' && echo '{\"gen/out.txt\":\"aGVsbG8=\"}'\n"
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := (&Plugin{Path: path}).Generate(packages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(files["gen/out.txt"]); len(files) != 1 || got != "hello" {
+		t.Errorf("got files %q, want gen/out.txt with hello", files)
+	}
+
+	for _, name := range []string{"", "/etc/out.txt", "../out.txt", "gen/../../out.txt", `..\\out.txt`, `gen\\out.txt`} {
+		script := "#!/bin/sh\nprintf '%s\\n' '{\"" + name + "\":\"aGVsbG8=\"}'\n"
+		if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+		_, err := (&Plugin{Path: path}).Generate(packages)
+		if err == nil || !strings.Contains(err.Error(), "not relative to the base directory") {
+			t.Errorf("path %q: got error %v, want rejection", name, err)
+		}
+	}
+}
+
+func TestDescriptor(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := packages.Descriptor().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got descriptor.Schema
+	if err := got.UnmarshalBinary(serial); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Packages) != 1 || got.Packages[0].Name != "gen" || len(got.Packages[0].Structs) != len(packages[0].Structs) {
+		t.Fatalf("got %+v, want package gen with %d structs", got.Packages, len(packages[0].Structs))
+	}
+	f := got.Packages[0].Structs[0].Fields[11]
+	if f.Index != 11 || f.Name != "os" || f.Datatype != "o" || !f.List || f.TypeRef != "gen.o" {
+		t.Errorf("got field %+v, want index 11 os as list of gen.o", f)
+	}
+	if len(f.Docs) != 1 || f.Docs[0] != "// Os tests data structure lists." {
+		t.Errorf("got docs %q", f.Docs)
+	}
+}
+
+func TestParseProto(t *testing.T) {
+	const src = `syntax = "proto3";
+
+// Package demo is a test.
+package demo;
+
+import "google/protobuf/timestamp.proto";
+import "other.proto";
+
+// Course is a golf ground.
+message Course {
+	// Holes are the cups.
+	repeated Hole holes = 3;
+	string name = 1; // trailing
+	google.protobuf.Timestamp opened = 2;
+	Kind kind = 4;
+	map<string, string> labels = 5;
+	repeated bool flags = 6;
+	oneof contact {
+		string email = 7;
+		bytes phone = 8 [deprecated = true];
+	}
+	other.Type ext = 9;
+
+	message Hole {
+		uint32 par = 1;
+	}
+	enum Kind {
+		PUBLIC = 0;
+		PRIVATE = 1;
+	}
+}
+
+service Booking {
+	rpc Book(Course) returns (Course);
+}
+`
+	pkg, warnings, err := ParseProto("demo.proto", strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWarnings := []string{
+		`demo.proto:7: import "other.proto" ignored; types from other files are not mapped`,
+		"demo.proto:33: service not supported",
+		"demo.proto:18: oneof in message Course flattened; the exclusivity is lost",
+		"demo.proto:15: enum field Course.kind mapped to int32; the symbols are lost",
+		"demo.proto:16: map field in message Course not supported",
+		"demo.proto:17: field Course.flags not mapped; no lists of bool in Colfer",
+		"demo.proto:22: field Course.ext of type other.Type not mapped; unknown or external type",
+	}
+	sort.Strings(warnings)
+	sort.Strings(wantWarnings)
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("got warnings:\n%s\nwant:\n%s", strings.Join(warnings, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+
+	var buf strings.Builder
+	if err := pkg.WriteSchema(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const want = `// Package demo is a test.
+package demo
+
+// Course is a golf ground.
+type course struct {
+	name   text
+	opened timestamp
+	// Holes are the cups.
+	holes []courseHole
+	kind  int32
+	email text
+	phone binary
+}
+
+type courseHole struct {
+	par uint32
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got schema:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, warnings := ProtoFiles(packages)
+	if len(warnings) != 2 {
+		t.Errorf("got warnings %q, want uint8 and uint16 widening only", warnings)
+	}
+
+	pkg, warnings, err := ParseProto("gen.proto", bytes.NewReader(files["gen.proto"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %q", warnings)
+	}
+	for i, want := range packages[0].Structs {
+		got := pkg.Structs[i]
+		if len(got.Fields) != len(want.Fields) {
+			t.Errorf("%s: got %d fields, want %d", want, len(got.Fields), len(want.Fields))
+			continue
+		}
+		for j, f := range got.Fields {
+			w := want.Fields[j]
+			wantType := w.Type
+			if wantType == "uint8" || wantType == "uint16" {
+				wantType = "uint32"
+			}
+			if !strings.EqualFold(f.Name, w.Name) || f.Type != wantType || f.TypeList != w.TypeList {
+				t.Errorf("got field %s %s (list %t), want %s %s (list %t)", f, f.Type, f.TypeList, w, wantType, w.TypeList)
+			}
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf", "testdata/break.colf", "testdata/break-refs.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packages {
+		p.SizeMax = "16 * 1024 * 1024"
+		p.ListMax = "(1 << 16) / 2"
+	}
+	files, err := GenerateJSONSchemaFiles(packages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Defs map[string]struct {
+			Description string
+			Properties  map[string]map[string]interface{}
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(files["gen.schema.json"], &doc); err != nil {
+		t.Fatal(err)
+	}
+	o := doc.Defs["o"]
+	if o.Description != "O contains all supported data types." {
+		t.Errorf("got description %q", o.Description)
+	}
+	golden := []struct{ field, key, want string }{
+		{"T", "format", "date-time"},
+		{"A", "contentEncoding", "base64"},
+		{"A", "maxLength", "2.2369624e+07"},
+		{"A", "type", "[string null]"},
+		{"S", "maxLength", "1.6777216e+07"},
+		{"U16", "maximum", "65535"},
+		{"I32", "minimum", "-2.147483648e+09"},
+		{"Os", "maxItems", "32768"},
+		{"Os", "type", "[array null]"},
+		{"S", "description", "S tests text."},
+	}
+	for _, gold := range golden {
+		if got := fmt.Sprint(o.Properties[gold.field][gold.key]); got != gold.want {
+			t.Errorf("field %s got %s %s, want %s", gold.field, gold.key, got, gold.want)
+		}
+	}
+	if got := jsonSchemaRef(o.Properties["O"]); got != "#/$defs/o" {
+		t.Errorf("field O got reference %s, want #/$defs/o", got)
+	}
+	items, _ := o.Properties["Os"]["items"].(map[string]interface{})
+	if got := jsonSchemaRef(items); got != "#/$defs/o" {
+		t.Errorf("field Os got items reference %s, want #/$defs/o", got)
+	}
+
+	if err := json.Unmarshal(files["void.schema.json"], &doc); err != nil {
+		t.Fatal(err)
+	}
+	items, _ = doc.Defs["class"].Properties["Public"]["items"].(map[string]interface{})
+	if got := jsonSchemaRef(items); got != "static.schema.json#/$defs/int" {
+		t.Errorf("got cross-package reference %s, want static.schema.json#/$defs/int", got)
+	}
+}
+
+// JSONSchemaRef returns the reference of a nullable data structure.
+func jsonSchemaRef(prop map[string]interface{}) string {
+	anyOf, _ := prop["anyOf"].([]interface{})
+	if len(anyOf) != 2 || fmt.Sprint(anyOf[1]) != "map[type:null]" {
+		return fmt.Sprintf("<not nullable: %v>", prop)
+	}
+	ref, _ := anyOf[0].(map[string]interface{})
+	return fmt.Sprint(ref["$ref"])
+}
+
+// TestJSONSchemaGo validates the encoding/json output of the Go code in
+// directory go against the JSON Schema.
+func TestJSONSchemaGo(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packages {
+		p.SizeMax = "16 * 1024 * 1024"
+		p.ListMax = "64 * 1024"
+	}
+	files, err := GenerateJSONSchemaFiles(packages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(files["gen.schema.json"], &schema); err != nil {
+		t.Fatal(err)
+	}
+	defs, _ := schema["$defs"].(map[string]interface{})
+
+	golden := []interface{}{
+		&gen.O{},
+		&gen.O{
+			B: true, U32: math.MaxUint32, U64: math.MaxUint64,
+			I32: math.MinInt32, I64: math.MinInt64,
+			F32: math.MaxFloat32, F64: math.SmallestNonzeroFloat64,
+			T: time.Unix(1, 2).UTC(), S: "Hello", A: []byte{0, 1},
+			O:  &gen.O{S: "nested"},
+			Os: []*gen.O{{}, {B: true}},
+			Ss: []string{"", "a"}, As: [][]byte{nil, {2}},
+			U8: math.MaxUint8, U16: math.MaxUint16,
+			F32s: []float32{1.5}, F64s: []float64{},
+		},
+		&gen.EmbedO{},
+		&gen.EmbedO{Inner: &gen.O{}},
+	}
+	for _, v := range golden {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+
+		def := "o"
+		if _, ok := v.(*gen.EmbedO); ok {
+			def = "EmbedO"
+		}
+		if err := validateJSONSchema(defs[def], defs, doc, "$"); err != nil {
+			t.Errorf("%s: %s", data, err)
+		}
+	}
+}
+
+// ValidateJSONSchema checks doc against the subset of JSON Schema in use.
+func validateJSONSchema(schema interface{}, defs map[string]interface{}, doc interface{}, path string) error {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: no schema", path)
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		return validateJSONSchema(defs[strings.TrimPrefix(ref, "#/$defs/")], defs, doc, path)
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		for _, sub := range anyOf {
+			if validateJSONSchema(sub, defs, doc, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: no match for any of %v", path, anyOf)
+	}
+
+	var docType string
+	switch v := doc.(type) {
+	case nil:
+		docType = "null"
+	case bool:
+		docType = "boolean"
+	case string:
+		docType = "string"
+		if max, ok := s["maxLength"].(float64); ok && float64(len([]rune(v))) > max {
+			return fmt.Errorf("%s: string exceeds maxLength", path)
+		}
+	case json.Number:
+		docType = "number"
+		if !strings.ContainsAny(v.String(), ".eE") {
+			docType = "integer"
+		}
+		n, _ := new(big.Float).SetString(v.String())
+		if min, ok := s["minimum"].(float64); ok && n.Cmp(big.NewFloat(min)) < 0 {
+			return fmt.Errorf("%s: %s below minimum %g", path, v, min)
+		}
+		if max, ok := s["maximum"].(float64); ok && n.Cmp(big.NewFloat(max)) > 0 {
+			return fmt.Errorf("%s: %s above maximum %g", path, v, max)
+		}
+	case []interface{}:
+		docType = "array"
+		if max, ok := s["maxItems"].(float64); ok && float64(len(v)) > max {
+			return fmt.Errorf("%s: array exceeds maxItems", path)
+		}
+		for i, item := range v {
+			if err := validateJSONSchema(s["items"], defs, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		docType = "object"
+		props, _ := s["properties"].(map[string]interface{})
+		for name, value := range v {
+			prop, ok := props[name]
+			if !ok {
+				return fmt.Errorf("%s: property %q not defined", path, name)
+			}
+			if err := validateJSONSchema(prop, defs, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	types := fmt.Sprint(s["type"])
+	if strings.HasPrefix(types, "[") {
+		types = strings.Trim(types, "[]")
+	}
+	for _, t := range strings.Fields(types) {
+		if t == docType || t == "number" && docType == "integer" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: got %s, want type %s", path, docType, types)
+}
+
+func TestOpenAPI(t *testing.T) {
+	packages, err := ParseFiles("testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := GenerateOpenAPIFiles(packages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI    string
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal(files["openapi.json"], &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("got OpenAPI version %q, want 3.1.0", doc.OpenAPI)
+	}
+	ref := jsonSchemaRef(doc.Components.Schemas["gen.EmbedO"].Properties["Inner"])
+	if ref != "#/components/schemas/gen.o" {
+		t.Errorf("got reference %q, want #/components/schemas/gen.o", ref)
+	}
+}
+
+// TestGenerateECMARPC checks the header code of the RPC client against the
+// schema from package rpc.
+func TestGenerateECMARPC(t *testing.T) {
+	files, err := GenerateECMAFiles(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(files["ColferRPC.js"])
+
+	packages, err := ParseFiles("rpc/internal.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecmaNames(packages)
+	for _, s := range packages[0].Structs {
+		if want := "\tthis." + s.NameNative + " = function(init) {\n"; !strings.Contains(code, want) {
+			t.Errorf("generated code misses %q", want)
+		}
+		for _, f := range s.Fields {
+			if want := "\t\tthis." + f.NameNative + " = "; !strings.Contains(code, want) {
+				t.Errorf("generated code misses %q", want)
+			}
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pascaldekloe/name"
+)
+
+// CPPKeywords are the reserved tokens for C++ code, including the C keywords
+// and the methods of the generated classes.
+var cppKeywords = map[string]struct{}{
+	"alignas": {}, "alignof": {}, "and": {}, "and_eq": {},
+	"asm": {}, "bitand": {}, "bitor": {}, "bool": {},
+	"catch": {}, "char8_t": {}, "char16_t": {}, "char32_t": {},
+	"class": {}, "compl": {}, "concept": {}, "consteval": {},
+	"constexpr": {}, "constinit": {}, "const_cast": {}, "co_await": {},
+	"co_return": {}, "co_yield": {}, "decltype": {}, "delete": {},
+	"dynamic_cast": {}, "explicit": {}, "export": {}, "false": {},
+	"friend": {}, "inline": {}, "mutable": {}, "namespace": {},
+	"new": {}, "noexcept": {}, "not": {}, "not_eq": {},
+	"nullptr": {}, "operator": {}, "or": {}, "or_eq": {},
+	"private": {}, "protected": {}, "public": {}, "reinterpret_cast": {},
+	"requires": {}, "static_assert": {}, "static_cast": {}, "template": {},
+	"this": {}, "thread_local": {}, "throw": {}, "true": {},
+	"try": {}, "typeid": {}, "typename": {}, "using": {},
+	"virtual": {}, "wchar_t": {}, "xor": {}, "xor_eq": {},
+
+	"marshal": {}, "unmarshal": {}, "to_c": {}, "from_c": {},
+}
+
+func init() {
+	for k := range cKeywords {
+		cppKeywords[k] = struct{}{}
+	}
+}
+
+// GenerateCPP writes the code of GenerateC, plus a C++17 wrapper in a
+// "<package>.hpp" and a "<package>.cpp" file per package. The classes have
+// value semantics, and they use the C code for serialization.
+func GenerateCPP(basedir string, packages Packages) error {
+	files, err := GenerateCPPFiles(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// GenerateCPPFiles returns the code of GenerateCPP.
+func GenerateCPPFiles(packages Packages) (Files, error) {
+	for _, p := range packages {
+		for _, t := range p.Structs {
+			for _, f := range t.Fields {
+				if f.TypeList && (f.Type == "int32" || f.Type == "int64") {
+					return nil, fmt.Errorf("colfer: integer list of field %s not supported with C++", f)
+				}
+			}
+		}
+	}
+
+	files, err := generateCFiles(packages, cppKeywords)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := template.FuncMap{
+		"upper": strings.ToUpper,
+		"base":  path.Base,
+		// the header path of package to, relative to package from
+		"include": func(from, to *Package) string {
+			return relativePath(from.Name, to.Name) + ".hpp"
+		},
+		"cppNamespace": cppNamespace,
+		"cppClass":     cppClass,
+		"cppQualified": cppQualified,
+		"cppForeign":   cppForeign,
+		"cppType":      cppType,
+		"cppInit": func(f *Field) string {
+			switch {
+			case f.TypeList || f.TypeRef != nil:
+				return ""
+			case f.Type == "bool":
+				return " = false"
+			case f.Type == "timestamp":
+				return " = {}"
+			case f.Type == "text" || f.Type == "binary":
+				return ""
+			default:
+				return " = 0"
+			}
+		},
+	}
+	headerTemplate := template.Must(template.New("C++-header").Funcs(funcs).Parse(cppHeaderTemplate))
+	codeTemplate := template.Must(template.New("C++").Funcs(funcs).Parse(cppTemplate))
+
+	for _, p := range packages {
+		var header, code bytes.Buffer
+		if err := headerTemplate.Execute(&header, p); err != nil {
+			return nil, err
+		}
+		if err := codeTemplate.Execute(&code, p); err != nil {
+			return nil, err
+		}
+		files[p.Name+".hpp"] = header.Bytes()
+		files[p.Name+".cpp"] = code.Bytes()
+	}
+	return files, nil
+}
+
+// CPPNamespace returns the namespace of p.
+func cppNamespace(p *Package) string {
+	segments := strings.Split(p.Name, "/")
+	for i, s := range segments {
+		if _, ok := cppKeywords[s]; ok {
+			segments[i] = s + "_"
+		}
+	}
+	return strings.Join(segments, "::")
+}
+
+// CPPClass returns the class name of t.
+func cppClass(t *Struct) string {
+	s := name.CamelCase(t.Name, true)
+	if _, ok := cppKeywords[s]; ok {
+		s += "_"
+	}
+	return s
+}
+
+// CPPQualified returns the fully qualified class name of t.
+func cppQualified(t *Struct) string {
+	return "::" + cppNamespace(t.Pkg) + "::" + cppClass(t)
+}
+
+// CPPForeign returns the structs from other packages which are referenced by
+// p, ordered by package.
+func cppForeign(p *Package) []*Struct {
+	var a []*Struct
+	seen := make(map[*Struct]bool)
+	for _, t := range p.Structs {
+		for _, f := range t.Fields {
+			if f.TypeRef != nil && f.TypeRef.Pkg != p && !seen[f.TypeRef] {
+				seen[f.TypeRef] = true
+				a = append(a, f.TypeRef)
+			}
+		}
+	}
+	sort.SliceStable(a, func(i, j int) bool { return a[i].Pkg.Name < a[j].Pkg.Name })
+	return a
+}
+
+// CPPType returns the C++ declaration of f.
+func cppType(f *Field) string {
+	var s string
+	switch f.Type {
+	case "bool":
+		s = "bool"
+	case "uint8", "uint16", "uint32", "uint64", "int32", "int64":
+		s = "std::" + f.Type + "_t"
+	case "float32":
+		s = "float"
+	case "float64":
+		s = "double"
+	case "timestamp":
+		s = "std::timespec"
+	case "text":
+		s = "std::string"
+	case "binary":
+		s = "std::vector<std::uint8_t>"
+	default:
+		s = cppQualified(f.TypeRef)
+		if !f.TypeList {
+			return "std::unique_ptr<" + s + ">"
+		}
+	}
+	if f.TypeList {
+		return "std::vector<" + s + ">"
+	}
+	return s
+}
+
+const cppHeaderTemplate = `// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
+
+#ifndef COLFER_{{upper .NameNative}}_HPP
+#define COLFER_{{upper .NameNative}}_HPP
+
+#include "{{base .Name}}.h"
+
+#include <cstddef>
+#include <cstdint>
+#include <ctime>
+#include <memory>
+#include <string>
+#include <vector>
+#if __has_include(<span>)
+#include <span>
+#endif
+{{range cppForeign .}}
+namespace {{cppNamespace .Pkg}} { class {{cppClass .}}; }
+{{- end}}
+
+namespace {{cppNamespace .}} {
+{{range .Structs}}
+class {{cppClass .}};
+{{- end}}
+{{range .Structs}}{{$class := cppClass .}}
+{{.DocText "// "}}
+class {{$class}} {
+public:
+{{- range .Fields}}
+{{.DocText "\t// "}}
+	{{cppType .}} {{.NameNative}}{{cppInit .}};
+{{- end}}
+
+	{{$class}}();
+	{{$class}}(const {{$class}}&);
+	{{$class}}({{$class}}&&) noexcept;
+	{{$class}}& operator=(const {{$class}}&);
+	{{$class}}& operator=({{$class}}&&) noexcept;
+	~{{$class}}();
+
+	// marshal replaces the content of buf with the Colfer serial. A breach
+	// of either {{$.NameNative}}_size_max or {{$.NameNative}}_list_max is thrown as a
+	// std::system_error with EFBIG.
+	void marshal(std::vector<std::uint8_t>& buf) const;
+
+	// unmarshal decodes data as Colfer, and it returns the number of octets
+	// read. The return is zero when data is incomplete. Other errors are
+	// thrown as a std::system_error with the errno of {{.NameNative}}_unmarshal.
+	std::size_t unmarshal(const void* data, std::size_t len);
+#ifdef __cpp_lib_span
+	// unmarshal decodes data as Colfer, like the pointer variant.
+	std::size_t unmarshal(std::span<const std::uint8_t> data) {
+		return unmarshal(data.data(), data.size());
+	}
+#endif
+
+	// to_c sets the fields of c with references into this object. Any
+	// additional memory is owned by hold.
+	void to_c({{.NameNative}}& c, std::vector<std::shared_ptr<void>>& hold) const;
+
+	// from_c sets all fields to the values of c.
+	void from_c(const {{.NameNative}}& c);
+};
+{{end}}
+} // namespace {{cppNamespace .}}
+
+#endif
+`
+
+const cppTemplate = `// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
+
+#include "{{base .Name}}.hpp"
+{{- range .Refs}}
+#include "{{include $ .}}"
+{{- end}}
+
+#include <cerrno>
+#include <system_error>
+
+namespace {{cppNamespace .}} {
+{{range .Structs}}{{$class := cppClass .}}
+{{$class}}::{{$class}}() = default;
+
+{{$class}}::{{$class}}(const {{$class}}& x)
+{{- range $i, $f := .Fields}}
+	{{if $i}}, {{else}}: {{end}}{{.NameNative}}(
+	{{- if and .TypeRef (not .TypeList)}}x.{{.NameNative}} ? std::make_unique<{{cppQualified .TypeRef}}>(*x.{{.NameNative}}) : nullptr
+	{{- else}}x.{{.NameNative}}{{end}})
+{{- end}} {}
+
+{{$class}}::{{$class}}({{$class}}&&) noexcept = default;
+
+{{$class}}& {{$class}}::operator=(const {{$class}}& x) {
+	if (this != &x) *this = {{$class}}(x);
+	return *this;
+}
+
+{{$class}}& {{$class}}::operator=({{$class}}&&) noexcept = default;
+
+{{$class}}::~{{$class}}() = default;
+
+void {{$class}}::marshal(std::vector<std::uint8_t>& buf) const {
+	{{.NameNative}} c{};
+	std::vector<std::shared_ptr<void>> hold;
+	to_c(c, hold);
+
+	std::size_t n = {{.NameNative}}_marshal_len(&c);
+	if (!n) throw std::system_error(errno, std::generic_category(), "{{.NameNative}}_marshal_len");
+	buf.resize(n);
+	{{.NameNative}}_marshal(&c, buf.data());
+}
+
+std::size_t {{$class}}::unmarshal(const void* data, std::size_t len) {
+	{{.NameNative}} c{};
+	std::unique_ptr<{{.NameNative}}, void (*)({{.NameNative}}*)> guard(&c, {{.NameNative}}_free);
+
+	std::size_t n = {{.NameNative}}_unmarshal(&c, data, len);
+	if (!n) {
+		if (errno == EWOULDBLOCK) return 0;
+		throw std::system_error(errno, std::generic_category(), "{{.NameNative}}_unmarshal");
+	}
+	from_c(c);
+	return n;
+}
+
+void {{$class}}::to_c({{.NameNative}}& c, std::vector<std::shared_ptr<void>>& hold) const {
+	(void) hold;
+{{- range .Fields}}
+ {{- if .TypeList}}
+  {{- if eq .Type "float32" "float64"}}
+	c.{{.NameNative}}.list = const_cast<{{if eq .Type "float32"}}float{{else}}double{{end}}*>(this->{{.NameNative}}.data());
+	c.{{.NameNative}}.len = this->{{.NameNative}}.size();
+  {{- else}}
+	if (!this->{{.NameNative}}.empty()) {
+		std::size_t n = this->{{.NameNative}}.size();
+   {{- if eq .Type "text"}}
+		std::shared_ptr<colfer_text[]> list(new colfer_text[n]());
+		for (std::size_t i = 0; i < n; ++i) {
+			list[i].utf8 = this->{{.NameNative}}[i].data();
+			list[i].len = this->{{.NameNative}}[i].size();
+		}
+   {{- else if eq .Type "binary"}}
+		std::shared_ptr<colfer_binary[]> list(new colfer_binary[n]());
+		for (std::size_t i = 0; i < n; ++i) {
+			list[i].octets = const_cast<std::uint8_t*>(this->{{.NameNative}}[i].data());
+			list[i].len = this->{{.NameNative}}[i].size();
+		}
+   {{- else}}
+		std::shared_ptr<{{.TypeRef.NameNative}}[]> list(new {{.TypeRef.NameNative}}[n]());
+		for (std::size_t i = 0; i < n; ++i)
+			this->{{.NameNative}}[i].to_c(list[i], hold);
+   {{- end}}
+		c.{{.NameNative}}.list = list.get();
+		c.{{.NameNative}}.len = n;
+		hold.push_back(list);
+	}
+  {{- end}}
+ {{- else if eq .Type "text"}}
+	c.{{.NameNative}}.utf8 = this->{{.NameNative}}.data();
+	c.{{.NameNative}}.len = this->{{.NameNative}}.size();
+ {{- else if eq .Type "binary"}}
+	c.{{.NameNative}}.octets = const_cast<std::uint8_t*>(this->{{.NameNative}}.data());
+	c.{{.NameNative}}.len = this->{{.NameNative}}.size();
+ {{- else if .TypeRef}}
+	if (this->{{.NameNative}}) {
+		auto ref = std::make_shared<{{.TypeRef.NameNative}}>();
+		this->{{.NameNative}}->to_c(*ref, hold);
+		c.{{.NameNative}} = ref.get();
+		hold.push_back(ref);
+	}
+ {{- else}}
+	c.{{.NameNative}} = this->{{.NameNative}};
+ {{- end}}
+{{- end}}
+}
+
+void {{$class}}::from_c(const {{.NameNative}}& c) {
+{{- range .Fields}}
+ {{- if .TypeList}}
+  {{- if eq .Type "float32" "float64"}}
+	this->{{.NameNative}}.assign(c.{{.NameNative}}.list, c.{{.NameNative}}.list + c.{{.NameNative}}.len);
+  {{- else}}
+	this->{{.NameNative}}.resize(c.{{.NameNative}}.len);
+	for (std::size_t i = 0; i < c.{{.NameNative}}.len; ++i)
+   {{- if eq .Type "text"}}
+		this->{{.NameNative}}[i].assign(c.{{.NameNative}}.list[i].utf8, c.{{.NameNative}}.list[i].utf8 + c.{{.NameNative}}.list[i].len);
+   {{- else if eq .Type "binary"}}
+		this->{{.NameNative}}[i].assign(c.{{.NameNative}}.list[i].octets, c.{{.NameNative}}.list[i].octets + c.{{.NameNative}}.list[i].len);
+   {{- else}}
+		this->{{.NameNative}}[i].from_c(c.{{.NameNative}}.list[i]);
+   {{- end}}
+  {{- end}}
+ {{- else if eq .Type "text"}}
+	this->{{.NameNative}}.assign(c.{{.NameNative}}.utf8, c.{{.NameNative}}.utf8 + c.{{.NameNative}}.len);
+ {{- else if eq .Type "binary"}}
+	this->{{.NameNative}}.assign(c.{{.NameNative}}.octets, c.{{.NameNative}}.octets + c.{{.NameNative}}.len);
+ {{- else if .TypeRef}}
+	if (c.{{.NameNative}}) {
+		this->{{.NameNative}} = std::make_unique<{{cppQualified .TypeRef}}>();
+		this->{{.NameNative}}->from_c(*c.{{.NameNative}});
+	} else {
+		this->{{.NameNative}}.reset();
+	}
+ {{- else}}
+	this->{{.NameNative}} = c.{{.NameNative}};
+ {{- end}}
+{{- end}}
+}
+{{end}}
+} // namespace {{cppNamespace .}}
+`
@@ -0,0 +1,43 @@
+package colfer
+
+import "github.com/pascaldekloe/colfer/descriptor"
+
+// Descriptor returns the model in the Colfer format of package descriptor.
+// Language specific properties, i.e., the natives, are not included.
+func (p Packages) Descriptor() *descriptor.Schema {
+	schema := &descriptor.Schema{Packages: make([]*descriptor.Pkg, len(p))}
+	for i, pkg := range p {
+		dp := &descriptor.Pkg{
+			Name:        pkg.Name,
+			Docs:        pkg.Docs,
+			Structs:     make([]*descriptor.Structure, len(pkg.Structs)),
+			SchemaFiles: pkg.SchemaFiles,
+		}
+		schema.Packages[i] = dp
+
+		for j, t := range pkg.Structs {
+			dt := &descriptor.Structure{
+				Name:       t.Name,
+				Docs:       t.Docs,
+				Fields:     make([]*descriptor.Field, len(t.Fields)),
+				SchemaFile: t.SchemaFile,
+			}
+			dp.Structs[j] = dt
+
+			for k, f := range t.Fields {
+				df := &descriptor.Field{
+					Index:    uint8(f.Index),
+					Name:     f.Name,
+					Docs:     f.Docs,
+					Datatype: f.Type,
+					List:     f.TypeList,
+				}
+				if f.TypeRef != nil {
+					df.TypeRef = f.TypeRef.String()
+				}
+				dt.Fields[k] = df
+			}
+		}
+	}
+	return schema
+}
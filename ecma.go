@@ -1,7 +1,9 @@
 package colfer
 
 import (
-	"os"
+	"bytes"
+	_ "embed"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"text/template"
@@ -23,8 +25,104 @@ var eCMAKeywords = map[string]struct{}{
 	"with": {}, "yield": {},
 }
 
-// GenerateECMA writes the code into file "Colfer.js".
+// ECMAModule is the Package option for ES module output. Each package goes
+// into its own file, named after the package with a ".js" extension, with an
+// import for each package referenced.
+const ECMAModule = "module"
+
+// ECMABigInt is the Package option for BigInt values on uint64 and int64
+// fields, which covers their full range.
+const ECMABigInt = "bigint"
+
+// GenerateECMA writes the code into file "Colfer.js", and it writes an RPC
+// client into file "ColferRPC.js". Packages with option ECMAModule go into
+// their own file instead. The RPC client is an ES module too when all packages
+// have option ECMAModule. References between packages with and without option
+// ECMAModule are not supported.
 func GenerateECMA(basedir string, packages Packages) error {
+	files, err := GenerateECMAFiles(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// GenerateECMAFiles returns the code of GenerateECMA.
+func GenerateECMAFiles(packages Packages) (Files, error) {
+	ecmaNames(packages)
+
+	t := template.New("ecma-code")
+	template.Must(t.Parse(ecmaCode))
+	template.Must(t.New("package").Parse(ecmaPackage))
+	template.Must(t.New("fields").Parse(ecmaFields))
+	template.Must(t.New("marshal-doc").Parse(ecmaMarshalDoc))
+	template.Must(t.New("marshal").Parse(ecmaMarshal))
+	template.Must(t.New("unmarshal").Parse(ecmaUnmarshal))
+	template.Must(t.New("helpers").Parse(ecmaHelpers))
+
+	m := template.New("ecma-module")
+	template.Must(m.Parse(ecmaModule))
+	template.Must(m.New("fields").Parse(ecmaFields))
+	template.Must(m.New("marshal-doc").Parse(ecmaMarshalDoc))
+	template.Must(m.New("marshal").Parse(ecmaMarshal))
+	template.Must(m.New("unmarshal").Parse(ecmaUnmarshal))
+	template.Must(m.New("helpers").Parse(strings.ReplaceAll(ecmaHelpers, "\n\t", "\n")))
+
+	var scripts Packages
+	for _, p := range packages {
+		for _, ref := range p.Refs() {
+			if ref.HasOption(ECMAModule) != p.HasOption(ECMAModule) {
+				return nil, fmt.Errorf("colfer: package %s references package %s, and only one of them has option %q", p.Name, ref.Name, ECMAModule)
+			}
+		}
+		if !p.HasOption(ECMAModule) {
+			scripts = append(scripts, p)
+		}
+	}
+
+	rpc, err := ecmaRPCCode(t, len(packages) != 0 && len(scripts) == 0)
+	if err != nil {
+		return nil, err
+	}
+	files := Files{"ColferRPC.js": rpc}
+	for _, p := range packages {
+		if !p.HasOption(ECMAModule) {
+			continue
+		}
+
+		file := p.Name + ".js"
+		data := ecmaModuleData{Package: p}
+		for _, ref := range p.Refs() {
+			rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(file)), filepath.FromSlash(ref.Name+".js"))
+			if err != nil {
+				return nil, err
+			}
+			rel = filepath.ToSlash(rel)
+			if !strings.HasPrefix(rel, "../") {
+				rel = "./" + rel
+			}
+			data.Imports = append(data.Imports, ecmaImport{Name: ref.NameNative, Path: rel})
+		}
+
+		var buf bytes.Buffer
+		if err := m.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		files[file] = buf.Bytes()
+	}
+
+	if len(scripts) != 0 {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, scripts); err != nil {
+			return nil, err
+		}
+		files["Colfer.js"] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// EcmaNames sets the native names of packages, structs and fields.
+func ecmaNames(packages Packages) {
 	for _, p := range packages {
 		p.NameNative = strings.Replace(p.Name, "/", "_", -1)
 		if _, ok := eCMAKeywords[p.NameNative]; ok {
@@ -42,20 +140,78 @@ func GenerateECMA(basedir string, packages Packages) error {
 		}
 	}
 
-	t := template.New("ecma-code")
-	template.Must(t.Parse(ecmaCode))
-	template.Must(t.New("marshal").Parse(ecmaMarshal))
-	template.Must(t.New("unmarshal").Parse(ecmaUnmarshal))
+	for _, p := range packages {
+		for _, t := range p.Structs {
+			for _, f := range t.Fields {
+				if f.TypeRef == nil {
+					continue
+				}
+				f.TypeNative = f.TypeRef.Pkg.NameNative + "." + f.TypeRef.NameNative
+				if f.TypeRef.Pkg == p && p.HasOption(ECMAModule) {
+					f.TypeNative = f.TypeRef.NameNative
+				}
+			}
+		}
+	}
+}
 
-	if err := os.MkdirAll(basedir, os.ModeDir|os.ModePerm); err != nil {
-		return err
+// EcmaRPCSchema has the header of the RPC protocol.
+//
+//go:embed rpc/internal.colf
+var ecmaRPCSchema string
+
+// EcmaRPCCode returns the RPC client, with the header code from the package
+// template in t. The client exports as an ES module when module is set.
+func ecmaRPCCode(t *template.Template, module bool) ([]byte, error) {
+	packages, err := ParseReader("internal.colf", strings.NewReader(ecmaRPCSchema))
+	if err != nil {
+		return nil, err
+	}
+	// limits conform the Go code in rpc/internal
+	for _, p := range packages {
+		p.SizeMax = "16 * 1024 * 1024"
+		p.ListMax = "64 * 1024"
+	}
+	ecmaNames(packages)
+	header := packages[0]
+	// no collision with packages from Colfer.js
+	header.NameNative = "colferRPCInternal"
+	for _, t := range header.Structs {
+		for _, f := range t.Fields {
+			if f.TypeRef != nil {
+				f.TypeNative = header.NameNative + "." + f.TypeRef.NameNative
+			}
+		}
 	}
-	f, err := os.Create(filepath.Join(basedir, "Colfer.js"))
+
+	rpc, err := t.Clone()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	template.Must(rpc.New("rpc").Parse(ecmaRPC))
+	var buf bytes.Buffer
+	if err := rpc.ExecuteTemplate(&buf, "rpc", ecmaRPCData{Package: header, Module: module}); err != nil {
+		return nil, err
 	}
-	defer f.Close()
-	return t.Execute(f, packages)
+	return buf.Bytes(), nil
+}
+
+// EcmaRPCData is the template input of the RPC client.
+type ecmaRPCData struct {
+	*Package      // header
+	Module   bool // ES module output
+}
+
+// EcmaModuleData is the template input of an ES module.
+type ecmaModuleData struct {
+	*Package
+	Imports []ecmaImport
+}
+
+// EcmaImport is a namespace import of an ES module.
+type ecmaImport struct {
+	Name string // identifier
+	Path string // module specifier
 }
 
 const ecmaCode = `/* eslint-disable no-redeclare */
@@ -64,30 +220,121 @@ const ecmaCode = `/* eslint-disable no-redeclare */
 // The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
 {{- end}}
 {{range .}}
-{{.DocText "// "}}
-class {{.Name}} {
-	EOF = 'colfer: EOF';
+{{template "package" .}}
+
+// NodeJS:
+if (typeof exports !== 'undefined') exports.{{.NameNative}} = {{.NameNative}};
+{{end}}`
+
+// EcmaPackage is the namespace object of a package.
+const ecmaPackage = `{{.DocText "// "}}
+var {{.NameNative}} = new function() {
+	const EOF = 'colfer: EOF';
 
 	// The upper limit for serial byte sizes.
-	colferSizeMax = {{.SizeMax}};
+	var colferSizeMax = {{.SizeMax}};
 {{- if .HasList}}
 	// The upper limit for the number of elements in a list.
-	colferListMax = {{.ListMax}};
+	var colferListMax = {{.ListMax}};
 {{- end}}
 {{range .Structs}}
 	// Constructor.
 {{.DocText "\t// "}}
 	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
-{{.NameNative}} = (init) => {
-		return {
-			{{template "marshal" .}}
-			{{template "unmarshal" .}}
-		};
+	this.{{.NameNative}} = function(init) {
+{{- template "fields" .}}
+
+		for (var p in init) this[p] = init[p];
+	}
+{{template "marshal-doc" .}}
+	this.{{.NameNative}}.prototype.marshal = function(buf) {
+{{template "marshal" .}}
+	}
+
+	// Deserializes the object from an Uint8Array and returns the number of bytes read.
+	this.{{.NameNative}}.prototype.unmarshal = function(data) {
+{{template "unmarshal" .}}
 	}
 {{end}}
 	// private section
+{{template "helpers" .}}
+}`
+
+const ecmaModule = `/* eslint-disable no-redeclare */
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
+{{range .Imports}}
+import * as {{.Name}} from '{{.Path}}';
+{{- end}}
+{{if .Docs}}
+{{.DocText "// "}}
+{{- end}}
+
+const EOF = 'colfer: EOF';
+
+// The upper limit for serial byte sizes.
+const colferSizeMax = {{.SizeMax}};
+{{- if .HasList}}
+// The upper limit for the number of elements in a list.
+const colferListMax = {{.ListMax}};
+{{- end}}
+{{range .Structs}}
+{{if .Docs}}{{.DocText "// "}}
+{{end}}export class {{.NameNative}} {
+
+	// Constructor.
+	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
+	constructor(init) {
+{{- template "fields" .}}
+
+		for (var p in init) this[p] = init[p];
+	}
+{{template "marshal-doc" .}}
+	marshal(buf) {
+{{template "marshal" .}}
+	}
+
+	// Deserializes the object from an Uint8Array and returns the number of bytes read.
+	unmarshal(data) {
+{{template "unmarshal" .}}
+	}
+}
+{{end}}
+// private section
+{{template "helpers" .}}`
+
+// EcmaFields is the property initiation of a constructor.
+const ecmaFields = `{{range .Fields}}
+{{- if .Docs}}
+{{.DocText "\t\t// "}}
+{{- end}}
+		this.{{.NameNative}} =
+{{- if .TypeList}}
+ {{- if eq .Type "float32"}} new Float32Array(0);
+ {{- else if eq .Type "float64"}} new Float64Array(0);
+ {{- else}} [];
+ {{- end}}
+{{- else if eq .Type "bool"}} false;
+{{- else if eq .Type "timestamp"}} null;
+		this.{{.NameNative}}_ns = 0;
+{{- else if eq .Type "text"}} '';
+{{- else if eq .Type "binary"}} new Uint8Array(0);
+{{- else if .TypeRef}} null;
+{{- else if and (.Struct.Pkg.HasOption "bigint") (eq .Type "uint64" "int64")}} 0n;
+{{- else}} 0;
+{{- end}}
+{{- end}}`
+
+// EcmaMarshalDoc is the documentation of the marshal method.
+const ecmaMarshalDoc = `
+	// Serializes the object into an Uint8Array.
+{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+	// All null entries in property {{.NameNative}} will be replaced with {{if eq .Type "text"}}an empty String{{else if eq .Type "binary"}}an empty Array{{else}}a new {{.TypeNative}}{{end}}.
+{{- end}}{{end}}{{end}}`
 
-	encodeVarint (bytes, i, x) {
+// EcmaHelpers are the private functions of a package.
+const ecmaHelpers = `
+	function encodeVarint(bytes, i, x) {
 		while (x > 127) {
 			bytes[i++] = (x & 127) | 128;
 			x /= 128;
@@ -95,8 +342,17 @@ class {{.Name}} {
 		bytes[i++] = x & 127;
 		return i;
 	}
-{{if .HasTimestamp}}
-	decodeInt64(data, i) {
+{{if .HasOption "bigint"}}
+	function encodeBigVarint(bytes, i, x) {
+		for (var n = 0; n < 8 && x > 127n; n++) {
+			bytes[i++] = Number(x & 127n) | 128;
+			x >>= 7n;
+		}
+		bytes[i++] = Number(x & 255n);
+		return i;
+	}
+{{end}}{{if .HasTimestamp}}
+	function decodeInt64(data, i) {
 		var v = 0, j = i + 7, m = 1;
 		if (data[i] & 128) {
 			// two's complement
@@ -113,7 +369,7 @@ class {{.Name}} {
 		return v;
 	}
 {{end}}
-	encodeUTF8(s) {
+	function encodeUTF8(s) {
 		var i = 0, bytes = new Uint8Array(s.length * 4);
 		for (var ci = 0; ci != s.length; ci++) {
 			var c = s.charCodeAt(ci);
@@ -146,7 +402,7 @@ class {{.Name}} {
 		return bytes.subarray(0, i);
 	}
 
-	decodeUTF8(bytes) {
+	function decodeUTF8(bytes) {
 		var i = 0, s = '';
 		while (i < bytes.length) {
 			var c = bytes[i++];
@@ -169,112 +425,130 @@ class {{.Name}} {
 			}
 		}
 		return s;
-	}
-}
-
-// NodeJS:
-if (typeof exports !== 'undefined') exports.{{.NameNative}} = new {{.Name}}();
-{{end}}`
+	}`
 
-const ecmaMarshal = `
-	// Serializes the object into an Uint8Array.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
-	// All null entries in property {{.NameNative}} will be replaced with {{if eq .Type "text"}}an empty String{{else if eq .Type "binary"}}an empty Array{{else}}a new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameNative}}{{end}}.
-{{- end}}{{end}}{{end}}
-	marshal: (buf) => {
-		if (! buf || !buf.length) buf = new Uint8Array(this.colferSizeMax);
+// EcmaMarshal is the body of the marshal method.
+const ecmaMarshal = `{{$bigint := .Pkg.HasOption "bigint"}}		if (! buf || !buf.length) buf = new Uint8Array(colferSizeMax);
 		var i = 0;
 		var view = new DataView(buf.buffer);
 
 {{range .Fields}}{{if eq .Type "bool"}}
-		if (init.{{.NameNative}})
+		if (this.{{.NameNative}})
 			buf[i++] = {{.Index}};
 {{else if eq .Type "uint8"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} > 255 || init.{{.NameNative}} < 0)
-				throw new Error('colfer: {{.String}} out of reach: ' + init.{{.NameNative}});
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} > 255 || this.{{.NameNative}} < 0)
+				throw new Error('colfer: {{.String}} out of reach: ' + this.{{.NameNative}});
 			buf[i++] = {{.Index}};
-			buf[i++] = init.{{.NameNative}};
+			buf[i++] = this.{{.NameNative}};
 		}
 {{else if eq .Type "uint16"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} > 65535 || init.{{.NameNative}} < 0)
-				throw new Error('colfer: {{.String}} out of reach: ' + init.{{.NameNative}});
-			if (init.{{.NameNative}} < 256) {
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} > 65535 || this.{{.NameNative}} < 0)
+				throw new Error('colfer: {{.String}} out of reach: ' + this.{{.NameNative}});
+			if (this.{{.NameNative}} < 256) {
 				buf[i++] = {{.Index}} | 128;
-				buf[i++] = init.{{.NameNative}};
+				buf[i++] = this.{{.NameNative}};
 			} else {
 				buf[i++] = {{.Index}};
-				buf[i++] = init.{{.NameNative}} >>> 0;
-				buf[i++] = init.{{.NameNative}} & 255;
+				buf[i++] = this.{{.NameNative}} >>> 0;
+				buf[i++] = this.{{.NameNative}} & 255;
 			}
 		}
 {{else if eq .Type "uint32"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} > 4294967295 || init.{{.NameNative}} < 0)
-				throw new Error('colfer: {{.String}} out of reach: ' + init.{{.NameNative}});
-			if (init.{{.NameNative}} < 0x200000) {
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} > 4294967295 || this.{{.NameNative}} < 0)
+				throw new Error('colfer: {{.String}} out of reach: ' + this.{{.NameNative}});
+			if (this.{{.NameNative}} < 0x200000) {
 				buf[i++] = {{.Index}};
-				i = this.encodeVarint(buf, i, init.{{.NameNative}});
+				i = encodeVarint(buf, i, this.{{.NameNative}});
 			} else {
 				buf[i++] = {{.Index}} | 128;
-				view.setUint32(i, init.{{.NameNative}});
+				view.setUint32(i, this.{{.NameNative}});
 				i += 4;
 			}
 		}
+{{else if and $bigint (eq .Type "uint64")}}
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} < 0n || this.{{.NameNative}} > 0xffffffffffffffffn)
+				throw new Error('colfer: {{.String}} out of reach: ' + this.{{.NameNative}});
+			if (this.{{.NameNative}} < 0x2000000000000n) {
+				buf[i++] = {{.Index}};
+				i = encodeBigVarint(buf, i, this.{{.NameNative}});
+			} else {
+				buf[i++] = {{.Index}} | 128;
+				view.setBigUint64(i, this.{{.NameNative}});
+				i += 8;
+			}
+		}
 {{else if eq .Type "uint64"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} < 0)
-				throw new Error('colfer: {{.String}} out of reach: ' + init.{{.NameNative}});
-			if (init.{{.NameNative}} > Number.MAX_SAFE_INTEGER)
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} < 0)
+				throw new Error('colfer: {{.String}} out of reach: ' + this.{{.NameNative}});
+			if (this.{{.NameNative}} > Number.MAX_SAFE_INTEGER)
 				throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			if (init.{{.NameNative}} < 0x2000000000000) {
+			if (this.{{.NameNative}} < 0x2000000000000) {
 				buf[i++] = {{.Index}};
-				i = this.encodeVarint(buf, i, init.{{.NameNative}});
+				i = encodeVarint(buf, i, this.{{.NameNative}});
 			} else {
 				buf[i++] = {{.Index}} | 128;
-				view.setUint32(i, init.{{.NameNative}} / 0x100000000);
+				view.setUint32(i, this.{{.NameNative}} / 0x100000000);
 				i += 4;
-				view.setUint32(i, init.{{.NameNative}} % 0x100000000);
+				view.setUint32(i, this.{{.NameNative}} % 0x100000000);
 				i += 4;
 			}
 		}
 {{else if eq .Type "int32"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} < 0) {
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} < 0) {
 				buf[i++] = {{.Index}} | 128;
-				if (init.{{.NameNative}} < -2147483648)
+				if (this.{{.NameNative}} < -2147483648)
 					throw new Error('colfer: {{.String}} exceeds 32-bit range');
-				i = this.encodeVarint(buf, i, -init.{{.NameNative}});
+				i = encodeVarint(buf, i, -this.{{.NameNative}});
 			} else {
 				buf[i++] = {{.Index}}; 
-				if (init.{{.NameNative}} > 2147483647)
+				if (this.{{.NameNative}} > 2147483647)
 					throw new Error('colfer: {{.String}} exceeds 32-bit range');
-				i = this.encodeVarint(buf, i, init.{{.NameNative}});
+				i = encodeVarint(buf, i, this.{{.NameNative}});
+			}
+		}
+{{else if and $bigint (eq .Type "int64")}}
+		if (this.{{.NameNative}}) {
+			var x = this.{{.NameNative}};
+			if (x < 0n) {
+				buf[i++] = {{.Index}} | 128;
+				if (x < -0x8000000000000000n)
+					throw new Error('colfer: {{.String}} exceeds 64-bit range');
+				x = -x;
+			} else {
+				buf[i++] = {{.Index}};
+				if (x > 0x7fffffffffffffffn)
+					throw new Error('colfer: {{.String}} exceeds 64-bit range');
 			}
+			i = encodeBigVarint(buf, i, x);
 		}
 {{else if eq .Type "int64"}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} < 0) {
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} < 0) {
 				buf[i++] = {{.Index}} | 128;
-				if (init.{{.NameNative}} < Number.MIN_SAFE_INTEGER)
+				if (this.{{.NameNative}} < Number.MIN_SAFE_INTEGER)
 					throw new Error('colfer: {{.String}} exceeds Number.MIN_SAFE_INTEGER');
-				i = this.encodeVarint(buf, i, -init.{{.NameNative}});
+				i = encodeVarint(buf, i, -this.{{.NameNative}});
 			} else {
 				buf[i++] = {{.Index}}; 
-				if (init.{{.NameNative}} > Number.MAX_SAFE_INTEGER)
+				if (this.{{.NameNative}} > Number.MAX_SAFE_INTEGER)
 					throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-				i = this.encodeVarint(buf, i, init.{{.NameNative}});
+				i = encodeVarint(buf, i, this.{{.NameNative}});
 			}
 		}
 {{else if eq .Type "float32"}}
  {{- if .TypeList}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
-			var a = init.{{.NameNative}};
-			if (a.length > this.colferListMax)
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > colferListMax)
 				throw new Error('colfer: {{.String}} exceeds colferListMax');
 			buf[i++] = {{.Index}};
-			i = this.encodeVarint(buf, i, a.length);
+			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(f, fi) {
 				if (f > 3.4028234663852886E38 || f < -3.4028234663852886E38)
 					throw new Error('colfer: {{.String}}[' + fi + '] exceeds 32-bit range');
@@ -283,46 +557,46 @@ const ecmaMarshal = `
 			});
 		}
  {{- else}}
-		if (init.{{.NameNative}}) {
-			if (init.{{.NameNative}} > 3.4028234663852886E38 || init.{{.NameNative}} < -3.4028234663852886E38)
+		if (this.{{.NameNative}}) {
+			if (this.{{.NameNative}} > 3.4028234663852886E38 || this.{{.NameNative}} < -3.4028234663852886E38)
 				throw new Error('colfer: {{.String}} exceeds 32-bit range');
 			buf[i++] = {{.Index}};
-			view.setFloat32(i, init.{{.NameNative}});
+			view.setFloat32(i, this.{{.NameNative}});
 			i += 4;
-		} else if (Number.isNaN(init.{{.NameNative}})) {
+		} else if (Number.isNaN(this.{{.NameNative}})) {
 			buf.set([{{.Index}}, 0x7f, 0xc0, 0, 0], i);
 			i += 5;
 		}
  {{- end}}
 {{else if eq .Type "float64"}}
  {{- if .TypeList}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
-			var a = init.{{.NameNative}};
-			if (a.length > this.colferListMax)
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > colferListMax)
 				throw new Error('colfer: {{.String}} exceeds colferListMax');
 			buf[i++] = {{.Index}};
-			i = this.encodeVarint(buf, i, a.length);
+			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(f) {
 				view.setFloat64(i, f);
 				i += 8;
 			});
 		}
  {{- else}}
-		if (init.{{.NameNative}}) {
+		if (this.{{.NameNative}}) {
 			buf[i++] = {{.Index}};
-			view.setFloat64(i, init.{{.NameNative}});
+			view.setFloat64(i, this.{{.NameNative}});
 			i += 8;
-		} else if (Number.isNaN(init.{{.NameNative}})) {
+		} else if (Number.isNaN(this.{{.NameNative}})) {
 			buf.set([{{.Index}}, 0x7f, 0xf8, 0, 0, 0, 0, 0, 0], i);
 			i += 9;
 		}
  {{- end}}
 {{else if eq .Type "timestamp"}}
-		if ((init.{{.NameNative}} && init.{{.NameNative}}.getTime()) || init.{{.NameNative}}_ns) {
-			var ms = init.{{.NameNative}} ? init.{{.NameNative}}.getTime() : 0;
+		if ((this.{{.NameNative}} && this.{{.NameNative}}.getTime()) || this.{{.NameNative}}_ns) {
+			var ms = this.{{.NameNative}} ? this.{{.NameNative}}.getTime() : 0;
 			var s = ms / 1E3;
 
-			var ns = init.{{.NameNative}}_ns || 0;
+			var ns = this.{{.NameNative}}_ns || 0;
 			if (ns < 0 || ns >= 1E6)
 				throw new Error('colfer: {{.String}} ns not in range (0, 1ms>');
 			var msf = ms % 1E3;
@@ -360,70 +634,70 @@ const ecmaMarshal = `
 		}
 {{else if eq .Type "text"}}
  {{- if .TypeList}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
-			var a = init.{{.NameNative}};
-			if (a.length > this.colferListMax)
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > colferListMax)
 				throw new Error('colfer: {{.String}} exceeds colferListMax');
 			buf[i++] = {{.Index}};
-			i = this.encodeVarint(buf, i, a.length);
+			i = encodeVarint(buf, i, a.length);
 
-			a.forEach((s, si) => {
+			a.forEach(function(s, si) {
 				if (s == null) {
 					s = "";
 					a[si] = s;
 				}
-				var utf8 = this.encodeUTF8(s);
-				i = this.encodeVarint(buf, i, utf8.length);
+				var utf8 = encodeUTF8(s);
+				i = encodeVarint(buf, i, utf8.length);
 				buf.set(utf8, i);
 				i += utf8.length;
 			});
 		}
  {{- else}}
-		if (init.{{.NameNative}}) {
+		if (this.{{.NameNative}}) {
 			buf[i++] = {{.Index}};
-			var utf8 = this.encodeUTF8(init.{{.NameNative}});
-			i = this.encodeVarint(buf, i, utf8.length);
+			var utf8 = encodeUTF8(this.{{.NameNative}});
+			i = encodeVarint(buf, i, utf8.length);
 			buf.set(utf8, i);
 			i += utf8.length;
 		}
  {{- end}}
 {{else if eq .Type "binary"}}
  {{- if .TypeList}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
-			var a = init.{{.NameNative}};
-			if (a.length > this.colferListMax)
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > colferListMax)
 				throw new Error('colfer: {{.String}} exceeds colferListMax');
 			buf[i++] = {{.Index}};
-			i = this.encodeVarint(buf, i, a.length);
-			a.forEach((b, bi) => {
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(b, bi) {
 				if (b == null) {
 					b = "";
 					a[bi] = b;
 				}
-				i = this.encodeVarint(buf, i, b.length);
+				i = encodeVarint(buf, i, b.length);
 				buf.set(b, i);
 				i += b.length;
 			});
 		}
  {{- else}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			buf[i++] = {{.Index}};
-			var b = init.{{.NameNative}};
-			i = this.encodeVarint(buf, i, b.length);
+			var b = this.{{.NameNative}};
+			i = encodeVarint(buf, i, b.length);
 			buf.set(b, i);
 			i += b.length;
 		}
  {{- end}}
 {{else if .TypeList}}
-		if (init.{{.NameNative}} && init.{{.NameNative}}.length) {
-			var a = init.{{.NameNative}};
-			if (a.length > this.colferListMax)
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > colferListMax)
 				throw new Error('colfer: {{.String}} exceeds colferListMax');
 			buf[i++] = {{.Index}};
-			i = this.encodeVarint(buf, i, a.length);
+			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(v, vi) {
 				if (v == null) {
-					v = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameNative}}();
+					v = new {{.TypeNative}}();
 					a[vi] = v;
 				}
 				var b = v.marshal();
@@ -432,28 +706,25 @@ const ecmaMarshal = `
 			});
 		}
 {{else}}
-		if (init.{{.NameNative}}) {
+		if (this.{{.NameNative}}) {
 			buf[i++] = {{.Index}};
-			var b = init.{{.NameNative}}.marshal();
+			var b = this.{{.NameNative}}.marshal();
 			buf.set(b, i);
 			i += b.length;
 		}
 {{end}}{{end}}
 
 		buf[i++] = 127;
-		if (i >= this.colferSizeMax)
-			throw new Error('colfer: {{.String}} serial size ' + i + ' exceeds ' + this.colferSizeMax + ' bytes');
-		return buf.subarray(0, i);
-	},`
+		if (i >= colferSizeMax)
+			throw new Error('colfer: {{.String}} serial size ' + i + ' exceeds ' + colferSizeMax + ' bytes');
+		return buf.subarray(0, i);`
 
-const ecmaUnmarshal = `
-	// Deserializes the object from an Uint8Array and returns the number of bytes read.
-	unmarshal: (data) => {
-		if (!data || ! data.length) throw new Error(this.EOF);
+// EcmaUnmarshal is the body of the unmarshal method.
+const ecmaUnmarshal = `{{$bigint := .Pkg.HasOption "bigint"}}		if (!data || ! data.length) throw new Error(EOF);
 		var header = data[0];
 		var i = 1;
 		var readHeader = function() {
-			if (i >= data.length) throw new Error(this.EOF);
+			if (i >= data.length) throw new Error(EOF);
 			header = data[i++];
 		}
 
@@ -470,56 +741,81 @@ const ecmaUnmarshal = `
 					if (result > Number.MAX_SAFE_INTEGER) break;
 					return result;
 				}
-				if (pos == data.length) throw new Error(this.EOF);
+				if (pos == data.length) throw new Error(EOF);
 			}
 			return -1;
 		}
+{{- if $bigint}}
+
+		var readBigVarint = function() {
+			var result = 0n;
+			for (var shift = 0n; true; shift += 7n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				if (shift == 56n || c < 128) {
+					result |= BigInt(c) << shift;
+					return result;
+				}
+				result |= BigInt(c & 127) << shift;
+			}
+		}
+{{- end}}
 {{range .Fields}}{{if eq .Type "bool"}}
 		if (header == {{.Index}}) {
-			init.{{.NameNative}} = true;
+			this.{{.NameNative}} = true;
 			readHeader();
 		}
 {{else if eq .Type "uint8"}}
 		if (header == {{.Index}}) {
-			if (i + 1 >= data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = data[i++];
+			if (i + 1 >= data.length) throw new Error(EOF);
+			this.{{.NameNative}} = data[i++];
 			header = data[i++];
 		}
 {{else if eq .Type "uint16"}}
 		if (header == {{.Index}}) {
-			if (i + 2 >= data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = (data[i++] << 8) | data[i++];
+			if (i + 2 >= data.length) throw new Error(EOF);
+			this.{{.NameNative}} = (data[i++] << 8) | data[i++];
 			header = data[i++];
 		} else if (header == ({{.Index}} | 128)) {
-			if (i + 1 >= data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = data[i++];
+			if (i + 1 >= data.length) throw new Error(EOF);
+			this.{{.NameNative}} = data[i++];
 			header = data[i++];
 		}
 {{else if eq .Type "uint32"}}
 		if (header == {{.Index}}) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = x;
+			this.{{.NameNative}} = x;
 			readHeader();
 		} else if (header == ({{.Index}} | 128)) {
-			if (i + 4 > data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = view.getUint32(i);
+			if (i + 4 > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = view.getUint32(i);
 			i += 4;
 			readHeader();
 		}
+{{else if and $bigint (eq .Type "uint64")}}
+		if (header == {{.Index}}) {
+			this.{{.NameNative}} = readBigVarint();
+			readHeader();
+		} else if (header == ({{.Index}} | 128)) {
+			if (i + 8 > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = view.getBigUint64(i);
+			i += 8;
+			readHeader();
+		}
 {{else if eq .Type "uint64"}}
 		if (header == {{.Index}}) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = x;
+			this.{{.NameNative}} = x;
 			readHeader();
 		} else if (header == ({{.Index}} | 128)) {
-			if (i + 8 > data.length) throw new Error(this.EOF);
+			if (i + 8 > data.length) throw new Error(EOF);
 			var x = view.getUint32(i) * 0x100000000;
 			x += view.getUint32(i + 4);
 			if (x > Number.MAX_SAFE_INTEGER)
 				throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = x;
+			this.{{.NameNative}} = x;
 			i += 8;
 			readHeader();
 		}
@@ -527,24 +823,32 @@ const ecmaUnmarshal = `
 		if (header == {{.Index}}) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = x;
+			this.{{.NameNative}} = x;
 			readHeader();
 		} else if (header == ({{.Index}} | 128)) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = -1 * x;
+			this.{{.NameNative}} = -1 * x;
+			readHeader();
+		}
+{{else if and $bigint (eq .Type "int64")}}
+		if (header == {{.Index}}) {
+			this.{{.NameNative}} = BigInt.asIntN(64, readBigVarint());
+			readHeader();
+		} else if (header == ({{.Index}} | 128)) {
+			this.{{.NameNative}} = BigInt.asIntN(64, -readBigVarint());
 			readHeader();
 		}
 {{else if eq .Type "int64"}}
 		if (header == {{.Index}}) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = x;
+			this.{{.NameNative}} = x;
 			readHeader();
 		} else if (header == ({{.Index}} | 128)) {
 			var x = readVarint();
 			if (x < 0) throw new Error('colfer: {{.String}} exceeds Number.MAX_SAFE_INTEGER');
-			init.{{.NameNative}} = -1 * x;
+			this.{{.NameNative}} = -1 * x;
 			readHeader();
 		}
 {{else if eq .Type "float32"}}
@@ -552,18 +856,18 @@ const ecmaUnmarshal = `
  {{- if .TypeList}}
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > this.colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + this.colferListMax + ' elements');
-			if (i + l * 4 > data.length) throw new Error(this.EOF);
+			if (l > colferListMax)
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 4 > data.length) throw new Error(EOF);
 
-			init.{{.NameNative}} = new Float32Array(l);
+			this.{{.NameNative}} = new Float32Array(l);
 			for (var n = 0; n < l; ++n) {
-				init.{{.NameNative}}[n] = view.getFloat32(i);
+				this.{{.NameNative}}[n] = view.getFloat32(i);
 				i += 4;
 			}
  {{- else}}
-			if (i + 4 > data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = view.getFloat32(i);
+			if (i + 4 > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = view.getFloat32(i);
 			i += 4;
  {{- end}}
 			readHeader();
@@ -572,44 +876,44 @@ const ecmaUnmarshal = `
 		if (header == {{.Index}}) {
  {{- if .TypeList}}
 			var l = readVarint();
-			if (l < 0 || l > this.colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + this.colferListMax + ' elements');
-			if (i + l * 8 > data.length) throw new Error(this.EOF);
+			if (l < 0 || l > colferListMax)
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 8 > data.length) throw new Error(EOF);
 
-			init.{{.NameNative}} = new Float64Array(l);
+			this.{{.NameNative}} = new Float64Array(l);
 			for (var n = 0; n < l; ++n) {
-				init.{{.NameNative}}[n] = view.getFloat64(i);
+				this.{{.NameNative}}[n] = view.getFloat64(i);
 				i += 8;
 			}
  {{- else}}
-			if (i + 8 > data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = view.getFloat64(i);
+			if (i + 8 > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = view.getFloat64(i);
 			i += 8;
  {{- end}}
 			readHeader();
 		}
 {{else if eq .Type "timestamp"}}
 		if (header == {{.Index}}) {
-			if (i + 8 > data.length) throw new Error(this.EOF);
+			if (i + 8 > data.length) throw new Error(EOF);
 
 			var ms = view.getUint32(i) * 1E3;
 			var ns = view.getUint32(i + 4);
 			ms += Math.floor(ns / 1E6);
-			init.{{.NameNative}} = new Date(ms);
-			init.{{.NameNative}}_ns = ns % 1E6;
+			this.{{.NameNative}} = new Date(ms);
+			this.{{.NameNative}}_ns = ns % 1E6;
 
 			i += 8;
 			readHeader();
 		} else if (header == ({{.Index}} | 128)) {
-			if (i + 12 > data.length) throw new Error(this.EOF);
+			if (i + 12 > data.length) throw new Error(EOF);
 
 			var ms = decodeInt64(data, i) * 1E3;
 			var ns = view.getUint32(i + 8);
 			ms += Math.floor(ns / 1E6);
 			if (ms < -864E13 || ms > 864E13)
 				throw new Error('colfer: {{.String}} exceeds ECMA Date range');
-			init.{{.NameNative}} = new Date(ms);
-			init.{{.NameNative}}_ns = ns % 1E6;
+			this.{{.NameNative}} = new Date(ms);
+			this.{{.NameNative}}_ns = ns % 1E6;
 
 			i += 12;
 			readHeader();
@@ -618,29 +922,29 @@ const ecmaUnmarshal = `
 		if (header == {{.Index}}) {
  {{- if .TypeList}}
 			var l = readVarint();
-			if (l < 0 || l > this.colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + this.colferListMax + ' elements');
+			if (l < 0 || l > colferListMax)
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
 
-			init.{{.NameNative}} = new Array(l);
+			this.{{.NameNative}} = new Array(l);
 			for (var n = 0; n < l; ++n) {
 				var size = readVarint();
-				if (size < 0 || size > this.colferSizeMax)
-					throw new Error('colfer: {{.String}}[' + init.{{.NameNative}}.length + '] size ' + size + ' exceeds ' + this.colferSizeMax + ' bytes');
+				if (size < 0 || size > colferSizeMax)
+					throw new Error('colfer: {{.String}}[' + this.{{.NameNative}}.length + '] size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
 
 				var start = i;
 				i += size;
-				if (i > data.length) throw new Error(this.EOF);
-				init.{{.NameNative}}[n] = this.decodeUTF8(data.subarray(start, i));
+				if (i > data.length) throw new Error(EOF);
+				this.{{.NameNative}}[n] = decodeUTF8(data.subarray(start, i));
 			}
  {{- else}}
 			var size = readVarint();
-			if (size < 0 || size > this.colferSizeMax)
-				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + this.colferSizeMax + ' bytes');
+			if (size < 0 || size > colferSizeMax)
+				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
 
 			var start = i;
 			i += size;
-			if (i > data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = this.decodeUTF8(data.subarray(start, i));
+			if (i > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = decodeUTF8(data.subarray(start, i));
  {{- end}}
 			readHeader();
 		}
@@ -648,55 +952,176 @@ const ecmaUnmarshal = `
 		if (header == {{.Index}}) {
  {{- if .TypeList}}
 			var l = readVarint();
-			if (l < 0 || l > this.colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + this.colferListMax + ' elements');
+			if (l < 0 || l > colferListMax)
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
 
-			init.{{.NameNative}} = new Array(l);
+			this.{{.NameNative}} = new Array(l);
 			for (var n = 0; n < l; ++n) {
 				var size = readVarint();
-				if (size < 0 || size > this.colferSizeMax)
-					throw new Error('colfer: {{.String}}[' + init.{{.NameNative}}.length + '] size ' + size + ' exceeds ' + this.colferSizeMax + ' bytes');
+				if (size < 0 || size > colferSizeMax)
+					throw new Error('colfer: {{.String}}[' + this.{{.NameNative}}.length + '] size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
 
 				var start = i;
 				i += size;
-				if (i > data.length) throw new Error(this.EOF);
-				init.{{.NameNative}}[n] = data.slice(start, i);
+				if (i > data.length) throw new Error(EOF);
+				this.{{.NameNative}}[n] = data.slice(start, i);
 			}
  {{- else}}
 			var size = readVarint();
-			if (size < 0 || size > this.colferSizeMax)
-				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + this.colferSizeMax + ' bytes');
+			if (size < 0 || size > colferSizeMax)
+				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
 
 			var start = i;
 			i += size;
-			if (i > data.length) throw new Error(this.EOF);
-			init.{{.NameNative}} = data.slice(start, i);
+			if (i > data.length) throw new Error(EOF);
+			this.{{.NameNative}} = data.slice(start, i);
  {{- end}}
 			readHeader();
 		}
 {{else if .TypeList}}
 		if (header == {{.Index}}) {
 			var l = readVarint();
-			if (l < 0 || l > this.colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + this.colferListMax + ' elements');
+			if (l < 0 || l > colferListMax)
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
 
 			for (var n = 0; n < l; ++n) {
-				var o = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameNative}}();
+				var o = new {{.TypeNative}}();
 				i += o.unmarshal(data.subarray(i));
-				init.{{.NameNative}}[n] = o;
+				this.{{.NameNative}}[n] = o;
 			}
 			readHeader();
 		}
 {{else}}
 		if (header == {{.Index}}) {
-			var o = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameNative}}();
+			var o = new {{.TypeNative}}();
 			i += o.unmarshal(data.subarray(i));
-			init.{{.NameNative}} = o;
+			this.{{.NameNative}} = o;
 			readHeader();
 		}
 {{end}}{{end}}
 		if (header != 127) throw new Error('colfer: unknown header at byte ' + (i - 1));
-		if (i > this.colferSizeMax)
-			throw new Error('colfer: {{.String}} serial size ' + size + ' exceeds ' + this.colferSizeMax + ' bytes');
-		return init;
-	}`
+		if (i > colferSizeMax)
+			throw new Error('colfer: {{.String}} serial size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
+		return i;`
+
+const ecmaRPC = `/* eslint-disable no-redeclare */
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}} for the header.
+{{template "package" .}}
+
+// ColferRPCError is a failure response conform the Go type
+// github.com/pascaldekloe/colfer/rpc.Error.
+{{if .Module}}export {{end}}class ColferRPCError extends Error {
+	constructor(message, code, retryable, detail) {
+		super(message);
+		this.name = 'ColferRPCError';
+		// Classification with zero for unspecified.
+		this.code = code || 0;
+		// Whether the call may succeed when repeated.
+		this.retryable = !!retryable;
+		// Optional Colfer serial as an Uint8Array.
+		this.detail = detail || new Uint8Array(0);
+	}
+}
+
+// ColferRPC is a client for the Go package github.com/pascaldekloe/colfer/rpc
+// over a WebSocket, e.g., with rpc.WebSocketHandler. Each binary message holds
+// exactly one request or response.
+// The socket is either a WebSocket instance or an URL.
+{{if .Module}}export {{end}}class ColferRPC {
+	constructor(socket) {
+		if (typeof socket === 'string') socket = new WebSocket(socket);
+		socket.binaryType = 'arraybuffer';
+		this.socket = socket;
+		this.seq = 0;
+		// pending calls per sequence ID
+		this.pending = new Map();
+
+		this.opened = new Promise((resolve, reject) => {
+			if (socket.readyState === 1) {
+				resolve();
+				return;
+			}
+			socket.addEventListener('open', () => resolve());
+			socket.addEventListener('error', () => reject(new Error('colfer/rpc: WebSocket error')));
+		});
+		socket.addEventListener('message', (event) => this.receive(new Uint8Array(event.data)));
+		socket.addEventListener('close', () => {
+			this.pending.forEach((call) => call.reject(new Error('colfer/rpc: WebSocket closed')));
+			this.pending.clear();
+		});
+	}
+
+	// Invokes the service method (as in "Service.Method") with args, which
+	// must have a marshal function. The optional meta object has the metadata
+	// for the request as string properties. The returned promise resolves with
+	// reply, after the unmarshal function of reply decoded the response body,
+	// or it rejects with a ColferRPCError.
+	call(method, args, reply, meta) {
+		return this.opened.then(() => new Promise((resolve, reject) => {
+			var seq = this.seq++;
+			var body = args.marshal();
+			var h = new {{.NameNative}}.Header({seqID: seq, method: method, bodySize: body.length});
+			if (meta) h.meta = Object.keys(meta).sort().map((k) => new {{.NameNative}}.Entry({key: k, value: String(meta[k])}));
+			var header = h.marshal();
+			var message = new Uint8Array(header.length + body.length);
+			message.set(header, 0);
+			message.set(body, header.length);
+
+			this.pending.set(seq, {reply: reply, resolve: resolve, reject: reject});
+			this.socket.send(message);
+		}));
+	}
+
+	// Closes the WebSocket.
+	close() {
+		this.socket.close();
+	}
+
+	// private section
+
+	receive(data) {
+		var h = new {{.NameNative}}.Header();
+		var size;
+		try {
+			size = h.unmarshal(data);
+		} catch (e) {
+			this.pending.forEach((call) => call.reject(e));
+			this.pending.clear();
+			this.socket.close();
+			return;
+		}
+
+		var call = this.pending.get(h.seqID);
+		if (!call) return;
+		this.pending.delete(h.seqID);
+
+		if (h.error || h.errorCode || h.retryable || h.errorDetail.length) {
+			call.reject(new ColferRPCError(h.error, h.errorCode, h.retryable, h.errorDetail));
+			return;
+		}
+		if (h.compression) {
+			call.reject(new Error('colfer/rpc: unsupported compression ' + h.compression));
+			return;
+		}
+		if (size + h.bodySize > data.length) {
+			call.reject(new Error('colfer/rpc: body incomplete'));
+			return;
+		}
+		try {
+			call.reply.unmarshal(data.subarray(size, size + h.bodySize));
+			call.resolve(call.reply);
+		} catch (e) {
+			call.reject(e);
+		}
+	}
+}
+{{- if not .Module}}
+
+// NodeJS:
+if (typeof exports !== 'undefined') {
+	exports.ColferRPC = ColferRPC;
+	exports.ColferRPCError = ColferRPCError;
+}
+{{- end}}
+`
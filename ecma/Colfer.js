@@ -1,3 +1,4 @@
+/* eslint-disable no-redeclare */
 // Code generated by colf(1); DO NOT EDIT.
 // The compiler used schema file test.colf for package gen.
 
@@ -578,7 +579,6 @@ var gen = new function() {
 	// DromedaryCase oposes name casings.
 	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
 	this.DromedaryCase = function(init) {
-
 		this.pascalCase = '';
 
 		for (var p in init) this[p] = init[p];
@@ -657,7 +657,6 @@ var gen = new function() {
 	// Covers regression of issue #66.
 	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
 	this.EmbedO = function(init) {
-
 		this.inner = null;
 
 		for (var p in init) this[p] = init[p];
@@ -727,7 +726,7 @@ var gen = new function() {
 
 	// private section
 
-	var encodeVarint = function(bytes, i, x) {
+	function encodeVarint(bytes, i, x) {
 		while (x > 127) {
 			bytes[i++] = (x & 127) | 128;
 			x /= 128;
@@ -0,0 +1,41 @@
+// Module_test checks the ES module output against golden cases from test.js.
+import assert from 'node:assert/strict';
+import * as gen from './moduletest/gen.js';
+import {ColferRPC, ColferRPCError} from './moduletest/ColferRPC.js';
+
+function newGoldenCases() {
+	return {
+		'7f': {},
+		'007f': {b: true},
+		'81ffffffff7f': {u32: 4294967295},
+		'82001fffffffffffff7f': {u64: Number.MAX_SAFE_INTEGER},
+		'8380808080087f': {i32: -2147483648},
+		'84ffffffffffffff0f7f': {i64: -Number.MAX_SAFE_INTEGER},
+		'057f7fffff7f': {f32: 3.4028234663852886e+38},
+		'067fefffffffffffff7f': {f64: Number.MAX_VALUE},
+		'0755ef312a2e5da4e77f': {t: new Date(1441739050777), t_ns: 888999},
+		'0809c280e0a080f09080807f': {s: '\u0080\u0800\u{10000}'},
+		'090202007f': {a: new Uint8Array([2, 0])},
+		'0a007f7f': {o: new gen.O({b: true})},
+		'0b027f7f7f': {os: [new gen.O(), new gen.O()]},
+		'0c0300016101627f': {ss: ['', 'a', 'b']},
+		'0d0201000201027f': {as: [new Uint8Array([0]), new Uint8Array([1, 2])]},
+		'0eff7f': {u8: 255},
+		'0fffff7f': {u16: 65535},
+		'1002000000003f8000007f': {f32s: new Float32Array([0, 1])},
+		'11014058c000000000007f': {f64s: new Float64Array([99])}
+	};
+}
+
+for (const [hex, feed] of Object.entries(newGoldenCases())) {
+	const got = Buffer.from(new gen.O(feed).marshal()).toString('hex');
+	assert.equal(got, hex, 'marshal ' + JSON.stringify(feed));
+
+	const o = new gen.O();
+	const n = o.unmarshal(new Uint8Array(Buffer.from(hex, 'hex')));
+	assert.equal(n, hex.length / 2, 'unmarshal read size of ' + hex);
+	assert.deepEqual(o, new gen.O(feed), 'unmarshal ' + hex);
+}
+
+assert.equal(typeof ColferRPC, 'function', 'ColferRPC export');
+assert.ok(new ColferRPCError('test') instanceof Error, 'ColferRPCError export');
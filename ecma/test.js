@@ -1,4 +1,5 @@
 var gen = require('./Colfer.js').gen;
+var big = require('./bigint/Colfer.js').gen;
 
 QUnit.test('constructor', function(assert) {
 	assert.deepEqual(new gen.O(), new gen.O({}), 'absent and empty init');
@@ -100,6 +101,56 @@ QUnit.test('unmarshal', function(assert) {
 	}
 });
 
+function newBigIntCases() {
+	return {
+		'7f': {},
+		'02017f': {u64: 1n},
+		'02ffffffffffff7f7f': {u64: (1n << 49n) - 1n},
+		'8200020000000000007f': {u64: 1n << 49n},
+		'8200200000000000007f': {u64: 2n ** 53n},
+		'82ffffffffffffffff7f': {u64: 0xffffffffffffffffn},
+		'04017f': {i64: 1n},
+		'84017f': {i64: -1n},
+		'0480808080808080107f': {i64: 2n ** 53n},
+		'8480808080808080107f': {i64: -(2n ** 53n)},
+		'04ffffffffffffffff7f7f': {i64: 0x7fffffffffffffffn},
+		'848080808080808080807f': {i64: -0x8000000000000000n}
+	}
+}
+
+QUnit.test('marshal BigInt', function(assert) {
+	assert.strictEqual(new big.O().u64, 0n, 'uint64 default');
+	assert.strictEqual(new big.O().i64, 0n, 'int64 default');
+
+	var golden = newBigIntCases();
+	for (hex in golden) {
+		try {
+			var o = new big.O(golden[hex]);
+			assert.equal(encodeHex(o.marshal()), hex, hex);
+		} catch (err) {
+			assert.equal(err, 'no error', hex);
+		}
+	}
+
+	assert.throws(function() { new big.O({u64: 1n << 64n}).marshal() }, /out of reach/, 'uint64 overflow');
+	assert.throws(function() { new big.O({u64: -1n}).marshal() }, /out of reach/, 'uint64 negative');
+	assert.throws(function() { new big.O({i64: 1n << 63n}).marshal() }, /64-bit range/, 'int64 overflow');
+	assert.throws(function() { new big.O({i64: -(1n << 63n) - 1n}).marshal() }, /64-bit range/, 'int64 underflow');
+});
+
+QUnit.test('unmarshal BigInt', function(assert) {
+	var golden = newBigIntCases();
+	for (hex in golden) {
+		try {
+			var got = new big.O();
+			got.unmarshal(decodeHex(hex));
+			assert.deepEqual(got, new big.O(golden[hex]), hex);
+		} catch (err) {
+			assert.equal(err, 'no error', hex);
+		}
+	}
+});
+
 function encodeHex(bytes) {
 	var s = '';
 	if (!bytes) return s;
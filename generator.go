@@ -0,0 +1,251 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Generator produces code for a target language.
+type Generator interface {
+	// Generate returns the code for packages.
+	Generate(packages Packages) (Files, error)
+}
+
+// DirGenerator is a Generator which resolves its own placement on disk.
+type DirGenerator interface {
+	Generator
+
+	// GenerateDir writes the code for packages relative to basedir.
+	GenerateDir(basedir string, packages Packages) error
+}
+
+// GeneratorFunc is a Generator as a function.
+type GeneratorFunc func(Packages) (Files, error)
+
+// Generate honors the Generator interface.
+func (f GeneratorFunc) Generate(packages Packages) (Files, error) {
+	return f(packages)
+}
+
+// Features lists what a Generator supports in addition to the schema. The
+// zero value supports none.
+type Features struct {
+	// Title is the name of the target language.
+	Title string
+
+	// SuperClass, Interfaces and CodeSnippet flag support for the Package
+	// fields of the same name.
+	SuperClass  bool
+	Interfaces  bool
+	CodeSnippet bool
+
+	// Tags are the constraints for tag files.
+	Tags TagOptions
+
+	// Options has each Package option supported, with nil for any.
+	Options []string
+}
+
+// FeatureGenerator is a Generator which declares its Features.
+type FeatureGenerator interface {
+	Generator
+
+	// Features returns the support.
+	Features() Features
+}
+
+// GeneratorFeatures returns the support of g. Generators other than a
+// FeatureGenerator, such as a Plugin, support everything.
+func GeneratorFeatures(g Generator) Features {
+	if f, ok := g.(FeatureGenerator); ok {
+		return f.Features()
+	}
+	return Features{
+		SuperClass:  true,
+		Interfaces:  true,
+		CodeSnippet: true,
+		Tags:        TagOptions{StructAllow: TagMulti, FieldAllow: TagMulti},
+	}
+}
+
+// FeatureFunc is a GeneratorFunc with Features.
+type featureFunc struct {
+	GeneratorFunc
+	features Features
+}
+
+// Features honors the FeatureGenerator interface.
+func (f featureFunc) Features() Features {
+	return f.features
+}
+
+// GoGenerator applies the module lookup of GenerateGo on disk.
+type goGenerator struct{}
+
+// Features honors the FeatureGenerator interface.
+func (goGenerator) Features() Features {
+	return Features{
+		Title:   "Go",
+		Tags:    TagOptions{FieldAllow: TagSingle},
+		Options: []string{},
+	}
+}
+
+// Generate honors the Generator interface.
+func (goGenerator) Generate(packages Packages) (Files, error) {
+	return GenerateGoFiles(packages)
+}
+
+// GenerateDir honors the DirGenerator interface.
+func (goGenerator) GenerateDir(basedir string, packages Packages) error {
+	return GenerateGo(basedir, packages)
+}
+
+var (
+	cFeatures    = Features{Title: "C", Options: []string{}}
+	cppFeatures  = Features{Title: "C++", Options: []string{}}
+	javaFeatures = Features{
+		Title:       "Java",
+		SuperClass:  true,
+		Interfaces:  true,
+		CodeSnippet: true,
+		Tags:        TagOptions{StructAllow: TagMulti, FieldAllow: TagMulti},
+		Options:     []string{JavaImmutable, JavaUnsigned},
+	}
+	kotlinFeatures = Features{
+		Title:       "Kotlin",
+		Interfaces:  true,
+		CodeSnippet: true,
+		Tags:        TagOptions{StructAllow: TagMulti, FieldAllow: TagMulti},
+		Options:     []string{},
+	}
+	ecmaFeatures       = Features{Title: "ECMAScript", Options: []string{ECMAModule, ECMABigInt}}
+	jsonSchemaFeatures = Features{Title: "JSON Schema", Options: []string{}}
+	openAPIFeatures    = Features{Title: "OpenAPI", Options: []string{}}
+)
+
+var (
+	generatorsMutex sync.RWMutex
+	generators      = map[string]Generator{
+		"c":          featureFunc{GenerateCFiles, cFeatures},
+		"c++":        featureFunc{GenerateCPPFiles, cppFeatures},
+		"cpp":        featureFunc{GenerateCPPFiles, cppFeatures},
+		"go":         goGenerator{},
+		"java":       featureFunc{GenerateJavaFiles, javaFeatures},
+		"kotlin":     featureFunc{GenerateKotlinFiles, kotlinFeatures},
+		"ecmascript": featureFunc{GenerateECMAFiles, ecmaFeatures},
+		"javascript": featureFunc{GenerateECMAFiles, ecmaFeatures},
+		"js":         featureFunc{GenerateECMAFiles, ecmaFeatures},
+		"jsonschema": featureFunc{GenerateJSONSchemaFiles, jsonSchemaFeatures},
+		"openapi":    featureFunc{GenerateOpenAPIFiles, openAPIFeatures},
+	}
+)
+
+// RegisterGenerator makes g available under name, case-insensitive. It panics
+// when name is already in use.
+func RegisterGenerator(name string, g Generator) {
+	generatorsMutex.Lock()
+	defer generatorsMutex.Unlock()
+
+	name = strings.ToLower(name)
+	if g == nil {
+		panic("colfer: register of nil generator " + name)
+	}
+	if _, ok := generators[name]; ok {
+		panic("colfer: generator " + name + " registered twice")
+	}
+	generators[name] = g
+}
+
+// LookupGenerator returns the registration of name, case-insensitive. The
+// return is nil when not found.
+func LookupGenerator(name string) Generator {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+	return generators[strings.ToLower(name)]
+}
+
+// GeneratorNames returns all registrations in order.
+func GeneratorNames() []string {
+	generatorsMutex.RLock()
+	defer generatorsMutex.RUnlock()
+
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate writes the code from g into basedir.
+func Generate(g Generator, basedir string, packages Packages) error {
+	if d, ok := g.(DirGenerator); ok {
+		return d.GenerateDir(basedir, packages)
+	}
+	files, err := g.Generate(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// PluginPrefix is the executable name for external generators, followed by
+// the target language in lower case, as in "colf-gen-rust".
+const PluginPrefix = "colf-gen-"
+
+// Plugin is a Generator in an external executable. The program receives the
+// packages in JSON on standard input, as encoded by Packages MarshalJSON. The
+// program must respond on standard output with a JSON object which maps each
+// relative path to the content in base64, as encoded by Files. Any non-zero
+// exit is an error. Standard error is passed through.
+type Plugin struct {
+	// Path is the executable.
+	Path string
+	// Args are optional command-line arguments.
+	Args []string
+}
+
+// LookupPlugin resolves the executable for the target language from the PATH
+// environment variable.
+func LookupPlugin(lang string) (*Plugin, error) {
+	path, err := exec.LookPath(PluginPrefix + strings.ToLower(lang))
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{Path: path}, nil
+}
+
+// Generate honors the Generator interface.
+func (p *Plugin) Generate(packages Packages) (Files, error) {
+	input, err := json.Marshal(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(p.Path, p.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("colfer: plugin %s: %w", p.Path, err)
+	}
+
+	var files Files
+	if err := json.Unmarshal(output.Bytes(), &files); err != nil {
+		return nil, fmt.Errorf("colfer: plugin %s output: %w", p.Path, err)
+	}
+	for path := range files {
+		if path == "" || strings.HasPrefix(path, "/") || strings.ContainsRune(path, '\\') || strings.Contains("/"+path+"/", "/../") {
+			return nil, fmt.Errorf("colfer: plugin %s output path %q not relative to the base directory", p.Path, path)
+		}
+	}
+	return files, nil
+}
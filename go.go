@@ -2,6 +2,7 @@ package colfer
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -45,8 +46,35 @@ func goMod(dir string) (modDir, modPkg string, err error) {
 	return "", "", nil // not found
 }
 
-// GenerateGo writes the code into file "Colfer.go".
+// GenerateGo writes the code into file "Colfer.go". Packages within the Go
+// module of basedir, if any, are placed relative to the module root.
 func GenerateGo(basedir string, packages Packages) error {
+	modDir, modPkg, err := goMod(basedir)
+	if err != nil {
+		return err
+	}
+
+	files, err := GenerateGoFiles(packages)
+	if err != nil {
+		return err
+	}
+	for _, p := range packages {
+		path := p.Name + "/Colfer.go"
+		if modPkg != "" && strings.HasPrefix(p.Name, modPkg+"/") {
+			err = Files{p.Name[len(modPkg)+1:] + "/Colfer.go": files[path]}.Write(modDir)
+		} else {
+			err = Files{path: files[path]}.Write(basedir)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateGoFiles returns the code of GenerateGo, with a "Colfer.go" in the
+// directory of each package name. No module lookup is applied.
+func GenerateGoFiles(packages Packages) (Files, error) {
 	t := template.New("go-code")
 	template.Must(t.Parse(goCode))
 	template.Must(t.New("marshal-field").Parse(goMarshalField))
@@ -54,11 +82,7 @@ func GenerateGo(basedir string, packages Packages) error {
 	template.Must(t.New("unmarshal-field").Parse(goUnmarshalField))
 	template.Must(t.New("unmarshal-varint").Parse(goUnmarshalVarint))
 
-	modDir, modPkg, err := goMod(basedir)
-	if err != nil {
-		return err
-	}
-
+	files := make(Files, len(packages))
 	for _, p := range packages {
 		p.NameNative = p.Name[strings.LastIndexByte(p.Name, '/')+1:]
 		for _, t := range p.Structs {
@@ -94,27 +118,17 @@ func GenerateGo(basedir string, packages Packages) error {
 
 		var buf bytes.Buffer
 		if err := t.Execute(&buf, p); err != nil {
-			return err
-		}
-
-		path := filepath.Join(basedir, p.Name)
-		if modPkg != "" && strings.HasPrefix(p.Name, modPkg+"/") {
-			path = filepath.Join(modDir, p.Name[len(modPkg):])
-		}
-		if err := os.MkdirAll(path, 0777); err != nil {
-			return err
+			return nil, err
 		}
 
-		path = filepath.Join(path, "Colfer.go")
-		if err := ioutil.WriteFile(path, buf.Bytes(), 0666); err != nil {
-			return err
-		}
-
-		if _, err := FormatFile(path); err != nil {
-			return err
+		path := p.Name + "/Colfer.go"
+		src, err := Format(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("colfer: format %q: %s", path, err)
 		}
+		files[path] = src
 	}
-	return nil
+	return files, nil
 }
 
 const goCode = `{{.DocText "// "}}
@@ -0,0 +1,204 @@
+package gen
+
+import (
+	"encoding/hex"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/colfer"
+)
+
+// Tagged is the reflection equivalent of O.
+type tagged struct {
+	B    bool      `colfer:"0"`
+	U32  uint32    `colfer:"1"`
+	U64  uint64    `colfer:"2"`
+	I32  int32     `colfer:"3"`
+	I64  int64     `colfer:"4"`
+	F32  float32   `colfer:"5"`
+	F64  float64   `colfer:"6"`
+	T    time.Time `colfer:"7"`
+	S    string    `colfer:"8"`
+	A    []byte    `colfer:"9"`
+	O    *tagged   `colfer:"10"`
+	Os   []*tagged `colfer:"11"`
+	Ss   []string  `colfer:"12"`
+	As   [][]byte  `colfer:"13"`
+	U8   uint8     `colfer:"14"`
+	U16  uint16    `colfer:"15"`
+	F32s []float32 `colfer:"16"`
+	F64s []float64 `colfer:"17"`
+
+	Ignored string
+}
+
+// ID is a named type of a basic kind.
+type id uint64
+
+// Names is a named list type.
+type names []string
+
+// Token is a named binary type.
+type token []byte
+
+// Named has fields of named types.
+type named struct {
+	ID    id    `colfer:"0"`
+	Names names `colfer:"1"`
+	Token token `colfer:"2"`
+}
+
+func newTagged(o *O) *tagged {
+	if o == nil {
+		return nil
+	}
+	t := &tagged{B: o.B, U32: o.U32, U64: o.U64, I32: o.I32, I64: o.I64, F32: o.F32, F64: o.F64, T: o.T, S: o.S, A: o.A, O: newTagged(o.O), Ss: o.Ss, As: o.As, U8: o.U8, U16: o.U16, F32s: o.F32s, F64s: o.F64s}
+	for _, e := range o.Os {
+		t.Os = append(t.Os, newTagged(e))
+	}
+	return t
+}
+
+func untag(t *tagged) *O {
+	if t == nil {
+		return nil
+	}
+	o := &O{B: t.B, U32: t.U32, U64: t.U64, I32: t.I32, I64: t.I64, F32: t.F32, F64: t.F64, T: t.T, S: t.S, A: t.A, O: untag(t.O), Ss: t.Ss, As: t.As, U8: t.U8, U16: t.U16, F32s: t.F32s, F64s: t.F64s}
+	for _, e := range t.Os {
+		o.Os = append(o.Os, untag(e))
+	}
+	return o
+}
+
+func TestReflectMarshal(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := colfer.Marshal(newTagged(&gold.object))
+		if err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != gold.serial {
+			t.Errorf("Got 0x%s, want 0x%s", got, gold.serial)
+		}
+	}
+}
+
+func TestReflectUnmarshal(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := new(tagged)
+		if err := colfer.Unmarshal(data, got); err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+
+		// work around NaN != NaN
+		a, b := *untag(got), gold.object
+		if math.IsNaN(float64(a.F32)) && math.IsNaN(float64(b.F32)) {
+			a.F32, b.F32 = 0, 0
+		}
+		if math.IsNaN(a.F64) && math.IsNaN(b.F64) {
+			a.F64, b.F64 = 0, 0
+		}
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("0x%s: got %+v, want %+v", gold.serial, a, gold.object)
+		}
+	}
+}
+
+func TestReflectUnmarshalEOF(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range data {
+			incomplete := data[:i]
+			if err := colfer.Unmarshal(incomplete, new(tagged)); err != io.EOF {
+				t.Errorf("0x%s: got error %T: %q", hex.EncodeToString(incomplete), err, err)
+			}
+		}
+	}
+}
+
+func TestReflectUnmarshalTail(t *testing.T) {
+	err := colfer.Unmarshal([]byte{0x7f, 0x7f}, new(tagged))
+	if err == nil || err.Error() != "colfer: data continuation at byte 1" {
+		t.Errorf("got error %v, want data continuation at byte 1", err)
+	}
+}
+
+func TestParseGoFiles(t *testing.T) {
+	got, err := colfer.ParseGoFiles("reflect_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := colfer.ParseFiles("../testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || len(got[0].Structs) != 2 {
+		t.Fatalf("got %d packages, want 1 package with 2 structs", len(got))
+	}
+	gotFields := got[0].Structs[0].Fields
+	wantFields := want[0].Structs[0].Fields
+	if len(gotFields) != len(wantFields) {
+		t.Fatalf("got %d fields, want %d", len(gotFields), len(wantFields))
+	}
+	for i, f := range gotFields {
+		w := wantFields[i]
+		if f.Name != w.Name || f.TypeList != w.TypeList || f.Index != w.Index {
+			t.Errorf("field %d: got %s (list %t), want %s (list %t)", i, f.Name, f.TypeList, w.Name, w.TypeList)
+		}
+		if f.TypeRef != nil {
+			if w.TypeRef == nil || f.TypeRef != got[0].Structs[0] {
+				t.Errorf("field %s: got reference to %s, want %s", f, f.TypeRef, w.Type)
+			}
+		} else if f.Type != w.Type {
+			t.Errorf("field %s: got type %s, want %s", f, f.Type, w.Type)
+		}
+	}
+}
+
+func TestNamedTypes(t *testing.T) {
+	packages, err := colfer.ParseGoFiles("reflect_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := packages[0].Structs[1]
+	want := []struct{ name, typ string }{{"id", "uint64"}, {"names", "text"}, {"token", "binary"}}
+	if s.Name != "named" || len(s.Fields) != len(want) {
+		t.Fatalf("got %s with %d fields, want named with %d fields", s, len(s.Fields), len(want))
+	}
+	for i, f := range s.Fields {
+		if f.Name != want[i].name || f.Type != want[i].typ || f.TypeList != (want[i].name == "names") {
+			t.Errorf("field %d: got %s of type %s (list %t), want %s of type %s", i, f.Name, f.Type, f.TypeList, want[i].name, want[i].typ)
+		}
+	}
+
+	v := &named{ID: 5, Names: names{"a"}, Token: token{1}}
+	data, err := colfer.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const serial = "0005010101610201017f"
+	if got := hex.EncodeToString(data); got != serial {
+		t.Errorf("got 0x%s, want 0x%s", got, serial)
+	}
+	got := new(named)
+	if err := colfer.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("got %+v, want %+v", got, v)
+	}
+}
@@ -0,0 +1,330 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode"
+
+	"github.com/pascaldekloe/name"
+)
+
+// ParseGoFiles returns the schema definitions from Go source, i.e., all structs
+// with colfer tags on their fields, as used by Marshal and Unmarshal. The
+// indices must cover each position from zero onwards. Struct references must
+// stay within the package.
+func ParseGoFiles(paths ...string) (Packages, error) {
+	var packages Packages
+	// goNames has the Go type names per package.
+	goNames := make(map[*Package]map[string]*Struct)
+	// goNamed has the named types other than structs per package, which
+	// resolve by their underlying type, as with reflection.
+	goNamed := make(map[*Package]map[string]ast.Expr)
+
+	fileSet := token.NewFileSet()
+	files := make([]*ast.File, len(paths))
+	filePkgs := make([]*Package, len(paths))
+	for i, goPath := range paths {
+		fileAST, err := parser.ParseFile(fileSet, goPath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = fileAST
+
+		var pkg *Package
+		for _, p := range packages {
+			if p.Name == fileAST.Name.Name {
+				pkg = p
+				break
+			}
+		}
+		if pkg == nil {
+			pkg = &Package{Name: fileAST.Name.Name}
+			packages = append(packages, pkg)
+			goNames[pkg] = make(map[string]*Struct)
+			goNamed[pkg] = make(map[string]ast.Expr)
+		}
+		filePkgs[i] = pkg
+
+		for _, decl := range fileAST.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				switch spec.Type.(type) {
+				case *ast.Ident, *ast.ArrayType:
+					goNamed[pkg][spec.Name.Name] = spec.Type
+				}
+			}
+		}
+	}
+
+	for i, fileAST := range files {
+		goPath, pkg := paths[i], filePkgs[i]
+
+		var hasStruct bool
+		for _, decl := range fileAST.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				specType, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				t := &Struct{Pkg: pkg, Name: goSchemaName(spec.Name.Name), SchemaFile: path.Base(goPath)}
+				if err := mapGoStruct(t, specType, goNamed[pkg]); err != nil {
+					return nil, err
+				}
+				if len(t.Fields) == 0 {
+					continue // not a Colfer type
+				}
+				if dupe, ok := goNames[pkg][spec.Name.Name]; ok {
+					return nil, fmt.Errorf("colfer: duplicate %s declaration in file %s and %s", dupe, dupe.SchemaFile, t.SchemaFile)
+				}
+				goNames[pkg][spec.Name.Name] = t
+				hasStruct = true
+
+				t.Docs = append(docs(decl.Doc), docs(spec.Doc)...)
+				pkg.Structs = append(pkg.Structs, t)
+			}
+		}
+		if hasStruct {
+			pkg.SchemaFiles = append(pkg.SchemaFiles, path.Base(goPath))
+			pkg.Docs = append(pkg.Docs, docs(fileAST.Doc)...)
+		}
+	}
+
+	// resolve struct references
+	var nonEmpty Packages
+	for _, pkg := range packages {
+		if len(pkg.Structs) == 0 {
+			continue
+		}
+		nonEmpty = append(nonEmpty, pkg)
+		for _, t := range pkg.Structs {
+			for _, f := range t.Fields {
+				if _, ok := datatypes[f.Type]; ok {
+					continue
+				}
+				ref, ok := goNames[pkg][f.Type]
+				if !ok {
+					return nil, fmt.Errorf("colfer: field %s references Go type %s, which is not a struct with colfer tags in package %s", f, f.Type, pkg.Name)
+				}
+				f.Type = ref.Name
+				f.TypeRef = ref
+			}
+		}
+	}
+	return nonEmpty, nil
+}
+
+// MapGoStruct adds the fields with a colfer tag in order of their index. The
+// Field Type is the Go name for struct references. Named types other than
+// structs resolve with named.
+func mapGoStruct(dst *Struct, src *ast.StructType, named map[string]ast.Expr) error {
+	for _, f := range src.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tags, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return fmt.Errorf("colfer: malformed tag %s in %s", f.Tag.Value, dst)
+		}
+		tag, ok := reflect.StructTag(tags).Lookup("colfer")
+		if !ok {
+			continue
+		}
+
+		if len(f.Names) != 1 {
+			return fmt.Errorf("colfer: tag %s in %s needs a field with one name", f.Tag.Value, dst)
+		}
+		field := &Field{Struct: dst, Name: goSchemaName(f.Names[0].Name)}
+		index, err := strconv.ParseUint(tag, 10, 8)
+		if err != nil || index > 126 {
+			return fmt.Errorf("colfer: field %s tag %q is not an index in [0, 126]", field, tag)
+		}
+		field.Index = int(index)
+		field.Docs = docs(f.Doc)
+		if err := mapGoType(field, f.Type, named); err != nil {
+			return err
+		}
+		dst.Fields = append(dst.Fields, field)
+	}
+
+	sort.SliceStable(dst.Fields, func(i, j int) bool {
+		return dst.Fields[i].Index < dst.Fields[j].Index
+	})
+	for i, f := range dst.Fields {
+		if f.Index != i {
+			return fmt.Errorf("colfer: field %s has index %d, want %d", f, f.Index, i)
+		}
+	}
+	return nil
+}
+
+// MapGoType applies the datatype of expr to f. Named types other than structs
+// resolve with named by their underlying type.
+func mapGoType(f *Field, expr ast.Expr, named map[string]ast.Expr) error {
+	expr = goUnderlying(expr, named)
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len == nil {
+		elt := goUnderlying(array.Elt, named)
+		if isGoIdent(elt, "byte", "uint8") {
+			f.Type = "binary"
+			return nil
+		}
+		f.TypeList = true
+		expr = elt
+
+		if array, ok := expr.(*ast.ArrayType); ok && array.Len == nil && isGoIdent(goUnderlying(array.Elt, named), "byte", "uint8") {
+			f.Type = "binary"
+			return nil
+		}
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+		ident, ok := expr.(*ast.Ident)
+		if !ok || goUnderlying(ident, named) != ast.Expr(ident) || goBasicTypes[ident.Name] {
+			return fmt.Errorf("colfer: unsupported pointer type for field %s", f)
+		}
+	} else if sel, ok := expr.(*ast.SelectorExpr); ok && !f.TypeList && isGoIdent(sel.X, "time") && sel.Sel.Name == "Time" {
+		f.Type = "timestamp"
+		return nil
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("colfer: unsupported datatype declaration %T for field %s", expr, f)
+	}
+	switch ident.Name {
+	case "bool", "uint8", "uint16", "uint32", "uint64":
+		if f.TypeList {
+			return fmt.Errorf("colfer: unsupported list type %s for field %s", ident.Name, f)
+		}
+		f.Type = ident.Name
+	case "byte":
+		if f.TypeList {
+			return fmt.Errorf("colfer: unsupported list type %s for field %s", ident.Name, f)
+		}
+		f.Type = "uint8"
+	case "int32", "int64", "float32", "float64":
+		f.Type = ident.Name
+	case "string":
+		f.Type = "text"
+	case "int", "uint", "int8", "int16", "uintptr", "rune", "complex64", "complex128", "error":
+		return fmt.Errorf("colfer: unsupported type %s for field %s", ident.Name, f)
+	default:
+		f.Type = ident.Name // resolved by ParseGoFiles
+	}
+	return nil
+}
+
+// GoBasicTypes has the predeclared identifiers of non-struct types.
+var goBasicTypes = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true,
+	"int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// GoUnderlying returns the type definition of expr when it names a type
+// from named, recursively. Other expressions pass as is.
+func goUnderlying(expr ast.Expr, named map[string]ast.Expr) ast.Expr {
+	// limit guards against invalid, cyclic definitions
+	for limit := len(named); limit >= 0; limit-- {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			break
+		}
+		def, ok := named[ident.Name]
+		if !ok {
+			break
+		}
+		expr = def
+	}
+	return expr
+}
+
+// GoSchemaName returns the schema equivalent of a Go identifier. Leading
+// initialisms are lower case in full, as in ID to id and HTTPServer to
+// httpServer.
+func goSchemaName(s string) string {
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) && string(runes[n:]) != "s" {
+		n-- // start of the next word
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return name.CamelCase(string(runes), false)
+}
+
+func isGoIdent(expr ast.Expr, names ...string) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for _, s := range names {
+		if ident.Name == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSchema writes p as a Colfer schema.
+func (p *Package) WriteSchema(w io.Writer) error {
+	var buf bytes.Buffer
+	writeDocs(&buf, p.Docs, "")
+	fmt.Fprintf(&buf, "package %s\n", path.Base(p.Name))
+
+	for _, t := range p.Structs {
+		buf.WriteByte('\n')
+		writeDocs(&buf, t.Docs, "")
+		fmt.Fprintf(&buf, "type %s struct {\n", t.Name)
+		for _, f := range t.Fields {
+			writeDocs(&buf, f.Docs, "\t")
+			typ := f.Type
+			if f.TypeRef != nil && f.TypeRef.Pkg != p {
+				typ = path.Base(f.TypeRef.Pkg.Name) + "." + f.TypeRef.Name
+			}
+			if f.TypeList {
+				typ = "[]" + typ
+			}
+			fmt.Fprintf(&buf, "\t%s %s\n", f.Name, typ)
+		}
+		buf.WriteString("}\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("colfer: schema of package %s: %s", p.Name, err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func writeDocs(buf *bytes.Buffer, docs []string, indent string) {
+	for _, s := range docs {
+		buf.WriteString(indent)
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+	}
+}
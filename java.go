@@ -1,8 +1,8 @@
 package colfer
 
 import (
-	"os"
-	"path/filepath"
+	"bytes"
+	"fmt"
 	"strings"
 	"text/template"
 	"unicode"
@@ -29,6 +29,15 @@ var javaKeywords = map[string]struct{}{
 	"volatile": {}, "while": {},
 }
 
+// JavaImmutable is the Package option for immutable classes, each with a
+// nested Builder class.
+const JavaImmutable = "immutable"
+
+// JavaUnsigned is the Package option for unsigned integers in the next wider
+// type, i.e., short for uint8, int for uint16 and long for uint32. Values of
+// uint64 remain a long, with a getter for the unsigned representation.
+const JavaUnsigned = "unsigned"
+
 func toJavaName(name string) string {
 	name = strings.ReplaceAll(name, "/", ".")
 
@@ -48,6 +57,15 @@ func toJavaName(name string) string {
 
 // GenerateJava writes the code into the respective ".java" files.
 func GenerateJava(basedir string, packages Packages) error {
+	files, err := GenerateJavaFiles(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// GenerateJavaFiles returns the code of GenerateJava.
+func GenerateJavaFiles(packages Packages) (Files, error) {
 	titleCache := make(map[string]string)
 	funcs := template.FuncMap{"title": func(s string) string {
 		if t, ok := titleCache[s]; ok {
@@ -80,6 +98,9 @@ func GenerateJava(basedir string, packages Packages) error {
 
 		for _, t := range p.Structs {
 			t.NameNative = name.CamelCase(t.Name, true)
+			if t.NameNative == "Builder" && p.HasOption(JavaImmutable) {
+				return nil, fmt.Errorf("colfer: %s conflicts with the Builder of option %s", t, JavaImmutable)
+			}
 			for _, f := range t.Fields {
 				f.NameNative = name.CamelCase(f.Name, false)
 				if _, ok := javaKeywords[f.NameNative]; ok {
@@ -89,22 +110,16 @@ func GenerateJava(basedir string, packages Packages) error {
 		}
 	}
 
+	files := make(Files)
 	for _, p := range packages {
-		pkgdir := filepath.Join(basedir, strings.Replace(p.NameNative, ".", string([]rune{filepath.Separator}), -1))
-		if err := os.MkdirAll(pkgdir, os.ModeDir|os.ModePerm); err != nil {
-			return err
-		}
+		pkgdir := strings.Replace(p.NameNative, ".", "/", -1)
 
 		if doc := p.DocText(" * "); doc != "" {
-			f, err := os.Create(filepath.Join(pkgdir, "package-info.java"))
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-
-			if err := packageTemplate.Execute(f, p); err != nil {
-				return err
+			var buf bytes.Buffer
+			if err := packageTemplate.Execute(&buf, p); err != nil {
+				return nil, err
 			}
+			files[pkgdir+"/package-info.java"] = buf.Bytes()
 		}
 
 		for _, t := range p.Structs {
@@ -123,9 +138,20 @@ func GenerateJava(basedir string, packages Packages) error {
 					f.TypeNative = "boolean"
 				case "uint8":
 					f.TypeNative = "byte"
+					if p.HasOption(JavaUnsigned) {
+						f.TypeNative = "short"
+					}
 				case "uint16":
 					f.TypeNative = "short"
-				case "uint32", "int32":
+					if p.HasOption(JavaUnsigned) {
+						f.TypeNative = "int"
+					}
+				case "uint32":
+					f.TypeNative = "int"
+					if p.HasOption(JavaUnsigned) {
+						f.TypeNative = "long"
+					}
+				case "int32":
 					f.TypeNative = "int"
 				case "uint64", "int64":
 					f.TypeNative = "long"
@@ -142,18 +168,14 @@ func GenerateJava(basedir string, packages Packages) error {
 				}
 			}
 
-			f, err := os.Create(filepath.Join(pkgdir, t.NameNative+".java"))
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-
-			if err := codeTemplate.Execute(f, t); err != nil {
-				return err
+			var buf bytes.Buffer
+			if err := codeTemplate.Execute(&buf, t); err != nil {
+				return nil, err
 			}
+			files[pkgdir+"/"+t.NameNative+".java"] = buf.Bytes()
 		}
 	}
-	return nil
+	return files, nil
 }
 
 const javaPackage = `// Code generated by colf(1); DO NOT EDIT.
@@ -186,10 +208,18 @@ import java.nio.charset.StandardCharsets;
 import java.util.InputMismatchException;
 import java.nio.BufferOverflowException;
 import java.nio.BufferUnderflowException;
-
+import java.nio.ByteBuffer;
+import java.nio.channels.ReadableByteChannel;
+{{$immutable := .Pkg.HasOption "immutable"}}
+{{- $unsigned := .Pkg.HasOption "unsigned"}}
 
 /**
+{{- if $immutable}}
+ * Immutable value with built-in serialization support.
+ * Instances are composed with a {@link Builder}.
+{{- else}}
  * Data bean with built-in serialization support.
+{{- end}}
 {{.DocText " * "}}
  * @author generated by colf(1)
  * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
@@ -197,7 +227,7 @@ import java.nio.BufferUnderflowException;
 {{- range .TagAdd}}
 {{.}}
 {{- end}}
-{{$class := .NameNative}}public class {{$class}} {{if .Pkg.SuperClass}}extends {{.Pkg.SuperClassNative}} {{end}}implements Serializable{{range .Pkg.InterfaceNatives}}, {{.}}{{end}} {
+{{$class := .NameNative}}public {{if $immutable}}final {{end}}class {{$class}} {{if .Pkg.SuperClass}}extends {{.Pkg.SuperClassNative}} {{end}}implements Serializable{{range .Pkg.InterfaceNatives}}, {{.}}{{end}} {
 
 	/** The upper limit for serial byte sizes. */
 	public static int colferSizeMax = {{.Pkg.SizeMax}};
@@ -214,12 +244,52 @@ import java.nio.BufferUnderflowException;
 {{- range .TagAdd}}
 	{{.}}
 {{- end}}
-	public {{.TypeNative}}{{if .TypeList}}[]{{end}} {{.NameNative}};{{end}}
+	{{if $immutable}}private{{else}}public{{end}} {{.TypeNative}}{{if .TypeList}}[]{{end}} {{.NameNative}};{{end}}
 
 	/** Default constructor */
-	public {{$class}}() {
+	{{if $immutable}}private{{else}}public{{end}} {{$class}}() {
 		init();
-	}{{if .Pkg.CodeSnippet}}
+	}
+{{- if $immutable}}
+
+	/**
+	 * Copy constructor with defensive copies of all arrays.
+	 * Any {@code null} values are replaced with their Colfer zero value.
+	 * @param o the original.
+	 */
+	private {{$class}}({{$class}} o) {
+{{- range .Fields}}
+{{- if .TypeList}}
+ {{- if eq .Type "binary"}}
+		if (o.{{.NameNative}} == null) {
+			this.{{.NameNative}} = _zeroBinaries;
+		} else {
+			this.{{.NameNative}} = new byte[o.{{.NameNative}}.length][];
+			for (int i = 0; i < this.{{.NameNative}}.length; i++) {
+				byte[] b = o.{{.NameNative}}[i];
+				this.{{.NameNative}}[i] = b == null ? _zeroBytes : b.clone();
+			}
+		}
+ {{- else}}
+		this.{{.NameNative}} = o.{{.NameNative}} == null ? _zero{{title .NameNative}} : o.{{.NameNative}}.clone();
+  {{- if eq .Type "text"}}
+		for (int i = 0; i < this.{{.NameNative}}.length; i++)
+			if (this.{{.NameNative}}[i] == null) this.{{.NameNative}}[i] = "";
+  {{- else if .TypeRef}}
+		for (int i = 0; i < this.{{.NameNative}}.length; i++)
+			if (this.{{.NameNative}}[i] == null) this.{{.NameNative}}[i] = new {{.TypeNative}}{{if .TypeRef.Pkg.HasOption "immutable"}}.Builder().build(){{else}}(){{end}};
+  {{- end}}
+ {{- end}}
+{{- else if eq .Type "text"}}
+		this.{{.NameNative}} = o.{{.NameNative}} == null ? "" : o.{{.NameNative}};
+{{- else if eq .Type "binary"}}
+		this.{{.NameNative}} = o.{{.NameNative}} == null ? _zeroBytes : o.{{.NameNative}}.clone();
+{{- else}}
+		this.{{.NameNative}} = o.{{.NameNative}};
+{{- end}}
+{{- end}}
+	}
+{{- end}}{{if .Pkg.CodeSnippet}}
 
 	// BEGIN Code Snippet Injection
 
@@ -299,14 +369,14 @@ import java.nio.BufferUnderflowException;
 
 		/**
 		 * Deserializes the following object.
-		 * @return the result or {@code null} when EOF.
+		 * @return the result or {@code null} when no data is available,
+		 * which is either EOF or, for a non-blocking {@link ChannelUnmarshaller},
+		 * no data at the moment; see {@link ChannelUnmarshaller#isEOF()}.
 		 * @throws IOException from the input stream.
 		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
 		 * @throws InputMismatchException when the data does not match this object's schema.
 		 */
 		public {{$class}} next() throws IOException {
-			if (in == null) return null;
-
 			while (true) {
 				if (this.i > this.offset) {
 					try {
@@ -330,17 +400,83 @@ import java.nio.BufferUnderflowException;
 				}
 				assert this.i < this.buf.length;
 
-				int n = in.read(buf, i, buf.length - i);
+				int n = read(buf, i, buf.length - i);
 				if (n < 0) {
 					if (this.i > this.offset)
 						throw new InputMismatchException("colfer: pending data with EOF");
 					return null;
 				}
-				assert n > 0;
+				if (n == 0) return null;
 				i += n;
 			}
 		}
 
+		/**
+		 * Reads from the data source.
+		 * @param buf the destination.
+		 * @param off the initial index for {@code buf}, inclusive.
+		 * @param len the maximum number of bytes.
+		 * @return the number of bytes read, or -1 when EOF, or 0 when no
+		 * data is available at the moment.
+		 * @throws IOException from the data source.
+		 */
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (in == null) return -1;
+			return in.read(buf, off, len);
+		}
+
+	}
+
+	/**
+	 * {@link #reset(ReadableByteChannel) Reusable} deserialization of Colfer
+	 * streams from a channel. Data is read into the buffer directly. With a
+	 * non-blocking channel, {@link #next() next} may return {@code null} when
+	 * no more data is available at the moment; see {@link #isEOF()}.
+	 */
+	public static class ChannelUnmarshaller extends Unmarshaller {
+
+		/** The data source. */
+		protected ReadableByteChannel channel;
+
+		/** Whether {@link #channel} reached EOF. */
+		protected boolean eof;
+
+		/**
+		 * @param channel the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 */
+		public ChannelUnmarshaller(ReadableByteChannel channel, byte[] buf) {
+			super(null, buf);
+			reset(channel);
+		}
+
+		/**
+		 * Reuses the marshaller.
+		 * @param channel the data source or {@code null}.
+		 * @throws IllegalStateException on pending data.
+		 */
+		public void reset(ReadableByteChannel channel) {
+			reset((InputStream) null);
+			this.channel = channel;
+			this.eof = channel == null;
+		}
+
+		/**
+		 * Gets whether the data source is exhausted.
+		 * @return whether the channel reached EOF.
+		 */
+		public boolean isEOF() {
+			return this.eof;
+		}
+
+		@Override
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (this.eof) return -1;
+			int n = this.channel.read(ByteBuffer.wrap(buf, off, len));
+			if (n < 0) this.eof = true;
+			return n;
+		}
+
 	}
 
 	/**
@@ -393,7 +529,7 @@ import java.nio.BufferUnderflowException;
 
 	/**
 	 * Serializes the object.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+{{- range .Fields}}{{if .TypeList}}{{if or $immutable (eq .Type "float32" "float64")}}{{else}}
 	 * All {@code null} elements in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else}}a {@code new} value{{end}}.
 {{- end}}{{end}}{{end}}
 	 * @param out the data destination.
@@ -416,9 +552,38 @@ import java.nio.BufferUnderflowException;
 		return buf;
 	}
 
+	/**
+	 * Serializes the object into the remaining space of {@code buf}, and it
+	 * advances the position accordingly. Heap buffers are written in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+{{- range .Fields}}{{if .TypeList}}{{if or $immutable (eq .Type "float32" "float64")}}{{else}}
+	 * All {@code null} elements in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else}}a {@code new} value{{end}}.
+{{- end}}{{end}}{{end}}
+	 * @param buf the data destination.
+	 * @return the number of bytes written.
+	 * @throws BufferOverflowException when {@code buf} has insufficient space remaining.
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws java.nio.ReadOnlyBufferException when {@code buf} is read-only.
+	 * @throws IllegalStateException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 */
+	public int marshal(ByteBuffer buf) {
+		int fit = marshalFit();
+		if (buf.hasArray() && buf.remaining() >= fit) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = marshal(buf.array(), offset) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[fit];
+		int n = marshal(a, 0);
+		buf.put(a, 0, n);
+		return n;
+	}
+
 	/**
 	 * Serializes the object.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+{{- range .Fields}}{{if .TypeList}}{{if or $immutable (eq .Type "float32" "float64")}}{{else}}
 	 * All {@code null} elements in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else}}a {@code new} value{{end}}.
 {{- end}}{{end}}{{end}}
 	 * @param buf the data destination.
@@ -437,13 +602,24 @@ import java.nio.BufferUnderflowException;
 			}
 {{else if eq .Type "uint8"}}
 			if (this.{{.NameNative}} != 0) {
+{{- if $unsigned}}
+				if ((this.{{.NameNative}} & ~0xff) != 0)
+					throw new IllegalStateException(format("colfer: {{.String}} value %d out of range [0, 255]", this.{{.NameNative}}));
+{{- end}}
 				buf[i++] = (byte) {{.Index}};
-				buf[i++] = this.{{.NameNative}};
+				buf[i++] = {{if $unsigned}}(byte) {{end}}this.{{.NameNative}};
 			}
 {{else if eq .Type "uint16"}}
 			if (this.{{.NameNative}} != 0) {
+{{- if $unsigned}}
+				if ((this.{{.NameNative}} & ~0xffff) != 0)
+					throw new IllegalStateException(format("colfer: {{.String}} value %d out of range [0, 65535]", this.{{.NameNative}}));
+				int x = this.{{.NameNative}};
+				if ((x & 0xff00) != 0) {
+{{- else}}
 				short x = this.{{.NameNative}};
 				if ((x & (short)0xff00) != 0) {
+{{- end}}
 					buf[i++] = (byte) {{.Index}};
 					buf[i++] = (byte) (x >>> 8);
 				} else {
@@ -453,7 +629,13 @@ import java.nio.BufferUnderflowException;
 			}
 {{else if eq .Type "uint32"}}
 			if (this.{{.NameNative}} != 0) {
+{{- if $unsigned}}
+				if ((this.{{.NameNative}} & ~0xffffffffL) != 0)
+					throw new IllegalStateException(format("colfer: {{.String}} value %d out of range [0, 4294967295]", this.{{.NameNative}}));
+				int x = (int) this.{{.NameNative}};
+{{- else}}
 				int x = this.{{.NameNative}};
+{{- end}}
 				if ((x & ~((1 << 21) - 1)) != 0) {
 					buf[i++] = (byte) ({{.Index}} | 0x80);
 					buf[i++] = (byte) (x >>> 24);
@@ -808,10 +990,12 @@ import java.nio.BufferUnderflowException;
 
 				for (int ai = 0; ai < a.length; ai++) {
 					{{.TypeNative}} o = a[ai];
+{{- if not $immutable}}
 					if (o == null) {
 						o = new {{.TypeNative}}();
 						a[ai] = o;
 					}
+{{- end}}
 					i = o.marshal(buf, i);
 				}
 			}
@@ -840,10 +1024,36 @@ import java.nio.BufferUnderflowException;
 	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
 	 * @throws InputMismatchException when the data does not match this object's schema.
 	 */
-	public int unmarshal(byte[] buf, int offset) {
+	{{if $immutable}}private{{else}}public{{end}} int unmarshal(byte[] buf, int offset) {
 		return unmarshal(buf, offset, buf.length);
 	}
 
+	/**
+	 * Deserializes the object from the remaining content of {@code buf}, and
+	 * it advances the position accordingly. Heap buffers are read in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+	 * @param buf the data source.
+	 * @return the number of bytes read.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	{{if $immutable}}private{{else}}public{{end}} int unmarshal(ByteBuffer buf) {
+		if (buf.hasArray()) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = unmarshal(buf.array(), offset, buf.arrayOffset() + buf.limit()) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[Math.min(buf.remaining(), {{$class}}.colferSizeMax)];
+		buf.duplicate().get(a);
+		int n = unmarshal(a, 0);
+		buf.position(buf.position() + n);
+		return n;
+	}
+
 	/**
 	 * Deserializes the object.
 	 * @param buf the data source.
@@ -854,7 +1064,7 @@ import java.nio.BufferUnderflowException;
 	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
 	 * @throws InputMismatchException when the data does not match this object's schema.
 	 */
-	public int unmarshal(byte[] buf, int offset, int end) {
+	{{if $immutable}}private{{else}}public{{end}} int unmarshal(byte[] buf, int offset, int end) {
 		if (end > buf.length) end = buf.length;
 		int i = offset;
 
@@ -867,15 +1077,15 @@ import java.nio.BufferUnderflowException;
 			}
 {{else if eq .Type "uint8"}}
 			if (header == (byte) {{.Index}}) {
-				this.{{.NameNative}} = buf[i++];
+				this.{{.NameNative}} = {{if $unsigned}}(short) (buf[i++] & 0xff){{else}}buf[i++]{{end}};
 				header = buf[i++];
 			}
 {{else if eq .Type "uint16"}}
 			if (header == (byte) {{.Index}}) {
-				this.{{.NameNative}} = (short) ((buf[i++] & 0xff) << 8 | (buf[i++] & 0xff));
+				this.{{.NameNative}} = {{if $unsigned}}(buf[i++] & 0xff) << 8 | (buf[i++] & 0xff){{else}}(short) ((buf[i++] & 0xff) << 8 | (buf[i++] & 0xff)){{end}};
 				header = buf[i++];
 			} else if (header == (byte) ({{.Index}} | 0x80)) {
-				this.{{.NameNative}} = (short) (buf[i++] & 0xff);
+				this.{{.NameNative}} = {{if $unsigned}}buf[i++] & 0xff{{else}}(short) (buf[i++] & 0xff){{end}};
 				header = buf[i++];
 			}
 {{else if eq .Type "uint32"}}
@@ -886,10 +1096,10 @@ import java.nio.BufferUnderflowException;
 					x |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				this.{{.NameNative}} = x;
+				this.{{.NameNative}} = x{{if $unsigned}} & 0xffffffffL{{end}};
 				header = buf[i++];
 			} else if (header == (byte) ({{.Index}} | 0x80)) {
-				this.{{.NameNative}} = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				this.{{.NameNative}} = {{if $unsigned}}((buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff)) & 0xffffffffL{{else}}(buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff){{end}};
 				header = buf[i++];
 			}
 {{else if eq .Type "uint64"}}
@@ -1128,17 +1338,29 @@ import java.nio.BufferUnderflowException;
 
 				{{.TypeNative}}[] a = new {{.TypeNative}}[length];
 				for (int ai = 0; ai < length; ai++) {
+{{- if .TypeRef.Pkg.HasOption "immutable"}}
+					{{.TypeNative}}.Builder ob = new {{.TypeNative}}.Builder();
+					i = ob.unmarshal(buf, i, end);
+					a[ai] = ob.build();
+{{- else}}
 					{{.TypeNative}} o = new {{.TypeNative}}();
 					i = o.unmarshal(buf, i, end);
 					a[ai] = o;
+{{- end}}
 				}
 				this.{{.NameNative}} = a;
 				header = buf[i++];
 			}
 {{else}}
 			if (header == (byte) {{.Index}}) {
+{{- if .TypeRef.Pkg.HasOption "immutable"}}
+				{{.TypeNative}}.Builder ob = new {{.TypeNative}}.Builder();
+				i = ob.unmarshal(buf, i, end);
+				this.{{.NameNative}} = ob.build();
+{{- else}}
 				this.{{.NameNative}} = new {{.TypeNative}}();
 				i = this.{{.NameNative}}.unmarshal(buf, i, end);
+{{- end}}
 				header = buf[i++];
 			}
 {{end}}{{end}}
@@ -1180,6 +1402,23 @@ import java.nio.BufferUnderflowException;
 		init();
 	}
 {{range .Fields}}
+{{- if $immutable}}
+	/**
+	 * Gets {{.String}}.
+	 * @return the value{{if or .TypeList (eq .Type "binary")}}, as a copy{{end}}.
+	 */
+	public {{.TypeNative}}{{if .TypeList}}[]{{end}} get{{title .NameNative}}() {
+{{- if and .TypeList (eq .Type "binary")}}
+		byte[][] a = new byte[this.{{.NameNative}}.length][];
+		for (int i = 0; i < a.length; i++) a[i] = this.{{.NameNative}}[i].clone();
+		return a;
+{{- else if or .TypeList (eq .Type "binary")}}
+		return this.{{.NameNative}}.clone();
+{{- else}}
+		return this.{{.NameNative}};
+{{- end}}
+	}
+{{else}}
 	/**
 	 * Gets {{.String}}.
 	 * @return the value.
@@ -1205,6 +1444,107 @@ import java.nio.BufferUnderflowException;
 		this.{{.NameNative}} = value;
 		return this;
 	}
+{{end}}
+{{- if and $unsigned (eq .Type "uint64")}}
+	/**
+	 * Gets {{.String}} as an unsigned decimal.
+	 * @return the value, as in {@link Long#toUnsignedString(long)}.
+	 */
+	public String get{{title .NameNative}}Unsigned() {
+		return Long.toUnsignedString(this.{{.NameNative}});
+	}
+{{end}}{{end}}
+{{- if $immutable}}
+	/**
+	 * Gets a new builder with the Colfer zero values.
+	 * @return the builder.
+	 */
+	public static Builder builder() {
+		return new Builder();
+	}
+
+	/**
+	 * Gets a new builder with the values of this object.
+	 * @return the builder.
+	 */
+	public Builder toBuilder() {
+		Builder b = new Builder();
+		b.o = new {{$class}}(this);
+		return b;
+	}
+
+	/**
+	 * Composes immutable {@link {{$class}}} instances.
+	 */
+	public static final class Builder {
+
+		/** The values in progress. */
+		private {{$class}} o = new {{$class}}();
+
+		/** Default constructor */
+		public Builder() {}
+{{range .Fields}}
+		/**
+		 * Sets {{.String}}.
+		 * @param value the replacement.
+		 * @return {@code this}.
+		 */
+		public Builder set{{title .NameNative}}({{.TypeNative}}{{if .TypeList}}[]{{end}} value) {
+			this.o.{{.NameNative}} = value;
+			return this;
+		}
+{{end}}
+		/**
+		 * Deserializes into the builder, replacing all values.
+		 * @param buf the data source.
+		 * @param offset the initial index for {@code buf}, inclusive.
+		 * @return the final index for {@code buf}, exclusive.
+		 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link {{$class}}#colferSizeMax}{{if .HasList}} or {@link {{$class}}#colferListMax}{{end}}.
+		 * @throws InputMismatchException when the data does not match this object's schema.
+		 */
+		public int unmarshal(byte[] buf, int offset) {
+			return unmarshal(buf, offset, buf.length);
+		}
+
+		/**
+		 * Deserializes into the builder, replacing all values.
+		 * @param buf the data source.
+		 * @param offset the initial index for {@code buf}, inclusive.
+		 * @param end the index limit for {@code buf}, exclusive.
+		 * @return the final index for {@code buf}, exclusive.
+		 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link {{$class}}#colferSizeMax}{{if .HasList}} or {@link {{$class}}#colferListMax}{{end}}.
+		 * @throws InputMismatchException when the data does not match this object's schema.
+		 */
+		public int unmarshal(byte[] buf, int offset, int end) {
+			this.o = new {{$class}}();
+			return this.o.unmarshal(buf, offset, end);
+		}
+
+		/**
+		 * Deserializes into the builder, replacing all values. The position of
+		 * {@code buf} advances accordingly.
+		 * @param buf the data source.
+		 * @return the number of bytes read.
+		 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link {{$class}}#colferSizeMax}{{if .HasList}} or {@link {{$class}}#colferListMax}{{end}}.
+		 * @throws InputMismatchException when the data does not match this object's schema.
+		 */
+		public int unmarshal(ByteBuffer buf) {
+			this.o = new {{$class}}();
+			return this.o.unmarshal(buf);
+		}
+
+		/**
+		 * Gets an immutable copy of the values.
+		 * @return the new instance.
+		 */
+		public {{$class}} build() {
+			return new {{$class}}(this.o);
+		}
+
+	}
 {{end}}
 	@Override
 	public final int hashCode() {
@@ -1216,6 +1556,8 @@ import java.nio.BufferUnderflowException;
 		h = 31 * h + (this.{{.NameNative}} & 0xff);
 {{- else if eq .Type "uint16"}}
 		h = 31 * h + (this.{{.NameNative}} & 0xffff);
+{{- else if and $unsigned (eq .Type "uint32")}}
+		h = 31 * h + (int) this.{{.NameNative}};
 {{- else if eq .Type "uint32" "int32"}}
 		h = 31 * h + this.{{.NameNative}};
 {{- else if eq .Type "uint64" "int64"}}
@@ -17,6 +17,8 @@ import java.nio.charset.StandardCharsets;
 import java.util.InputMismatchException;
 import java.nio.BufferOverflowException;
 import java.nio.BufferUnderflowException;
+import java.nio.ByteBuffer;
+import java.nio.channels.ReadableByteChannel;
 
 
 /**
@@ -92,14 +94,14 @@ public class DromedaryCase implements Serializable {
 
 		/**
 		 * Deserializes the following object.
-		 * @return the result or {@code null} when EOF.
+		 * @return the result or {@code null} when no data is available,
+		 * which is either EOF or, for a non-blocking {@link ChannelUnmarshaller},
+		 * no data at the moment; see {@link ChannelUnmarshaller#isEOF()}.
 		 * @throws IOException from the input stream.
 		 * @throws SecurityException on an upper limit breach defined by {@link #colferSizeMax}.
 		 * @throws InputMismatchException when the data does not match this object's schema.
 		 */
 		public DromedaryCase next() throws IOException {
-			if (in == null) return null;
-
 			while (true) {
 				if (this.i > this.offset) {
 					try {
@@ -123,17 +125,83 @@ public class DromedaryCase implements Serializable {
 				}
 				assert this.i < this.buf.length;
 
-				int n = in.read(buf, i, buf.length - i);
+				int n = read(buf, i, buf.length - i);
 				if (n < 0) {
 					if (this.i > this.offset)
 						throw new InputMismatchException("colfer: pending data with EOF");
 					return null;
 				}
-				assert n > 0;
+				if (n == 0) return null;
 				i += n;
 			}
 		}
 
+		/**
+		 * Reads from the data source.
+		 * @param buf the destination.
+		 * @param off the initial index for {@code buf}, inclusive.
+		 * @param len the maximum number of bytes.
+		 * @return the number of bytes read, or -1 when EOF, or 0 when no
+		 * data is available at the moment.
+		 * @throws IOException from the data source.
+		 */
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (in == null) return -1;
+			return in.read(buf, off, len);
+		}
+
+	}
+
+	/**
+	 * {@link #reset(ReadableByteChannel) Reusable} deserialization of Colfer
+	 * streams from a channel. Data is read into the buffer directly. With a
+	 * non-blocking channel, {@link #next() next} may return {@code null} when
+	 * no more data is available at the moment; see {@link #isEOF()}.
+	 */
+	public static class ChannelUnmarshaller extends Unmarshaller {
+
+		/** The data source. */
+		protected ReadableByteChannel channel;
+
+		/** Whether {@link #channel} reached EOF. */
+		protected boolean eof;
+
+		/**
+		 * @param channel the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 */
+		public ChannelUnmarshaller(ReadableByteChannel channel, byte[] buf) {
+			super(null, buf);
+			reset(channel);
+		}
+
+		/**
+		 * Reuses the marshaller.
+		 * @param channel the data source or {@code null}.
+		 * @throws IllegalStateException on pending data.
+		 */
+		public void reset(ReadableByteChannel channel) {
+			reset((InputStream) null);
+			this.channel = channel;
+			this.eof = channel == null;
+		}
+
+		/**
+		 * Gets whether the data source is exhausted.
+		 * @return whether the channel reached EOF.
+		 */
+		public boolean isEOF() {
+			return this.eof;
+		}
+
+		@Override
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (this.eof) return -1;
+			int n = this.channel.read(ByteBuffer.wrap(buf, off, len));
+			if (n < 0) this.eof = true;
+			return n;
+		}
+
 	}
 
 	/**
@@ -169,6 +237,32 @@ public class DromedaryCase implements Serializable {
 		return buf;
 	}
 
+	/**
+	 * Serializes the object into the remaining space of {@code buf}, and it
+	 * advances the position accordingly. Heap buffers are written in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+	 * @param buf the data destination.
+	 * @return the number of bytes written.
+	 * @throws BufferOverflowException when {@code buf} has insufficient space remaining.
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws java.nio.ReadOnlyBufferException when {@code buf} is read-only.
+	 * @throws IllegalStateException on an upper limit breach defined by {@link #colferSizeMax}.
+	 */
+	public int marshal(ByteBuffer buf) {
+		int fit = marshalFit();
+		if (buf.hasArray() && buf.remaining() >= fit) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = marshal(buf.array(), offset) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[fit];
+		int n = marshal(a, 0);
+		buf.put(a, 0, n);
+		return n;
+	}
+
 	/**
 	 * Serializes the object.
 	 * @param buf the data destination.
@@ -250,6 +344,32 @@ public class DromedaryCase implements Serializable {
 		return unmarshal(buf, offset, buf.length);
 	}
 
+	/**
+	 * Deserializes the object from the remaining content of {@code buf}, and
+	 * it advances the position accordingly. Heap buffers are read in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+	 * @param buf the data source.
+	 * @return the number of bytes read.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws SecurityException on an upper limit breach defined by {@link #colferSizeMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(ByteBuffer buf) {
+		if (buf.hasArray()) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = unmarshal(buf.array(), offset, buf.arrayOffset() + buf.limit()) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[Math.min(buf.remaining(), DromedaryCase.colferSizeMax)];
+		buf.duplicate().get(a);
+		int n = unmarshal(a, 0);
+		buf.position(buf.position() + n);
+		return n;
+	}
+
 	/**
 	 * Deserializes the object.
 	 * @param buf the data source.
@@ -0,0 +1,116 @@
+import immutable.gen.O;
+
+import java.nio.ByteBuffer;
+import java.util.Arrays;
+import java.util.Map.Entry;
+
+import static java.nio.charset.StandardCharsets.UTF_8;
+
+
+// Immutable_test checks the immutable option against the mutable classes of
+// test, with the golden cases from there.
+public class immutable_test {
+
+	public static void main(String[] args) {
+		try {
+			marshal();
+			unmarshal();
+			byteBuffer();
+			identity();
+			copies();
+		} catch (Exception e) {
+			e.printStackTrace();
+			System.exit(1);
+		}
+
+		if (! test.testSuccess) System.exit(2);
+	}
+
+	static O parse(String hex) {
+		O.Builder b = O.builder();
+		byte[] serial = test.parseHex(hex);
+		int i = b.unmarshal(serial, 0);
+		if (i != serial.length)
+			test.fail("unmarshal: got read index %d for serial 0x%s", i, hex);
+		return b.build();
+	}
+
+	static void marshal() {
+		for (String hex : test.newGoldenCases().keySet()) {
+			O o = parse(hex);
+			byte[] buf = new byte[o.marshalFit()];
+			int n = o.marshal(buf, 0);
+			String got = test.toHex(Arrays.copyOf(buf, n));
+			if (! got.equals(hex))
+				test.fail("marshal: got serial 0x%s, want %s", got, hex);
+		}
+	}
+
+	static void unmarshal() {
+		for (Entry<String, gen.O> e : test.newGoldenCases().entrySet()) {
+			O o = parse(e.getKey());
+			gen.O want = e.getValue();
+			if (o.getU32() != want.u32 || o.getU64() != want.u64 || o.getI64() != want.i64 || ! Arrays.equals(o.getA(), want.a) || ! Arrays.equals(o.getSs(), want.ss))
+				test.fail("unmarshal: value mismatch for serial 0x%s", e.getKey());
+			if (o.hashCode() != want.hashCode())
+				test.fail("unmarshal: got hash code %d for serial 0x%s, want %d as mutable", o.hashCode(), e.getKey(), want.hashCode());
+		}
+	}
+
+	static void byteBuffer() {
+		ByteBuffer buf = ByteBuffer.allocate(64 * 1024);
+		for (String hex : test.newGoldenCases().keySet()) {
+			buf.clear();
+			parse(hex).marshal(buf);
+			buf.flip();
+			O.Builder b = O.builder();
+			b.unmarshal(buf);
+			if (buf.hasRemaining())
+				test.fail("byte buffer: %d bytes remaining for serial 0x%s", buf.remaining(), hex);
+			if (! b.build().equals(parse(hex)))
+				test.fail("byte buffer: mismatch for serial 0x%s", hex);
+		}
+	}
+
+	static void identity() {
+		if (O.builder().build().equals((Object) null))
+			test.fail("equals null Object");
+
+		for (String hex : test.newGoldenCases().keySet()) {
+			O a = parse(hex), b = parse(hex);
+			if (! a.equals(b) || a.hashCode() != b.hashCode())
+				test.fail("identity: serial 0x%s not equal", hex);
+			if (! a.toBuilder().build().equals(a))
+				test.fail("identity: serial 0x%s not equal after toBuilder", hex);
+			if (! hex.equals("7f") && a.equals(O.builder().build()))
+				test.fail("identity: serial 0x%s equals the zero value", hex);
+		}
+	}
+
+	static void copies() {
+		byte[] a = "a".getBytes(UTF_8);
+		String[] ss = {"s"};
+		byte[][] as = {"x".getBytes(UTF_8)};
+		O o = O.builder().setA(a).setSs(ss).setAs(as).build();
+
+		a[0] = 'b';
+		ss[0] = "t";
+		as[0][0] = 'y';
+		if (o.getA()[0] != 'a' || ! o.getSs()[0].equals("s") || o.getAs()[0][0] != 'x')
+			test.fail("copies: build shares arrays with the builder input");
+
+		o.getA()[0] = 'b';
+		o.getSs()[0] = "t";
+		o.getAs()[0][0] = 'y';
+		if (o.getA()[0] != 'a' || ! o.getSs()[0].equals("s") || o.getAs()[0][0] != 'x')
+			test.fail("copies: getters share arrays with the object");
+
+		O.Builder b = o.toBuilder();
+		b.setB(true);
+		if (o.getB())
+			test.fail("copies: toBuilder shares state with the object");
+		if (! b.build().getB())
+			test.fail("copies: builder lost its value");
+	}
+
+}
@@ -6,6 +6,7 @@ import java.io.ObjectInputStream;
 import java.io.ObjectOutputStream;
 import java.math.BigInteger;
 import java.nio.ByteBuffer;
+import java.nio.channels.Channels;
 import java.time.Instant;
 import java.util.Arrays;
 import java.util.LinkedHashMap;
@@ -28,6 +29,8 @@ public class test {
 			marshal();
 			unmarshal();
 			stream();
+			byteBuffer();
+			channel();
 
 			marshalMax();
 			marshalTextMax();
@@ -190,6 +193,82 @@ public class test {
 			fail("stream: data tail");
 	}
 
+	static void byteBuffer() {
+		for (ByteBuffer buf : new ByteBuffer[]{ByteBuffer.allocate(64 * 1024), ByteBuffer.allocateDirect(64 * 1024)}) {
+			String kind = buf.isDirect() ? "direct" : "heap";
+			for (Entry<String, O> e : newGoldenCases().entrySet()) {
+				buf.clear();
+				buf.put((byte) 0xaa); // offset
+				int n = e.getValue().marshal(buf);
+				byte[] serial = parseHex(e.getKey());
+				if (n != serial.length || buf.position() != 1 + n)
+					fail("%s buffer: marshal 0x%s: got %d bytes at position %d", kind, e.getKey(), n, buf.position());
+
+				buf.flip();
+				buf.get();
+				byte[] got = new byte[buf.remaining()];
+				buf.duplicate().get(got);
+				if (! Arrays.equals(got, serial))
+					fail("%s buffer: marshal 0x%s: got 0x%s", kind, e.getKey(), toHex(got));
+
+				O o = new O();
+				n = o.unmarshal(buf);
+				if (n != serial.length || buf.hasRemaining())
+					fail("%s buffer: unmarshal 0x%s: got %d bytes with %d remaining", kind, e.getKey(), n, buf.remaining());
+				if (! e.getValue().equals(o))
+					fail("%s buffer: unmarshal mismatch for serial 0x%s", kind, e.getKey());
+			}
+
+			// incomplete data
+			buf.clear();
+			buf.put(new byte[]{0x01, (byte) 0xff});
+			buf.flip();
+			try {
+				new O().unmarshal(buf);
+				fail("%s buffer: unmarshal of incomplete data: no exception", kind);
+			} catch (java.nio.BufferUnderflowException e) {
+				if (buf.position() != 0)
+					fail("%s buffer: unmarshal of incomplete data: got position %d", kind, buf.position());
+			}
+
+			// insufficient space
+			buf.clear();
+			buf.limit(2);
+			O o = new O();
+			o.s = "overflow";
+			try {
+				o.marshal(buf);
+				fail("%s buffer: marshal overflow: no exception", kind);
+			} catch (java.nio.BufferOverflowException e) {
+				if (buf.position() != 0)
+					fail("%s buffer: marshal overflow: got position %d", kind, buf.position());
+			}
+		}
+	}
+
+	static void channel() throws Exception {
+		ByteArrayOutputStream out = new ByteArrayOutputStream();
+		byte[] buf = new byte[1];
+		for (O o : newGoldenCases().values()) {
+			buf = o.marshal(out, buf);
+		}
+
+		O.ChannelUnmarshaller unmarshaller = new O.ChannelUnmarshaller(Channels.newChannel(new ByteArrayInputStream(out.toByteArray())), new byte[1]);
+		for (Entry<String, O> e : newGoldenCases().entrySet()) {
+			O got = unmarshaller.next();
+			if (got == null) {
+				fail("channel: missing as of serial 0x%s", e.getKey());
+				return;
+			}
+			if (! e.getValue().equals(got))
+				fail("channel: mismatch for serial 0x%s", e.getKey());
+		}
+		if (unmarshaller.next() != null)
+			fail("channel: data tail");
+		if (! unmarshaller.isEOF())
+			fail("channel: no EOF");
+	}
+
 	static void marshalMax() {
 		int origMax = O.colferSizeMax;
 		O.colferSizeMax = 2;
@@ -0,0 +1,101 @@
+import unsigned.gen.O;
+
+import java.util.Arrays;
+import java.util.LinkedHashMap;
+import java.util.Map;
+import java.util.Map.Entry;
+
+
+// Unsigned_test checks the unsigned option with values beyond the range of
+// the signed Java types.
+public class unsigned_test {
+
+	public static void main(String[] args) {
+		try {
+			marshal();
+			unmarshal();
+			unsignedString();
+			outOfRange();
+		} catch (Exception e) {
+			e.printStackTrace();
+			System.exit(1);
+		}
+
+		if (! test.testSuccess) System.exit(2);
+	}
+
+	static Map<String, O> newGoldenCases() {
+		Map<String, O> goldenCases = new LinkedHashMap<>();
+		newCase(goldenCases, "0e807f").u8 = 128;
+		newCase(goldenCases, "0eff7f").u8 = 255;
+		newCase(goldenCases, "0f80007f").u16 = 32768;
+		newCase(goldenCases, "0fffff7f").u16 = 65535;
+		newCase(goldenCases, "817fffffff7f").u32 = 2147483647L;
+		newCase(goldenCases, "81800000007f").u32 = 2147483648L;
+		newCase(goldenCases, "81ffffffff7f").u32 = 4294967295L;
+		newCase(goldenCases, "8280000000000000007f").u64 = Long.MIN_VALUE;
+		newCase(goldenCases, "82ffffffffffffffff7f").u64 = -1L;
+		return goldenCases;
+	}
+
+	static O newCase(Map<String, O> cases, String hex) {
+		O o = new O();
+		cases.put(hex, o);
+		return o;
+	}
+
+	static void marshal() {
+		for (Entry<String, O> e : newGoldenCases().entrySet()) {
+			byte[] buf = new byte[e.getValue().marshalFit()];
+			int n = e.getValue().marshal(buf, 0);
+			String got = test.toHex(Arrays.copyOf(buf, n));
+			if (! got.equals(e.getKey()))
+				test.fail("marshal: got serial 0x%s, want %s", got, e.getKey());
+		}
+	}
+
+	static void unmarshal() {
+		for (Entry<String, O> e : newGoldenCases().entrySet()) {
+			O o = new O();
+			byte[] serial = test.parseHex(e.getKey());
+			int i = o.unmarshal(serial, 0);
+			if (i != serial.length)
+				test.fail("unmarshal: got read index %d for serial 0x%s", i, e.getKey());
+			O want = e.getValue();
+			if (o.u8 != want.u8 || o.u16 != want.u16 || o.u32 != want.u32 || o.u64 != want.u64)
+				test.fail("unmarshal: got (%d, %d, %d, %d) for serial 0x%s, want (%d, %d, %d, %d)",
+					o.u8, o.u16, o.u32, o.u64, e.getKey(), want.u8, want.u16, want.u32, want.u64);
+		}
+	}
+
+	static void unsignedString() {
+		O o = new O();
+		o.unmarshal(test.parseHex("82ffffffffffffffff7f"), 0);
+		if (! o.getU64Unsigned().equals("18446744073709551615"))
+			test.fail("got uint64 %s, want 18446744073709551615", o.getU64Unsigned());
+		o.unmarshal(test.parseHex("8280000000000000007f"), 0);
+		if (! o.getU64Unsigned().equals("9223372036854775808"))
+			test.fail("got uint64 %s, want 9223372036854775808", o.getU64Unsigned());
+	}
+
+	static void outOfRange() {
+		O[] cases = new O[6];
+		for (int i = 0; i < cases.length; i++) cases[i] = new O();
+		cases[0].u8 = 256;
+		cases[1].u8 = -1;
+		cases[2].u16 = 65536;
+		cases[3].u16 = -1;
+		cases[4].u32 = 4294967296L;
+		cases[5].u32 = -1L;
+
+		for (O o : cases) {
+			try {
+				o.marshal(new byte[o.marshalFit()], 0);
+				test.fail("marshal: no error for (%d, %d, %d) out of range", o.u8, o.u16, o.u32);
+			} catch (IllegalStateException e) {
+				// expected
+			}
+		}
+	}
+
+}
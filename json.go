@@ -0,0 +1,200 @@
+package colfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+)
+
+// JSONPos is the interchange format of a token.Position.
+type jsonPos struct {
+	File   string `json:"file,omitempty"`
+	Offset int    `json:"offset"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func newJSONPos(pos token.Position) *jsonPos {
+	if !pos.IsValid() {
+		return nil
+	}
+	return &jsonPos{File: pos.Filename, Offset: pos.Offset, Line: pos.Line, Column: pos.Column}
+}
+
+func (p *jsonPos) position() token.Position {
+	if p == nil {
+		return token.Position{}
+	}
+	return token.Position{Filename: p.File, Offset: p.Offset, Line: p.Line, Column: p.Column}
+}
+
+// JSONPackage is the interchange format of a Package.
+type jsonPackage struct {
+	Name        string        `json:"name"`
+	Docs        []string      `json:"docs,omitempty"`
+	Structs     []*jsonStruct `json:"structs"`
+	SchemaFiles []string      `json:"schemaFiles,omitempty"`
+	SizeMax     string        `json:"sizeMax,omitempty"`
+	ListMax     string        `json:"listMax,omitempty"`
+	SuperClass  string        `json:"superClass,omitempty"`
+	Interfaces  []string      `json:"interfaces,omitempty"`
+	CodeSnippet string        `json:"codeSnippet,omitempty"`
+	Options     []string      `json:"options,omitempty"`
+	Pos         *jsonPos      `json:"pos,omitempty"`
+}
+
+// JSONStruct is the interchange format of a Struct.
+type jsonStruct struct {
+	Name       string       `json:"name"`
+	Docs       []string     `json:"docs,omitempty"`
+	Fields     []*jsonField `json:"fields"`
+	SchemaFile string       `json:"schemaFile,omitempty"`
+	TagAdd     []string     `json:"tagAdd,omitempty"`
+	Pos        *jsonPos     `json:"pos,omitempty"`
+}
+
+// JSONField is the interchange format of a Field.
+type jsonField struct {
+	Index int      `json:"index"`
+	Name  string   `json:"name"`
+	Docs  []string `json:"docs,omitempty"`
+	Type  string   `json:"type"`
+	// TypeRef is the qualified name of the Struct reference, if any.
+	TypeRef  string   `json:"typeRef,omitempty"`
+	TypeList bool     `json:"typeList,omitempty"`
+	TagAdd   []string `json:"tagAdd,omitempty"`
+	Pos      *jsonPos `json:"pos,omitempty"`
+}
+
+// MarshalJSON honors the json.Marshaler interface. Struct references are
+// encoded with their qualified name, as in <package>.<type>. Language
+// specific properties, i.e., the natives, are not included.
+func (p Packages) MarshalJSON() ([]byte, error) {
+	a := make([]*jsonPackage, len(p))
+	for i, pkg := range p {
+		jp := &jsonPackage{
+			Name:        pkg.Name,
+			Docs:        pkg.Docs,
+			Structs:     make([]*jsonStruct, len(pkg.Structs)),
+			SchemaFiles: pkg.SchemaFiles,
+			SizeMax:     pkg.SizeMax,
+			ListMax:     pkg.ListMax,
+			SuperClass:  pkg.SuperClass,
+			Interfaces:  pkg.Interfaces,
+			CodeSnippet: pkg.CodeSnippet,
+			Options:     pkg.Options,
+			Pos:         newJSONPos(pkg.Pos),
+		}
+		a[i] = jp
+
+		for j, t := range pkg.Structs {
+			jt := &jsonStruct{
+				Name:       t.Name,
+				Docs:       t.Docs,
+				Fields:     make([]*jsonField, len(t.Fields)),
+				SchemaFile: t.SchemaFile,
+				TagAdd:     t.TagAdd,
+				Pos:        newJSONPos(t.Pos),
+			}
+			jp.Structs[j] = jt
+
+			for k, f := range t.Fields {
+				jf := &jsonField{
+					Index:    f.Index,
+					Name:     f.Name,
+					Docs:     f.Docs,
+					Type:     f.Type,
+					TypeList: f.TypeList,
+					TagAdd:   f.TagAdd,
+					Pos:      newJSONPos(f.Pos),
+				}
+				if f.TypeRef != nil {
+					jf.TypeRef = f.TypeRef.String()
+				}
+				jt.Fields[k] = jf
+			}
+		}
+	}
+	return json.Marshal(a)
+}
+
+// UnmarshalJSON honors the json.Unmarshaler interface. Struct references are
+// resolved, and the parent links are set.
+func (p *Packages) UnmarshalJSON(data []byte) error {
+	var a []*jsonPackage
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+
+	packages := make(Packages, len(a))
+	for i, jp := range a {
+		pkg := &Package{
+			Name:        jp.Name,
+			Docs:        jp.Docs,
+			Structs:     make([]*Struct, len(jp.Structs)),
+			SchemaFiles: jp.SchemaFiles,
+			SizeMax:     jp.SizeMax,
+			ListMax:     jp.ListMax,
+			SuperClass:  jp.SuperClass,
+			Interfaces:  jp.Interfaces,
+			CodeSnippet: jp.CodeSnippet,
+			Options:     jp.Options,
+			Pos:         jp.Pos.position(),
+		}
+		packages[i] = pkg
+
+		for j, jt := range jp.Structs {
+			t := &Struct{
+				Pkg:        pkg,
+				Name:       jt.Name,
+				Docs:       jt.Docs,
+				Fields:     make([]*Field, len(jt.Fields)),
+				SchemaFile: jt.SchemaFile,
+				TagAdd:     jt.TagAdd,
+				Pos:        jt.Pos.position(),
+			}
+			pkg.Structs[j] = t
+
+			for k, jf := range jt.Fields {
+				t.Fields[k] = &Field{
+					Struct:   t,
+					Index:    jf.Index,
+					Name:     jf.Name,
+					Docs:     jf.Docs,
+					Type:     jf.Type,
+					TypeList: jf.TypeList,
+					TagAdd:   jf.TagAdd,
+					Pos:      jf.Pos.position(),
+				}
+			}
+		}
+	}
+
+	structs := make(map[string]*Struct)
+	for _, pkg := range packages {
+		for _, t := range pkg.Structs {
+			if _, ok := structs[t.String()]; ok {
+				return fmt.Errorf("colfer: duplicate struct definition %q", t)
+			}
+			structs[t.String()] = t
+		}
+	}
+	for i, jp := range a {
+		for j, jt := range jp.Structs {
+			for k, jf := range jt.Fields {
+				if jf.TypeRef == "" {
+					continue
+				}
+				f := packages[i].Structs[j].Fields[k]
+				ref, ok := structs[jf.TypeRef]
+				if !ok {
+					return fmt.Errorf("colfer: type reference %q of field %s not found", jf.TypeRef, f)
+				}
+				f.TypeRef = ref
+			}
+		}
+	}
+
+	*p = packages
+	return nil
+}
@@ -0,0 +1,216 @@
+package colfer
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"math"
+	"strings"
+
+	"github.com/pascaldekloe/name"
+)
+
+// JSONSchemaDialect is the JSON Schema version in use, which is also the
+// dialect of OpenAPI 3.1.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateJSONSchemaFiles returns a JSON Schema with the definitions of each
+// package in a "<package>.schema.json" file. The JSON representation follows
+// encoding/json on the Go code: timestamps are RFC 3339 strings and binaries
+// are base64 strings. Properties have the field names from the Go code, and
+// data structure references, lists and binaries may be null, as with nil.
+func GenerateJSONSchemaFiles(packages Packages) (Files, error) {
+	files := make(Files, len(packages))
+	for _, p := range packages {
+		defs := make(map[string]interface{}, len(p.Structs))
+		for _, t := range p.Structs {
+			defs[t.Name] = jsonSchemaStruct(t, func(ref *Struct) string {
+				if ref.Pkg == p {
+					return "#/$defs/" + ref.Name
+				}
+				return relativePath(p.Name, ref.Pkg.Name) + ".schema.json#/$defs/" + ref.Name
+			})
+		}
+
+		doc := map[string]interface{}{
+			"$schema": jsonSchemaDialect,
+			"$defs":   defs,
+		}
+		if text := p.DocText(""); text != "" {
+			doc["description"] = text
+		}
+
+		data, err := json.MarshalIndent(doc, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		files[p.Name+".schema.json"] = append(data, '\n')
+	}
+	return files, nil
+}
+
+// GenerateOpenAPIFiles returns the components of an OpenAPI 3.1 document, with
+// the definitions of GenerateJSONSchemaFiles, in file "openapi.json". Schema
+// names are qualified, as in <package>.<type>, with dots for any slashes in
+// the package name.
+func GenerateOpenAPIFiles(packages Packages) (Files, error) {
+	qName := func(t *Struct) string {
+		return strings.Replace(t.Pkg.Name, "/", ".", -1) + "." + t.Name
+	}
+
+	schemas := make(map[string]interface{})
+	var names []string
+	for _, p := range packages {
+		names = append(names, p.Name)
+		for _, t := range p.Structs {
+			schemas[qName(t)] = jsonSchemaStruct(t, func(ref *Struct) string {
+				return "#/components/schemas/" + qName(ref)
+			})
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": jsonSchemaDialect,
+		"info": map[string]interface{}{
+			"title":   "Colfer packages " + strings.Join(names, ", "),
+			"version": "1",
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+
+	data, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return Files{"openapi.json": append(data, '\n')}, nil
+}
+
+// RelativePath returns the slash-separated path from the directory of a
+// package to another package.
+func relativePath(from, to string) string {
+	fromDirs := strings.Split(from, "/")
+	fromDirs = fromDirs[:len(fromDirs)-1]
+	toDirs := strings.Split(to, "/")
+	for len(fromDirs) != 0 && len(toDirs) > 1 && fromDirs[0] == toDirs[0] {
+		fromDirs, toDirs = fromDirs[1:], toDirs[1:]
+	}
+	return strings.Repeat("../", len(fromDirs)) + strings.Join(toDirs, "/")
+}
+
+// JSONSchemaStruct returns the definition of t. The refPath function resolves
+// data structure references.
+func jsonSchemaStruct(t *Struct, refPath func(*Struct) string) map[string]interface{} {
+	sizeMax, sizeOK := evalLimit(t.Pkg.SizeMax)
+	listMax, listOK := evalLimit(t.Pkg.ListMax)
+
+	props := make(map[string]interface{}, len(t.Fields))
+	for _, f := range t.Fields {
+		var prop map[string]interface{}
+		switch f.Type {
+		case "bool":
+			prop = map[string]interface{}{"type": "boolean"}
+		case "uint8", "uint16", "uint32", "uint64":
+			max := map[string]uint64{"uint8": math.MaxUint8, "uint16": math.MaxUint16, "uint32": math.MaxUint32, "uint64": math.MaxUint64}[f.Type]
+			prop = map[string]interface{}{"type": "integer", "minimum": 0, "maximum": max}
+		case "int32":
+			prop = map[string]interface{}{"type": "integer", "format": "int32", "minimum": math.MinInt32, "maximum": math.MaxInt32}
+		case "int64":
+			prop = map[string]interface{}{"type": "integer", "format": "int64", "minimum": int64(math.MinInt64), "maximum": int64(math.MaxInt64)}
+		case "float32":
+			prop = map[string]interface{}{"type": "number", "format": "float"}
+		case "float64":
+			prop = map[string]interface{}{"type": "number", "format": "double"}
+		case "timestamp":
+			prop = map[string]interface{}{"type": "string", "format": "date-time"}
+		case "text":
+			prop = map[string]interface{}{"type": "string"}
+			if sizeOK {
+				// characters take at least one byte
+				prop["maxLength"] = sizeMax
+			}
+		case "binary":
+			prop = map[string]interface{}{"type": []string{"string", "null"}, "contentEncoding": "base64"}
+			if sizeOK {
+				prop["maxLength"] = (sizeMax + 2) / 3 * 4
+			}
+		default:
+			prop = map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"$ref": refPath(f.TypeRef)},
+				map[string]interface{}{"type": "null"},
+			}}
+		}
+
+		if f.TypeList {
+			prop = map[string]interface{}{"type": []string{"array", "null"}, "items": prop}
+			if listOK {
+				prop["maxItems"] = listMax
+			}
+		}
+		if text := f.DocText(""); text != "" {
+			prop["description"] = text
+		}
+		// conform the field names of GenerateGo
+		props[name.CamelCase(f.Name, true)] = prop
+	}
+
+	def := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if text := t.DocText(""); text != "" {
+		def["description"] = text
+	}
+	return def
+}
+
+// EvalLimit returns the value of a SizeMax or ListMax expression, when it is
+// an integer constant.
+func evalLimit(expr string) (int64, bool) {
+	if expr == "" {
+		return 0, false
+	}
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		return 0, false
+	}
+	v := evalConstant(x)
+	if v.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(v)
+}
+
+func evalConstant(x ast.Expr) constant.Value {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			return constant.MakeFromLiteral(x.Value, x.Kind, 0)
+		}
+	case *ast.ParenExpr:
+		return evalConstant(x.X)
+	case *ast.BinaryExpr:
+		a, b := evalConstant(x.X), evalConstant(x.Y)
+		if a.Kind() != constant.Int || b.Kind() != constant.Int {
+			break
+		}
+		switch x.Op {
+		case token.ADD, token.SUB, token.MUL:
+			return constant.BinaryOp(a, x.Op, b)
+		case token.QUO:
+			if constant.Sign(b) != 0 {
+				return constant.BinaryOp(a, token.QUO_ASSIGN, b)
+			}
+		case token.SHL:
+			if s, ok := constant.Uint64Val(b); ok && s < 64 {
+				return constant.Shift(a, x.Op, uint(s))
+			}
+		}
+	}
+	return constant.MakeUnknown()
+}
@@ -0,0 +1,802 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pascaldekloe/name"
+)
+
+// KotlinKeywords are the hard keywords of Kotlin, which need backticks as an
+// identifier.
+var kotlinKeywords = map[string]struct{}{
+	"as": {}, "break": {}, "class": {}, "continue": {},
+	"do": {}, "else": {}, "false": {}, "for": {},
+	"fun": {}, "if": {}, "in": {}, "interface": {},
+	"is": {}, "null": {}, "object": {}, "package": {},
+	"return": {}, "super": {}, "this": {}, "throw": {},
+	"true": {}, "try": {}, "typealias": {}, "typeof": {},
+	"val": {}, "var": {}, "when": {}, "while": {},
+}
+
+// KotlinIdent returns s with backticks when needed.
+func kotlinIdent(s string) string {
+	if _, ok := kotlinKeywords[s]; ok {
+		return "`" + s + "`"
+	}
+	return s
+}
+
+// GenerateKotlin writes the code into the respective ".kt" files.
+func GenerateKotlin(basedir string, packages Packages) error {
+	files, err := GenerateKotlinFiles(packages)
+	if err != nil {
+		return err
+	}
+	return files.Write(basedir)
+}
+
+// GenerateKotlinFiles returns the code of GenerateKotlin. The serials are
+// identical to the Java output.
+func GenerateKotlinFiles(packages Packages) (Files, error) {
+	codeTemplate := template.New("kotlin-code").Funcs(template.FuncMap{
+		"type": kotlinType,
+		"zero": kotlinZero,
+	})
+	template.Must(codeTemplate.Parse(kotlinCode))
+
+	for _, p := range packages {
+		segments := strings.Split(strings.ReplaceAll(p.Name, "/", "."), ".")
+		for i, s := range segments {
+			segments[i] = kotlinIdent(s)
+		}
+		p.NameNative = strings.Join(segments, ".")
+
+		p.InterfaceNatives = make([]string, len(p.Interfaces))
+		for i, s := range p.Interfaces {
+			p.InterfaceNatives[i] = strings.ReplaceAll(s, "/", ".")
+		}
+
+		for _, t := range p.Structs {
+			t.NameNative = name.CamelCase(t.Name, true)
+			for _, f := range t.Fields {
+				f.NameNative = kotlinIdent(name.CamelCase(f.Name, false))
+			}
+		}
+	}
+
+	files := make(Files)
+	for _, p := range packages {
+		pkgdir := strings.ReplaceAll(p.Name, ".", "/")
+
+		for _, t := range p.Structs {
+			for _, f := range t.Fields {
+				switch f.Type {
+				default:
+					f.TypeNative = f.TypeRef.NameNative
+					if f.TypeRef.Pkg != p {
+						f.TypeNative = f.TypeRef.Pkg.NameNative + "." + f.TypeNative
+					}
+				case "bool":
+					f.TypeNative = "Boolean"
+				case "uint8":
+					f.TypeNative = "UByte"
+				case "uint16":
+					f.TypeNative = "UShort"
+				case "uint32":
+					f.TypeNative = "UInt"
+				case "uint64":
+					f.TypeNative = "ULong"
+				case "int32":
+					f.TypeNative = "Int"
+				case "int64":
+					f.TypeNative = "Long"
+				case "float32":
+					f.TypeNative = "Float"
+				case "float64":
+					f.TypeNative = "Double"
+				case "timestamp":
+					f.TypeNative = "java.time.Instant"
+				case "text":
+					f.TypeNative = "String"
+				case "binary":
+					f.TypeNative = "ByteArray"
+				}
+				if f.TypeList && (f.Type == "int32" || f.Type == "int64") {
+					return nil, fmt.Errorf("colfer: Kotlin does not support integer lists; field %s", f)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := codeTemplate.Execute(&buf, t); err != nil {
+				return nil, err
+			}
+			files[pkgdir+"/"+t.NameNative+".kt"] = buf.Bytes()
+		}
+	}
+	return files, nil
+}
+
+// KotlinType returns the declaration type of f.
+func kotlinType(f *Field) string {
+	if f.TypeList {
+		switch f.Type {
+		case "float32":
+			return "FloatArray"
+		case "float64":
+			return "DoubleArray"
+		default:
+			return "List<" + f.TypeNative + ">"
+		}
+	}
+	if f.Type == "timestamp" || f.TypeRef != nil {
+		return f.TypeNative + "?"
+	}
+	return f.TypeNative
+}
+
+// KotlinZero returns the default value of f.
+func kotlinZero(f *Field) string {
+	if f.TypeList {
+		switch f.Type {
+		case "float32":
+			return "FloatArray(0)"
+		case "float64":
+			return "DoubleArray(0)"
+		default:
+			return "emptyList()"
+		}
+	}
+	switch f.Type {
+	case "bool":
+		return "false"
+	case "uint8", "uint16", "uint32":
+		return "0u"
+	case "uint64":
+		return "0uL"
+	case "int32":
+		return "0"
+	case "int64":
+		return "0L"
+	case "float32":
+		return "0f"
+	case "float64":
+		return "0.0"
+	case "text":
+		return `""`
+	case "binary":
+		return "ByteArray(0)"
+	default:
+		return "null"
+	}
+}
+
+const kotlinCode = `// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.Pkg.SchemaFileList}}.
+
+package {{.Pkg.NameNative}}
+
+import java.nio.BufferOverflowException
+import java.nio.BufferUnderflowException
+import java.util.InputMismatchException
+
+/**
+ * Data class with built-in serialization support.
+{{.DocText " * "}}
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+{{- range .TagAdd}}
+{{.}}
+{{- end}}
+{{$class := .NameNative}}{{if .Fields}}data {{end}}class {{$class}}(
+{{- range .Fields}}
+{{- if .Docs}}
+	/**
+{{.DocText "\t * "}}
+	 */
+{{- end}}
+{{- range .TagAdd}}
+	{{.}}
+{{- end}}
+	val {{.NameNative}}: {{type .}} = {{zero .}},
+{{- end}}
+){{if .Pkg.InterfaceNatives}} :{{range $i, $e := .Pkg.InterfaceNatives}}{{if $i}},{{end}} {{$e}}{{end}}{{end}} {
+{{if .Pkg.CodeSnippet}}
+	// BEGIN Code Snippet Injection
+
+{{.Pkg.CodeSnippet}}
+	// END Code Snippet Injection
+{{end}}
+	companion object {
+
+		/** The upper limit for serial byte sizes. */
+		@JvmStatic
+		var colferSizeMax: Int = {{.Pkg.SizeMax}}
+{{- if .HasList}}
+
+		/** The upper limit for the number of elements in a list. */
+		@JvmStatic
+		var colferListMax: Int = {{.Pkg.ListMax}}
+{{- end}}
+
+		/**
+		 * Deserializes an object.
+		 * @param buf the data source.
+		 * @param offset the initial index for buf, inclusive.
+		 * @param end the index limit for buf, exclusive.
+		 * @return the object, with the final index for buf, exclusive.
+		 * @throws BufferUnderflowException when buf is incomplete. (EOF)
+		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} colferSizeMax{{if .HasList}} or colferListMax{{end}}.
+		 * @throws InputMismatchException when the data does not match this object's schema.
+		 */
+		@JvmStatic
+		@JvmOverloads
+		fun unmarshal(buf: ByteArray, offset: Int = 0, end: Int = buf.size): Pair<{{$class}}, Int> {
+			val limit = minOf(end, buf.size)
+			var i = offset
+{{- range .Fields}}
+			var v{{.Index}}: {{type .}} = {{zero .}}
+{{- end}}
+
+			try {
+				var header = buf[i++].toInt() and 0xff
+{{range .Fields}}{{if eq .Type "bool"}}
+				if (header == {{.Index}}) {
+					v{{.Index}} = true
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "uint8"}}
+				if (header == {{.Index}}) {
+					v{{.Index}} = buf[i++].toUByte()
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "uint16"}}
+				if (header == {{.Index}}) {
+					v{{.Index}} = ((buf[i++].toInt() and 0xff) shl 8 or (buf[i++].toInt() and 0xff)).toUShort()
+					header = buf[i++].toInt() and 0xff
+				} else if (header == ({{.Index}} or 0x80)) {
+					v{{.Index}} = buf[i++].toUByte().toUShort()
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "uint32" "int32"}}
+				if (header == {{.Index}}) {
+					var x = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						x = x or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					v{{.Index}} = x{{if eq .Type "uint32"}}.toUInt(){{end}}
+					header = buf[i++].toInt() and 0xff
+				} else if (header == ({{.Index}} or 0x80)) {
+{{- if eq .Type "uint32"}}
+					v{{.Index}} = ((buf[i++].toInt() and 0xff) shl 24 or (buf[i++].toInt() and 0xff shl 16) or (buf[i++].toInt() and 0xff shl 8) or (buf[i++].toInt() and 0xff)).toUInt()
+{{- else}}
+					var x = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						x = x or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					v{{.Index}} = -x
+{{- end}}
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "uint64" "int64"}}
+				if (header == {{.Index}}) {
+					var x = 0L
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toLong()
+						if (shift == 56 || b >= 0) {
+							x = x or (b and 0xffL shl shift)
+							break
+						}
+						x = x or (b and 0x7fL shl shift)
+						shift += 7
+					}
+					v{{.Index}} = x{{if eq .Type "uint64"}}.toULong(){{end}}
+					header = buf[i++].toInt() and 0xff
+				} else if (header == ({{.Index}} or 0x80)) {
+{{- if eq .Type "uint64"}}
+					var x = 0L
+					for (n in 0 until 8) x = x shl 8 or (buf[i++].toLong() and 0xffL)
+					v{{.Index}} = x.toULong()
+{{- else}}
+					var x = 0L
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toLong()
+						if (shift == 56 || b >= 0) {
+							x = x or (b and 0xffL shl shift)
+							break
+						}
+						x = x or (b and 0x7fL shl shift)
+						shift += 7
+					}
+					v{{.Index}} = -x
+{{- end}}
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "float32" "float64"}}
+				if (header == {{.Index}}) {
+{{- if .TypeList}}
+					var length = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						length = length or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					if (length < 0 || length > colferListMax)
+						throw SecurityException("colfer: {{.String}} length $length exceeds $colferListMax elements")
+
+					val a = {{if eq .Type "float32"}}FloatArray{{else}}DoubleArray{{end}}(length)
+					for (ai in 0 until length) {
+{{- if eq .Type "float32"}}
+						var x = 0
+						for (n in 0 until 4) x = x shl 8 or (buf[i++].toInt() and 0xff)
+						a[ai] = Float.fromBits(x)
+{{- else}}
+						var x = 0L
+						for (n in 0 until 8) x = x shl 8 or (buf[i++].toLong() and 0xffL)
+						a[ai] = Double.fromBits(x)
+{{- end}}
+					}
+					v{{.Index}} = a
+{{- else if eq .Type "float32"}}
+					var x = 0
+					for (n in 0 until 4) x = x shl 8 or (buf[i++].toInt() and 0xff)
+					v{{.Index}} = Float.fromBits(x)
+{{- else}}
+					var x = 0L
+					for (n in 0 until 8) x = x shl 8 or (buf[i++].toLong() and 0xffL)
+					v{{.Index}} = Double.fromBits(x)
+{{- end}}
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "timestamp"}}
+				if (header == {{.Index}}) {
+					var s = 0L
+					for (n in 0 until 4) s = s shl 8 or (buf[i++].toLong() and 0xffL)
+					var ns = 0L
+					for (n in 0 until 4) ns = ns shl 8 or (buf[i++].toLong() and 0xffL)
+					v{{.Index}} = java.time.Instant.ofEpochSecond(s, ns)
+					header = buf[i++].toInt() and 0xff
+				} else if (header == ({{.Index}} or 0x80)) {
+					var s = 0L
+					for (n in 0 until 8) s = s shl 8 or (buf[i++].toLong() and 0xffL)
+					var ns = 0L
+					for (n in 0 until 4) ns = ns shl 8 or (buf[i++].toLong() and 0xffL)
+					v{{.Index}} = java.time.Instant.ofEpochSecond(s, ns)
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if eq .Type "text" "binary"}}
+				if (header == {{.Index}}) {
+{{- if .TypeList}}
+					var length = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						length = length or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					if (length < 0 || length > colferListMax)
+						throw SecurityException("colfer: {{.String}} length $length exceeds $colferListMax elements")
+
+					val a = ArrayList<{{.TypeNative}}>(length)
+					for (ai in 0 until length) {
+						var size = 0
+						shift = 0
+						while (true) {
+							val b = buf[i++].toInt()
+							size = size or (b and 0x7f shl shift)
+							if (shift == 28 || b >= 0) break
+							shift += 7
+						}
+						if (size < 0 || size > colferSizeMax)
+							throw SecurityException("colfer: {{.String}}[$ai] size $size exceeds $colferSizeMax {{if eq .Type "text"}}UTF-8 {{end}}bytes")
+
+						val start = i
+						i += size
+{{- if eq .Type "text"}}
+						a.add(String(buf, start, size, Charsets.UTF_8))
+{{- else}}
+						a.add(buf.copyOfRange(start, i))
+{{- end}}
+					}
+					v{{.Index}} = a
+{{- else}}
+					var size = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						size = size or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					if (size < 0 || size > colferSizeMax)
+						throw SecurityException("colfer: {{.String}} size $size exceeds $colferSizeMax {{if eq .Type "text"}}UTF-8 {{end}}bytes")
+
+					val start = i
+					i += size
+{{- if eq .Type "text"}}
+					v{{.Index}} = String(buf, start, size, Charsets.UTF_8)
+{{- else}}
+					v{{.Index}} = buf.copyOfRange(start, i)
+{{- end}}
+{{- end}}
+					header = buf[i++].toInt() and 0xff
+				}
+{{else if .TypeList}}
+				if (header == {{.Index}}) {
+					var length = 0
+					var shift = 0
+					while (true) {
+						val b = buf[i++].toInt()
+						length = length or (b and 0x7f shl shift)
+						if (shift == 28 || b >= 0) break
+						shift += 7
+					}
+					if (length < 0 || length > colferListMax)
+						throw SecurityException("colfer: {{.String}} length $length exceeds $colferListMax elements")
+
+					val a = ArrayList<{{.TypeNative}}>(length)
+					for (ai in 0 until length) {
+						val (o, next) = {{.TypeNative}}.unmarshal(buf, i, limit)
+						a.add(o)
+						i = next
+					}
+					v{{.Index}} = a
+					header = buf[i++].toInt() and 0xff
+				}
+{{else}}
+				if (header == {{.Index}}) {
+					val (o, next) = {{.TypeNative}}.unmarshal(buf, i, limit)
+					v{{.Index}} = o
+					i = next
+					header = buf[i++].toInt() and 0xff
+				}
+{{end}}{{end}}
+				if (header != 0x7f)
+					throw InputMismatchException("colfer: unknown header at byte ${i - 1}")
+			} finally {
+				if (i > limit && limit - offset < colferSizeMax) throw BufferUnderflowException()
+				if (i < 0 || i - offset > colferSizeMax)
+					throw SecurityException("colfer: {{.String}} exceeds $colferSizeMax bytes")
+				if (i > limit) throw BufferUnderflowException()
+			}
+
+			return Pair({{$class}}(
+{{- range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f.NameNative}} = v{{$f.Index}}{{end}}), i)
+		}
+
+	}
+
+	/**
+	 * Gets the serial size estimate as an upper boundary, whereby
+	 * marshal(ByteArray, Int) ≤ marshalFit() ≤ colferSizeMax.
+	 * @return the number of bytes.
+	 */
+	fun marshalFit(): Int {
+		var n = 1L
+{{- range .Fields}}
+{{- if eq .Type "bool"}}
+		n += 1
+{{- else if eq .Type "uint8"}}
+		n += 2
+{{- else if eq .Type "uint16"}}
+		n += 3
+{{- else if eq .Type "uint32"}}
+		n += 5
+{{- else if eq .Type "uint64"}}
+		n += 9
+{{- else if eq .Type "int32"}}
+		n += 6
+{{- else if eq .Type "int64"}}
+		n += 10
+{{- else if eq .Type "float32"}}
+		n += {{if .TypeList}}6 + this.{{.NameNative}}.size * 4L{{else}}5{{end}}
+{{- else if eq .Type "float64"}}
+		n += {{if .TypeList}}6 + this.{{.NameNative}}.size * 8L{{else}}9{{end}}
+{{- else if eq .Type "timestamp"}}
+		n += 13
+{{- else if eq .Type "text"}}
+ {{- if .TypeList}}
+		n += 6 + this.{{.NameNative}}.size * 6L
+		for (s in this.{{.NameNative}}) n += s.length * 3L
+ {{- else}}
+		n += 6 + this.{{.NameNative}}.length * 3L
+ {{- end}}
+{{- else if eq .Type "binary"}}
+ {{- if .TypeList}}
+		n += 6 + this.{{.NameNative}}.size * 6L
+		for (a in this.{{.NameNative}}) n += a.size
+ {{- else}}
+		n += 6 + this.{{.NameNative}}.size
+ {{- end}}
+{{- else if .TypeList}}
+		n += 6
+		for (o in this.{{.NameNative}}) n += o.marshalFit()
+{{- else}}
+		this.{{.NameNative}}?.let { n += 1 + it.marshalFit() }
+{{- end}}
+{{- end}}
+		if (n < 0 || n > colferSizeMax) return colferSizeMax
+		return n.toInt()
+	}
+
+	/**
+	 * Serializes the object.
+	 * @return the serial.
+	 * @throws IllegalStateException on an upper limit breach defined by{{if .HasList}} either{{end}} colferSizeMax{{if .HasList}} or colferListMax{{end}}.
+	 */
+	fun marshal(): ByteArray {
+		val buf = ByteArray(marshalFit())
+		return buf.copyOf(marshal(buf, 0))
+	}
+
+	/**
+	 * Serializes the object.
+	 * @param buf the data destination.
+	 * @param offset the initial index for buf, inclusive.
+	 * @return the final index for buf, exclusive.
+	 * @throws BufferOverflowException when buf is too small.
+	 * @throws IllegalStateException on an upper limit breach defined by{{if .HasList}} either{{end}} colferSizeMax{{if .HasList}} or colferListMax{{end}}.
+	 */
+	@JvmOverloads
+	fun marshal(buf: ByteArray, offset: Int = 0): Int {
+		var i = offset
+
+		try {
+{{- range .Fields}}{{if eq .Type "bool"}}
+			if (this.{{.NameNative}}) {
+				buf[i++] = {{.Index}}.toByte()
+			}
+{{else if eq .Type "uint8"}}
+			if (this.{{.NameNative}}.toInt() != 0) {
+				buf[i++] = {{.Index}}.toByte()
+				buf[i++] = this.{{.NameNative}}.toByte()
+			}
+{{else if eq .Type "uint16"}}
+			if (this.{{.NameNative}}.toInt() != 0) {
+				val x = this.{{.NameNative}}.toInt()
+				if (x and 0xff00 != 0) {
+					buf[i++] = {{.Index}}.toByte()
+					buf[i++] = (x ushr 8).toByte()
+				} else {
+					buf[i++] = ({{.Index}} or 0x80).toByte()
+				}
+				buf[i++] = x.toByte()
+			}
+{{else if eq .Type "uint32"}}
+			if (this.{{.NameNative}} != 0u) {
+				var x = this.{{.NameNative}}.toInt()
+				if (x and ((1 shl 21) - 1).inv() != 0) {
+					buf[i++] = ({{.Index}} or 0x80).toByte()
+					buf[i++] = (x ushr 24).toByte()
+					buf[i++] = (x ushr 16).toByte()
+					buf[i++] = (x ushr 8).toByte()
+				} else {
+					buf[i++] = {{.Index}}.toByte()
+					while (x > 0x7f) {
+						buf[i++] = (x or 0x80).toByte()
+						x = x ushr 7
+					}
+				}
+				buf[i++] = x.toByte()
+			}
+{{else if eq .Type "uint64"}}
+			if (this.{{.NameNative}} != 0uL) {
+				var x = this.{{.NameNative}}.toLong()
+				if (x and ((1L shl 49) - 1).inv() != 0L) {
+					buf[i++] = ({{.Index}} or 0x80).toByte()
+					for (shift in 56 downTo 0 step 8) buf[i++] = (x ushr shift).toByte()
+				} else {
+					buf[i++] = {{.Index}}.toByte()
+					while (x > 0x7fL) {
+						buf[i++] = (x or 0x80L).toByte()
+						x = x ushr 7
+					}
+					buf[i++] = x.toByte()
+				}
+			}
+{{else if eq .Type "int32"}}
+			if (this.{{.NameNative}} != 0) {
+				var x = this.{{.NameNative}}
+				if (x < 0) {
+					x = -x
+					buf[i++] = ({{.Index}} or 0x80).toByte()
+				} else {
+					buf[i++] = {{.Index}}.toByte()
+				}
+				while (x and 0x7f.inv() != 0) {
+					buf[i++] = (x or 0x80).toByte()
+					x = x ushr 7
+				}
+				buf[i++] = x.toByte()
+			}
+{{else if eq .Type "int64"}}
+			if (this.{{.NameNative}} != 0L) {
+				var x = this.{{.NameNative}}
+				if (x < 0) {
+					x = -x
+					buf[i++] = ({{.Index}} or 0x80).toByte()
+				} else {
+					buf[i++] = {{.Index}}.toByte()
+				}
+				var n = 0
+				while (n < 8 && x and 0x7fL.inv() != 0L) {
+					buf[i++] = (x or 0x80L).toByte()
+					x = x ushr 7
+					n++
+				}
+				buf[i++] = x.toByte()
+			}
+{{else if eq .Type "float32" "float64"}}
+ {{- if .TypeList}}
+			if (this.{{.NameNative}}.isNotEmpty()) {
+				buf[i++] = {{.Index}}.toByte()
+				val a = this.{{.NameNative}}
+
+				var x = a.size
+				if (x > colferListMax)
+					throw IllegalStateException("colfer: {{.String}} length $x exceeds $colferListMax elements")
+				while (x > 0x7f) {
+					buf[i++] = (x or 0x80).toByte()
+					x = x ushr 7
+				}
+				buf[i++] = x.toByte()
+
+				for (f in a) {
+					val bits = f.toRawBits()
+					for (shift in {{if eq .Type "float32"}}24{{else}}56{{end}} downTo 0 step 8) buf[i++] = (bits ushr shift).toByte()
+				}
+			}
+ {{- else}}
+			if (this.{{.NameNative}} != {{zero .}}) {
+				buf[i++] = {{.Index}}.toByte()
+				val bits = this.{{.NameNative}}.toRawBits()
+				for (shift in {{if eq .Type "float32"}}24{{else}}56{{end}} downTo 0 step 8) buf[i++] = (bits ushr shift).toByte()
+			}
+ {{- end}}
+{{else if eq .Type "timestamp"}}
+			this.{{.NameNative}}?.let {
+				val s = it.epochSecond
+				val ns = it.nano
+				if (s != 0L || ns != 0) {
+					if (s >= 0 && s < (1L shl 32)) {
+						buf[i++] = {{.Index}}.toByte()
+						for (shift in 24 downTo 0 step 8) buf[i++] = (s ushr shift).toByte()
+					} else {
+						buf[i++] = ({{.Index}} or 0x80).toByte()
+						for (shift in 56 downTo 0 step 8) buf[i++] = (s ushr shift).toByte()
+					}
+					for (shift in 24 downTo 0 step 8) buf[i++] = (ns ushr shift).toByte()
+				}
+			}
+{{else if eq .Type "text" "binary"}}
+ {{- if .TypeList}}
+			if (this.{{.NameNative}}.isNotEmpty()) {
+				buf[i++] = {{.Index}}.toByte()
+				val a = this.{{.NameNative}}
+
+				var x = a.size
+				if (x > colferListMax)
+					throw IllegalStateException("colfer: {{.String}} length $x exceeds $colferListMax elements")
+				while (x > 0x7f) {
+					buf[i++] = (x or 0x80).toByte()
+					x = x ushr 7
+				}
+				buf[i++] = x.toByte()
+
+				for ((ai, e) in a.withIndex()) {
+					val b = {{if eq .Type "text"}}e.toByteArray(Charsets.UTF_8){{else}}e{{end}}
+					if (b.size > colferSizeMax)
+						throw IllegalStateException("colfer: {{.String}}[$ai] size ${b.size} exceeds $colferSizeMax {{if eq .Type "text"}}UTF-8 {{end}}bytes")
+
+					x = b.size
+					while (x > 0x7f) {
+						buf[i++] = (x or 0x80).toByte()
+						x = x ushr 7
+					}
+					buf[i++] = x.toByte()
+
+					val start = i
+					i += b.size
+					b.copyInto(buf, start)
+				}
+			}
+ {{- else}}
+			if (this.{{.NameNative}}.isNotEmpty()) {
+				buf[i++] = {{.Index}}.toByte()
+				val b = {{if eq .Type "text"}}this.{{.NameNative}}.toByteArray(Charsets.UTF_8){{else}}this.{{.NameNative}}{{end}}
+				if (b.size > colferSizeMax)
+					throw IllegalStateException("colfer: {{.String}} size ${b.size} exceeds $colferSizeMax {{if eq .Type "text"}}UTF-8 {{end}}bytes")
+
+				var x = b.size
+				while (x > 0x7f) {
+					buf[i++] = (x or 0x80).toByte()
+					x = x ushr 7
+				}
+				buf[i++] = x.toByte()
+
+				val start = i
+				i += b.size
+				b.copyInto(buf, start)
+			}
+ {{- end}}
+{{else if .TypeList}}
+			if (this.{{.NameNative}}.isNotEmpty()) {
+				buf[i++] = {{.Index}}.toByte()
+				val a = this.{{.NameNative}}
+
+				var x = a.size
+				if (x > colferListMax)
+					throw IllegalStateException("colfer: {{.String}} length $x exceeds $colferListMax elements")
+				while (x > 0x7f) {
+					buf[i++] = (x or 0x80).toByte()
+					x = x ushr 7
+				}
+				buf[i++] = x.toByte()
+
+				for (o in a) i = o.marshal(buf, i)
+			}
+{{else}}
+			this.{{.NameNative}}?.let {
+				buf[i++] = {{.Index}}.toByte()
+				i = it.marshal(buf, i)
+			}
+{{end}}{{end}}
+			buf[i++] = 0x7f
+		} catch (e: IndexOutOfBoundsException) {
+			if (i - offset > colferSizeMax)
+				throw IllegalStateException("colfer: {{.String}} exceeds $colferSizeMax bytes")
+			throw BufferOverflowException()
+		}
+		if (i - offset > colferSizeMax)
+			throw IllegalStateException("colfer: {{.String}} exceeds $colferSizeMax bytes")
+		return i
+	}
+
+	override fun equals(other: Any?): Boolean {
+		if (this === other) return true
+		if (other !is {{$class}}) return false
+
+		return {{if not .Fields}}true{{end}}{{range $i, $f := .Fields}}
+{{- if $i}} &&
+			{{end}}
+{{- if and .TypeList (eq .Type "binary")}}this.{{.NameNative}}.size == other.{{.NameNative}}.size && this.{{.NameNative}}.indices.all { this.{{.NameNative}}[it].contentEquals(other.{{.NameNative}}[it]) }
+{{- else if or .TypeList (eq .Type "binary")}}
+ {{- if or (eq .Type "float32" "float64" "binary")}}this.{{.NameNative}}.contentEquals(other.{{.NameNative}})
+ {{- else}}this.{{.NameNative}} == other.{{.NameNative}}
+ {{- end}}
+{{- else if eq .Type "float32" "float64"}}(this.{{.NameNative}} == other.{{.NameNative}} || (this.{{.NameNative}}.isNaN() && other.{{.NameNative}}.isNaN()))
+{{- else}}this.{{.NameNative}} == other.{{.NameNative}}
+{{- end}}{{end}}
+	}
+
+	override fun hashCode(): Int {
+		var h = 1
+{{- range .Fields}}
+{{- if and .TypeList (eq .Type "binary")}}
+		for (b in this.{{.NameNative}}) h = 31 * h + b.contentHashCode()
+{{- else if or (and .TypeList (eq .Type "float32" "float64")) (eq .Type "binary")}}
+		h = 31 * h + this.{{.NameNative}}.contentHashCode()
+{{- else}}
+		h = 31 * h + this.{{.NameNative}}.hashCode()
+{{- end}}
+{{- end}}
+		return h
+	}
+
+}
+`
@@ -0,0 +1,167 @@
+import gen.O
+
+import java.nio.BufferUnderflowException
+import java.time.Instant
+import java.util.InputMismatchException
+import kotlin.system.exitProcess
+
+var testSuccess = true
+
+fun fail(message: String) {
+	System.err.println(message)
+	testSuccess = false
+}
+
+fun main() {
+	identity()
+	values()
+	marshal()
+	unmarshal()
+	unmarshalIncomplete()
+	marshalMax()
+	unmarshalMismatch()
+
+	if (!testSuccess) exitProcess(2)
+}
+
+// The serials are shared with the Java tests.
+fun newGoldenCases(): Map<String, O> = linkedMapOf(
+	"7f" to O(),
+	"007f" to O(b = true),
+	"01017f" to O(u32 = 1u),
+	"01ff017f" to O(u32 = 255u),
+	"01ffff037f" to O(u32 = 65535u),
+	"81ffffffff7f" to O(u32 = UInt.MAX_VALUE),
+	"02017f" to O(u64 = 1uL),
+	"02ff017f" to O(u64 = 255uL),
+	"02ffff037f" to O(u64 = 65535uL),
+	"02ffffffff0f7f" to O(u64 = 4294967295uL),
+	"82ffffffffffffffff7f" to O(u64 = ULong.MAX_VALUE),
+	"03017f" to O(i32 = 1),
+	"83017f" to O(i32 = -1),
+	"037f7f" to O(i32 = Byte.MAX_VALUE.toInt()),
+	"8380017f" to O(i32 = Byte.MIN_VALUE.toInt()),
+	"03ffff017f" to O(i32 = Short.MAX_VALUE.toInt()),
+	"838080027f" to O(i32 = Short.MIN_VALUE.toInt()),
+	"03ffffffff077f" to O(i32 = Int.MAX_VALUE),
+	"8380808080087f" to O(i32 = Int.MIN_VALUE),
+	"04017f" to O(i64 = 1),
+	"84017f" to O(i64 = -1),
+	"047f7f" to O(i64 = Byte.MAX_VALUE.toLong()),
+	"8480017f" to O(i64 = Byte.MIN_VALUE.toLong()),
+	"04ffff017f" to O(i64 = Short.MAX_VALUE.toLong()),
+	"848080027f" to O(i64 = Short.MIN_VALUE.toLong()),
+	"04ffffffff077f" to O(i64 = Int.MAX_VALUE.toLong()),
+	"8480808080087f" to O(i64 = Int.MIN_VALUE.toLong()),
+	"04ffffffffffffffff7f7f" to O(i64 = Long.MAX_VALUE),
+	"848080808080808080807f" to O(i64 = Long.MIN_VALUE),
+	"05000000017f" to O(f32 = Float.MIN_VALUE),
+	"057f7fffff7f" to O(f32 = Float.MAX_VALUE),
+	"057fc000007f" to O(f32 = Float.NaN),
+	"0600000000000000017f" to O(f64 = Double.MIN_VALUE),
+	"067fefffffffffffff7f" to O(f64 = Double.MAX_VALUE),
+	"067ff80000000000007f" to O(f64 = Double.NaN),
+	"0755ef312a2e5da4e77f" to O(t = Instant.ofEpochSecond(1441739050L, 777888999)),
+	"87000007dba8218000000003e87f" to O(t = Instant.ofEpochSecond(8640000000000L, 1000)),
+	"87fffff82457de8000000003e97f" to O(t = Instant.ofEpochSecond(-8640000000000L, 1001)),
+	"87ffffffffffffffff2e5da4e77f" to O(t = Instant.ofEpochSecond(-1L, 777888999)),
+	"0801417f" to O(s = "A"),
+	"080261007f" to O(s = "a\u0000"),
+	"0809c280e0a080f09080807f" to O(s = "\u0080\u0800\ud800\udc00"),
+	"0901ff7f" to O(a = byteArrayOf(-1)),
+	"090202007f" to O(a = byteArrayOf(2, 0)),
+	"0a7f7f" to O(o = O()),
+	"0a007f7f" to O(o = O(b = true)),
+	"0b01007f7f" to O(os = listOf(O(b = true))),
+	"0b027f7f7f" to O(os = listOf(O(), O())),
+	"0c0300016101627f" to O(ss = listOf("", "a", "b")),
+	"0d0201000201027f" to O(`as` = listOf(byteArrayOf(0), byteArrayOf(1, 2))),
+	"0e017f" to O(u8 = 1u),
+	"0eff7f" to O(u8 = UByte.MAX_VALUE),
+	"8f017f" to O(u16 = 1u),
+	"0fffff7f" to O(u16 = UShort.MAX_VALUE),
+	"1002000000003f8000007f" to O(f32s = floatArrayOf(0f, 1f)),
+	"11014058c000000000007f" to O(f64s = doubleArrayOf(99.0)),
+)
+
+fun ByteArray.toHex(): String = joinToString("") { "%02x".format(it) }
+
+fun String.parseHex(): ByteArray = ByteArray(length / 2) { substring(it * 2, it * 2 + 2).toInt(16).toByte() }
+
+fun identity() {
+	val a = newGoldenCases().values.toList()
+	val b = newGoldenCases().values.toList()
+	if (a != b) fail("golden cases not equal")
+	if (a.hashCode() != b.hashCode()) fail("golden cases hash not equal")
+	if (O().equals(null)) fail("equals null")
+}
+
+fun values() {
+	if (O(a = byteArrayOf(1), f32s = floatArrayOf(2f)) != O(a = byteArrayOf(1), f32s = floatArrayOf(2f)))
+		fail("arrays not compared by content")
+	if (O(`as` = listOf(byteArrayOf(1))).hashCode() != O(`as` = listOf(byteArrayOf(1))).hashCode())
+		fail("binary list hash not by content")
+	if (O(a = byteArrayOf(1)) == O(a = byteArrayOf(2)))
+		fail("different arrays equal")
+	val o = O(s = "x", os = listOf(O(b = true)))
+	if (o.copy(s = "y") != O(s = "y", os = listOf(O(b = true))))
+		fail("copy mismatch")
+	if (O().t != null || O().o != null || O().a.isNotEmpty() || O().os.isNotEmpty())
+		fail("defaults not zero")
+}
+
+fun marshal() {
+	for ((hex, o) in newGoldenCases()) {
+		val buf = ByteArray(o.marshalFit())
+		val n = o.marshal(buf)
+		if (n != hex.length / 2) fail("marshal: got write index $n for serial 0x$hex")
+		val got = buf.copyOf(n).toHex()
+		if (got != hex) fail("marshal: got serial 0x$got, want 0x$hex")
+		if (o.marshal().toHex() != hex) fail("marshal: allocation mismatch for serial 0x$hex")
+	}
+}
+
+fun unmarshal() {
+	for ((hex, want) in newGoldenCases()) {
+		val serial = (hex + "7f").parseHex()
+		val (got, i) = O.unmarshal(serial)
+		if (i != serial.size - 1) fail("unmarshal: got read index $i for serial 0x$hex")
+		if (got != want) fail("unmarshal: mismatch for serial 0x$hex")
+	}
+}
+
+fun unmarshalIncomplete() {
+	for (hex in newGoldenCases().keys) {
+		val serial = hex.parseHex()
+		for (end in 0 until serial.size) {
+			try {
+				O.unmarshal(serial, 0, end)
+				fail("unmarshal: no error for serial 0x$hex with end $end")
+			} catch (e: BufferUnderflowException) {
+				// expected
+			}
+		}
+	}
+}
+
+fun marshalMax() {
+	val saved = O.colferSizeMax
+	O.colferSizeMax = 10
+	try {
+		O(s = "x".repeat(100)).marshal()
+		fail("marshal: no error on size maximum breach")
+	} catch (e: IllegalStateException) {
+		// expected
+	} finally {
+		O.colferSizeMax = saved
+	}
+}
+
+fun unmarshalMismatch() {
+	try {
+		O.unmarshal("7e".parseHex())
+		fail("unmarshal: no error on schema mismatch")
+	} catch (e: InputMismatchException) {
+		// expected
+	}
+}
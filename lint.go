@@ -0,0 +1,200 @@
+package colfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/pascaldekloe/name"
+)
+
+// Lint rule identifiers.
+const (
+	// LintNameCollision flags names which are equal after CamelCase
+	// conversion, as applied by the generators.
+	LintNameCollision = "name-collision"
+	// LintKeyword flags names which get an underscore suffix in Java or
+	// ECMAScript, because of a reserved word.
+	LintKeyword = "keyword"
+	// LintFieldLimit flags structs close to the maximum of 127 fields.
+	LintFieldLimit = "field-limit"
+	// LintUndocumented flags fields without documentation.
+	LintUndocumented = "undocumented"
+	// LintUnused flags structs which are not referenced by any field. Note
+	// that root types, i.e., the top-level serials, are never referenced.
+	LintUnused = "unused"
+)
+
+// LintRules are all rule identifiers in use. There is no rule for recursive
+// types, as struct references are optional, i.e., a pointer or a nullable in
+// all languages, so any recursion can terminate.
+var LintRules = []string{LintNameCollision, LintKeyword, LintFieldLimit, LintUndocumented, LintUnused}
+
+// LintOptional are the rules which apply only when enabled with Lint.
+var LintOptional = []string{LintUndocumented, LintUnused}
+
+// lintFieldWarn is the number of fields from which LintFieldLimit applies.
+const lintFieldWarn = 112
+
+// LintIgnore is the comment directive for suppressions, followed by one or
+// more rule identifiers. The directive applies to the documented package,
+// struct or field, including all of its members. Without rule identifiers
+// the directive applies to all rules.
+const LintIgnore = "//colf:lint-ignore"
+
+// Finding is a schema smell reported by Lint.
+type Finding struct {
+	Pos     token.Position
+	Rule    string
+	Message string
+}
+
+// String returns the finding in the format of compiler diagnostics.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Pos, f.Message, f.Rule)
+}
+
+// MarshalJSON honors the json.Marshaler interface.
+func (f Finding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File    string `json:"file,omitempty"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+		Rule    string `json:"rule"`
+		Message string `json:"message"`
+	}{f.Pos.Filename, f.Pos.Line, f.Pos.Column, f.Rule, f.Message})
+}
+
+// Lint returns the findings in order of appearance. The rules from
+// LintOptional apply only when enabled. Suppressed rules, as with
+// LintIgnore, are omitted.
+func Lint(packages Packages, enable ...string) []Finding {
+	disabled := make(map[string]bool)
+	for _, rule := range LintOptional {
+		disabled[rule] = true
+	}
+	for _, rule := range enable {
+		delete(disabled, rule)
+	}
+
+	var findings []Finding
+	report := func(pos token.Position, rule string, ignores [][]string, format string, args ...interface{}) {
+		if disabled[rule] {
+			return
+		}
+		for _, rules := range ignores {
+			if rules == nil {
+				continue
+			}
+			if len(rules) == 0 {
+				return
+			}
+			for _, r := range rules {
+				if r == rule {
+					return
+				}
+			}
+		}
+		findings = append(findings, Finding{Pos: pos, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	referenced := make(map[*Struct]bool)
+	for _, p := range packages {
+		for _, t := range p.Structs {
+			for _, f := range t.Fields {
+				if f.TypeRef != nil && f.TypeRef != t {
+					referenced[f.TypeRef] = true
+				}
+			}
+		}
+	}
+
+	for _, p := range packages {
+		pkgIgnore := lintIgnores(p.Docs)
+
+		ecmaName := strings.Replace(p.Name, "/", "_", -1)
+		if _, ok := eCMAKeywords[ecmaName]; ok {
+			report(p.Pos, LintKeyword, [][]string{pkgIgnore}, "package %s gets name %s_ in ECMAScript", p.Name, ecmaName)
+		}
+		for _, s := range strings.Split(p.Name, "/") {
+			if _, ok := javaKeywords[s]; ok {
+				report(p.Pos, LintKeyword, [][]string{pkgIgnore}, "package %s gets segment %s_ in Java", p.Name, s)
+			}
+		}
+
+		structNames := make(map[string]*Struct)
+		for _, t := range p.Structs {
+			structIgnore := [][]string{pkgIgnore, lintIgnores(t.Docs)}
+
+			camel := name.CamelCase(t.Name, true)
+			if other, ok := structNames[camel]; ok {
+				report(t.Pos, LintNameCollision, structIgnore, "%s and %s both map to %s", t, other, camel)
+			} else {
+				structNames[camel] = t
+			}
+
+			if len(t.Fields) >= lintFieldWarn {
+				report(t.Pos, LintFieldLimit, structIgnore, "%s has %d fields, close to the maximum of 127", t, len(t.Fields))
+			}
+
+			if !referenced[t] {
+				report(t.Pos, LintUnused, structIgnore, "%s is not referenced by any field", t)
+			}
+
+			fieldNames := make(map[string]*Field)
+			for _, f := range t.Fields {
+				fieldIgnore := append(structIgnore, lintIgnores(f.Docs))
+
+				camel := name.CamelCase(f.Name, true)
+				if other, ok := fieldNames[camel]; ok {
+					report(f.Pos, LintNameCollision, fieldIgnore, "%s and %s both map to %s", f, other, camel)
+				} else {
+					fieldNames[camel] = f
+				}
+
+				native := name.CamelCase(f.Name, false)
+				if _, ok := javaKeywords[native]; ok {
+					report(f.Pos, LintKeyword, fieldIgnore, "%s gets name %s_ in Java", f, native)
+				}
+				if _, ok := eCMAKeywords[native]; ok {
+					report(f.Pos, LintKeyword, fieldIgnore, "%s gets name %s_ in ECMAScript", f, native)
+				}
+
+				if len(f.Docs) == 0 {
+					report(f.Pos, LintUndocumented, fieldIgnore, "%s has no documentation", f)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings
+}
+
+// LintIgnores returns the rules suppressed by docs. The return is nil for no
+// suppressions, and it is empty for all rules.
+func lintIgnores(docs []string) []string {
+	var rules []string
+	for _, line := range docs {
+		if !strings.HasPrefix(line, LintIgnore) {
+			continue
+		}
+		args := strings.Fields(strings.Replace(line[len(LintIgnore):], ",", " ", -1))
+		if len(args) == 0 {
+			return []string{}
+		}
+		rules = append(rules, args...)
+	}
+	return rules
+}
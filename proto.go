@@ -0,0 +1,626 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pascaldekloe/name"
+)
+
+// ProtoScalars maps the Protocol Buffers scalars to Colfer datatypes.
+var protoScalars = map[string]string{
+	"double":   "float64",
+	"float":    "float32",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"bool":     "bool",
+	"string":   "text",
+	"bytes":    "binary",
+
+	"google.protobuf.Timestamp":  "timestamp",
+	".google.protobuf.Timestamp": "timestamp",
+}
+
+// ParseProto returns the schema definition of the messages in a Protocol
+// Buffers file. Scalar, string, bytes, repeated and message fields map onto
+// Colfer, in order of their field number. Nested messages become structs with
+// the outer names as a prefix. Anything which can not be mapped is omitted,
+// with a note in the warnings, each prefixed with the file position.
+func ParseProto(path string, r io.Reader) (pkg *Package, warnings []string, err error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &protoParser{path: path, src: src, line: 1}
+	if err := p.parseFile(); err != nil {
+		return nil, p.warnings, err
+	}
+	pkg, err = p.mapPackage()
+	return pkg, p.warnings, err
+}
+
+// ProtoMessage is a message definition.
+type protoMessage struct {
+	// qName is the qualified name within the package, with dots for nesting.
+	qName  string
+	docs   []string
+	fields []*protoField
+	line   int
+}
+
+// ProtoField is a message member definition.
+type protoField struct {
+	name     string
+	typ      string
+	number   int
+	repeated bool
+	docs     []string
+	line     int
+	// scope is the qualified name of the enclosing message.
+	scope string
+}
+
+type protoParser struct {
+	path string
+	src  []byte
+	// i is the read position in src.
+	i    int
+	line int
+
+	// comments are the lines since the previous token.
+	comments []string
+	// tokLine is the line of the last token.
+	tokLine int
+
+	pkgName  string
+	pkgDocs  []string
+	messages []*protoMessage
+	enums    map[string]bool
+	warnings []string
+}
+
+func (p *protoParser) warn(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf("%s:%d: ", p.path, line)+fmt.Sprintf(format, args...))
+}
+
+func (p *protoParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("colfer: %s:%d: %s", p.path, p.tokLine, fmt.Sprintf(format, args...))
+}
+
+// Next returns the following token, with the empty string on end of input.
+// Comments are collected as documentation.
+func (p *protoParser) next() (string, error) {
+	for p.i < len(p.src) {
+		c := p.src[p.i]
+		switch {
+		case c == '\n':
+			p.line++
+			p.i++
+			// blank line ends documentation
+			if rest := bytes.TrimLeft(p.src[p.i:], " \t\r"); len(rest) != 0 && rest[0] == '\n' {
+				p.comments = nil
+			}
+		case unicode.IsSpace(rune(c)):
+			p.i++
+		case bytes.HasPrefix(p.src[p.i:], []byte("//")):
+			end := bytes.IndexByte(p.src[p.i:], '\n')
+			if end < 0 {
+				end = len(p.src) - p.i
+			}
+			// trailing comments are not documentation
+			if p.line != p.tokLine {
+				p.comments = append(p.comments, string(bytes.TrimRightFunc(p.src[p.i:p.i+end], unicode.IsSpace)))
+			}
+			p.i += end
+		case bytes.HasPrefix(p.src[p.i:], []byte("/*")):
+			end := bytes.Index(p.src[p.i+2:], []byte("*/"))
+			if end < 0 {
+				p.tokLine = p.line
+				return "", p.errorf("comment not terminated")
+			}
+			text := string(p.src[p.i+2 : p.i+2+end])
+			for _, s := range strings.Split(text, "\n") {
+				s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "*"))
+				if s != "" {
+					p.comments = append(p.comments, "// "+s)
+				}
+			}
+			p.line += strings.Count(text, "\n")
+			p.i += end + 4
+		default:
+			return p.token()
+		}
+	}
+	p.tokLine = p.line
+	return "", nil
+}
+
+func (p *protoParser) token() (string, error) {
+	p.tokLine = p.line
+	start := p.i
+	c := p.src[p.i]
+	switch {
+	case c == '"' || c == '\'':
+		for p.i++; p.i < len(p.src) && p.src[p.i] != c; p.i++ {
+			if p.src[p.i] == '\\' {
+				p.i++
+			}
+			if p.i < len(p.src) && p.src[p.i] == '\n' {
+				return "", p.errorf("string literal not terminated")
+			}
+		}
+		if p.i >= len(p.src) {
+			return "", p.errorf("string literal not terminated")
+		}
+		p.i++
+	case c == '.' || c == '_' || c == '-' || c == '+' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+		for p.i++; p.i < len(p.src); p.i++ {
+			c := rune(p.src[p.i])
+			if c != '.' && c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+				break
+			}
+		}
+	default:
+		p.i++
+	}
+	return string(p.src[start:p.i]), nil
+}
+
+// Docs returns the comments for the last token.
+func (p *protoParser) docs() []string {
+	docs := p.comments
+	p.comments = nil
+	return docs
+}
+
+func (p *protoParser) expect(want string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return p.errorf("got %q, want %q", tok, want)
+	}
+	return nil
+}
+
+// SkipStatement reads up until the ';' or the end of a block.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		tok, err := p.next()
+		switch {
+		case err != nil:
+			return err
+		case tok == "":
+			return p.errorf("unexpected end of input")
+		case tok == "{":
+			depth++
+		case tok == "}":
+			depth--
+			if depth <= 0 {
+				return nil
+			}
+		case tok == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+func (p *protoParser) parseFile() error {
+	p.enums = make(map[string]bool)
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "":
+			return nil
+		case ";":
+			continue
+		case "syntax":
+			p.docs()
+			line := p.tokLine
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			s, err := p.next()
+			if err != nil {
+				return err
+			}
+			if s != `"proto3"` && s != `'proto3'` {
+				p.warn(line, "syntax %s: only proto3 is supported; field presence and defaults are lost", s)
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "package":
+			p.pkgDocs = p.docs()
+			if p.pkgName, err = p.next(); err != nil {
+				return err
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			p.docs()
+			line := p.tokLine
+			s, err := p.next()
+			if err != nil {
+				return err
+			}
+			if s == "public" || s == "weak" {
+				if s, err = p.next(); err != nil {
+					return err
+				}
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+			if s != `"google/protobuf/timestamp.proto"` {
+				p.warn(line, "import %s ignored; types from other files are not mapped", s)
+			}
+		case "option":
+			p.docs()
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "message":
+			if err := p.parseMessage(""); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(""); err != nil {
+				return err
+			}
+		case "service", "extend":
+			p.docs()
+			p.warn(p.tokLine, "%s not supported", tok)
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unexpected %q", tok)
+		}
+	}
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	p.docs()
+	ident, err := p.next()
+	if err != nil {
+		return err
+	}
+	p.enums[qualify(scope, ident)] = true
+	return p.skipStatement()
+}
+
+func qualify(scope, ident string) string {
+	if scope == "" {
+		return ident
+	}
+	return scope + "." + ident
+}
+
+func (p *protoParser) parseMessage(scope string) error {
+	m := &protoMessage{docs: p.docs(), line: p.tokLine}
+	ident, err := p.next()
+	if err != nil {
+		return err
+	}
+	m.qName = qualify(scope, ident)
+	p.messages = append(p.messages, m)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseBody(m, false)
+}
+
+// ParseBody reads the message declarations up until the closing brace.
+func (p *protoParser) parseBody(m *protoMessage, oneof bool) error {
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "":
+			return p.errorf("unexpected end of input")
+		case "}":
+			p.docs()
+			return nil
+		case ";":
+			continue
+		case "option", "reserved", "extensions":
+			p.docs()
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+			continue
+		}
+		if !oneof {
+			switch tok {
+			case "message":
+				if err := p.parseMessage(m.qName); err != nil {
+					return err
+				}
+				continue
+			case "enum":
+				if err := p.parseEnum(m.qName); err != nil {
+					return err
+				}
+				continue
+			case "oneof":
+				p.docs()
+				p.warn(p.tokLine, "oneof in message %s flattened; the exclusivity is lost", m.qName)
+				if _, err := p.next(); err != nil {
+					return err
+				}
+				if err := p.expect("{"); err != nil {
+					return err
+				}
+				if err := p.parseBody(m, true); err != nil {
+					return err
+				}
+				continue
+			case "extend":
+				p.docs()
+				p.warn(p.tokLine, "extend in message %s not supported", m.qName)
+				if err := p.skipStatement(); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := p.parseField(m, tok); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *protoParser) parseField(m *protoMessage, tok string) error {
+	f := &protoField{docs: p.docs(), line: p.tokLine, scope: m.qName}
+	switch tok {
+	case "repeated":
+		f.repeated = true
+		fallthrough
+	case "optional", "required":
+		var err error
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	case "map":
+		p.warn(f.line, "map field in message %s not supported", m.qName)
+		return p.skipStatement()
+	case "group":
+		p.warn(f.line, "group in message %s not supported", m.qName)
+		return p.skipStatement()
+	}
+	f.typ = tok
+
+	var err error
+	if f.name, err = p.next(); err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	s, err := p.next()
+	if err != nil {
+		return err
+	}
+	if f.number, err = strconv.Atoi(s); err != nil {
+		return p.errorf("malformed field number %q", s)
+	}
+
+	tok, err = p.next()
+	if err != nil {
+		return err
+	}
+	if tok == "[" {
+		// field options
+		for tok != "]" {
+			if tok, err = p.next(); err != nil {
+				return err
+			}
+			if tok == "" {
+				return p.errorf("unexpected end of input")
+			}
+		}
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	}
+	if tok != ";" {
+		return p.errorf("got %q, want %q", tok, ";")
+	}
+
+	m.fields = append(m.fields, f)
+	return nil
+}
+
+// Resolve returns the qualified name of a message or enum type, relative to
+// the package.
+func (p *protoParser) resolve(f *protoField, isType func(string) bool) (string, bool) {
+	typ := f.typ
+	if strings.HasPrefix(typ, ".") {
+		typ = strings.TrimPrefix(typ[1:], p.pkgName+".")
+		return typ, isType(typ)
+	}
+	if p.pkgName != "" && strings.HasPrefix(typ, p.pkgName+".") && isType(typ[len(p.pkgName)+1:]) {
+		return typ[len(p.pkgName)+1:], true
+	}
+	// innermost scope first
+	for scope := f.scope; ; {
+		if qName := qualify(scope, typ); isType(qName) {
+			return qName, true
+		}
+		if scope == "" {
+			return "", false
+		}
+		if i := strings.LastIndexByte(scope, '.'); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// ColferName returns the struct name for a qualified message name.
+func protoStructName(qName string) string {
+	return name.CamelCase(strings.Replace(qName, ".", "_", -1), false)
+}
+
+func (p *protoParser) mapPackage() (*Package, error) {
+	pkgName := p.pkgName
+	if pkgName == "" {
+		pkgName = strings.TrimSuffix(path.Base(p.path), path.Ext(p.path))
+		p.warn(1, "no package; using %q", pkgName)
+	}
+	pkg := &Package{
+		Name:        strings.ToLower(strings.Replace(pkgName, ".", "_", -1)),
+		Docs:        p.pkgDocs,
+		SchemaFiles: []string{path.Base(p.path)},
+	}
+
+	structs := make(map[string]*Struct, len(p.messages))
+	for _, m := range p.messages {
+		t := &Struct{Pkg: pkg, Name: protoStructName(m.qName), Docs: m.docs, SchemaFile: path.Base(p.path)}
+		structs[m.qName] = t
+		pkg.Structs = append(pkg.Structs, t)
+	}
+	isMessage := func(qName string) bool {
+		_, ok := structs[qName]
+		return ok
+	}
+
+	for _, m := range p.messages {
+		t := structs[m.qName]
+		sort.SliceStable(m.fields, func(i, j int) bool {
+			return m.fields[i].number < m.fields[j].number
+		})
+
+		for _, pf := range m.fields {
+			f := &Field{Struct: t, Index: len(t.Fields), Name: name.CamelCase(pf.name, false), Docs: pf.docs, TypeList: pf.repeated}
+
+			if typ, ok := protoScalars[pf.typ]; ok {
+				f.Type = typ
+			} else if qName, ok := p.resolve(pf, isMessage); ok {
+				f.TypeRef = structs[qName]
+				f.Type = f.TypeRef.Name
+			} else if _, ok := p.resolve(pf, func(s string) bool { return p.enums[s] }); ok {
+				p.warn(pf.line, "enum field %s.%s mapped to int32; the symbols are lost", m.qName, pf.name)
+				f.Type = "int32"
+			} else {
+				p.warn(pf.line, "field %s.%s of type %s not mapped; unknown or external type", m.qName, pf.name, pf.typ)
+				continue
+			}
+
+			if f.TypeList {
+				switch f.Type {
+				case "bool", "uint32", "uint64", "timestamp":
+					p.warn(pf.line, "field %s.%s not mapped; no lists of %s in Colfer", m.qName, pf.name, f.Type)
+					continue
+				}
+			}
+			if len(t.Fields) == 127 {
+				p.warn(pf.line, "field %s.%s not mapped; Colfer has a maximum of 127 fields", m.qName, pf.name)
+				continue
+			}
+			t.Fields = append(t.Fields, f)
+		}
+
+		if len(t.Fields) == 0 {
+			return nil, fmt.Errorf("colfer: %s:%d: message %s has no fields in Colfer", p.path, m.line, m.qName)
+		}
+	}
+	return pkg, nil
+}
+
+// ProtoFiles returns Protocol Buffers definitions for packages, with a
+// "<package>.proto" file each. The warnings describe any losses in mapping.
+func ProtoFiles(packages Packages) (files Files, warnings []string) {
+	files = make(Files, len(packages))
+	for _, p := range packages {
+		var buf bytes.Buffer
+		buf.WriteString("// Converted by colf(1) from schema file ")
+		buf.WriteString(p.SchemaFileList())
+		buf.WriteString(".\n\n")
+		buf.WriteString("syntax = \"proto3\";\n\n")
+		writeDocs(&buf, p.Docs, "")
+		fmt.Fprintf(&buf, "package %s;\n", strings.Replace(p.Name, "/", ".", -1))
+
+		var imports []string
+		if p.HasTimestamp() {
+			imports = append(imports, "google/protobuf/timestamp.proto")
+		}
+		for _, ref := range p.Refs() {
+			imports = append(imports, ref.Name+".proto")
+		}
+		if len(imports) != 0 {
+			buf.WriteByte('\n')
+		}
+		for _, s := range imports {
+			fmt.Fprintf(&buf, "import %q;\n", s)
+		}
+
+		for _, t := range p.Structs {
+			buf.WriteByte('\n')
+			writeDocs(&buf, t.Docs, "")
+			fmt.Fprintf(&buf, "message %s {\n", name.CamelCase(t.Name, true))
+			for _, f := range t.Fields {
+				writeDocs(&buf, f.Docs, "\t")
+				buf.WriteByte('\t')
+				if f.TypeList {
+					buf.WriteString("repeated ")
+				}
+
+				typ := f.Type
+				switch f.Type {
+				case "uint8", "uint16":
+					warnings = append(warnings, fmt.Sprintf("field %s: %s widened to uint32", f, f.Type))
+					typ = "uint32"
+				case "float32":
+					typ = "float"
+				case "float64":
+					typ = "double"
+				case "timestamp":
+					typ = "google.protobuf.Timestamp"
+				case "text":
+					typ = "string"
+				case "binary":
+					typ = "bytes"
+				}
+				if f.TypeRef != nil {
+					typ = name.CamelCase(f.TypeRef.Name, true)
+					if f.TypeRef.Pkg != p {
+						typ = strings.Replace(f.TypeRef.Pkg.Name, "/", ".", -1) + "." + typ
+					}
+				}
+
+				fmt.Fprintf(&buf, "%s %s = %d;\n", typ, strings.ToLower(name.SnakeCase(f.Name)), f.Index+1)
+			}
+			buf.WriteString("}\n")
+		}
+
+		files[p.Name+".proto"] = buf.Bytes()
+	}
+	return files, warnings
+}
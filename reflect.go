@@ -0,0 +1,628 @@
+package colfer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SizeMax is the upper limit for serial byte sizes with Marshal and Unmarshal.
+var SizeMax = 16 * 1024 * 1024
+
+// ListMax is the upper limit for the number of elements in a list with Marshal
+// and Unmarshal.
+var ListMax = 64 * 1024
+
+// Marshal returns the Colfer encoding of v, which must be a struct or a pointer
+// to a struct. Fields take part when they have a tag with the Colfer index, as
+// in `colfer:"0"`. The serial is identical to the output of code from
+// GenerateGo for the equivalent schema, which is available with ParseGoFiles.
+//
+// The Go types map as follows. Bool, uint8, uint16, uint32, uint64, int32,
+// int64, float32 and float64 map onto the Colfer types of the same name.
+// A time.Time is a timestamp, a string is text and a []byte is binary. Lists
+// are slices of float32, float64, int32, int64, string or []byte. Structs with
+// Colfer tags can be referenced either by value or by pointer, and they can be
+// listed as a slice of either.
+func Marshal(v interface{}) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, errors.New("colfer: marshal of nil pointer")
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("colfer: marshal of non-struct type %s", val.Type())
+	}
+
+	s, err := reflectStructOf(val.Type())
+	if err != nil {
+		return nil, err
+	}
+	buf, err := s.marshal(nil, val)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > SizeMax {
+		return nil, fmt.Errorf("colfer: %s size %d exceeds %d bytes", s.name, len(buf), SizeMax)
+	}
+	return buf, nil
+}
+
+// Unmarshal decodes data into v, which must be a pointer to a struct. See
+// Marshal for the mapping of types. The error is io.EOF when data is
+// incomplete, and data continuation after the serial is an error too.
+func Unmarshal(data []byte, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("colfer: unmarshal into non-pointer or nil %T", v)
+	}
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("colfer: unmarshal into non-struct type %s", val.Type())
+	}
+
+	s, err := reflectStructOf(val.Type())
+	if err != nil {
+		return err
+	}
+
+	d := decoder{data: data}
+	if len(data) > SizeMax {
+		d.data = data[:SizeMax]
+	}
+	s.unmarshal(&d, val)
+	if d.err != nil {
+		if d.err == io.EOF && len(data) > SizeMax {
+			return fmt.Errorf("colfer: %s exceeds %d bytes", s.name, SizeMax)
+		}
+		return d.err
+	}
+	if d.i < len(data) {
+		return fmt.Errorf("colfer: data continuation at byte %d", d.i)
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ReflectStruct is the Colfer mapping of a Go struct.
+type reflectStruct struct {
+	// name is the Go type for error messages.
+	name string
+	// fields are in order of the Colfer index.
+	fields []*reflectField
+}
+
+// ReflectField is the Colfer mapping of a Go struct field.
+type reflectField struct {
+	// index is the Colfer field index.
+	index byte
+	// num is the Go struct field number.
+	num int
+	// name is the qualified Go name for error messages.
+	name string
+	// typ is the Colfer datatype, or empty for struct references.
+	typ string
+	// list flags whether the datatype is a list.
+	list bool
+	// ptr flags struct references by pointer.
+	ptr bool
+	// ref is the struct reference.
+	ref *reflectStruct
+}
+
+var (
+	reflectMutex sync.Mutex
+	reflectCache = make(map[reflect.Type]*reflectStruct)
+)
+
+// ReflectStructOf returns the mapping of t, which must be a struct type.
+func reflectStructOf(t reflect.Type) (*reflectStruct, error) {
+	reflectMutex.Lock()
+	defer reflectMutex.Unlock()
+
+	if s, ok := reflectCache[t]; ok {
+		return s, nil
+	}
+
+	// only cache complete results
+	building := make(map[reflect.Type]*reflectStruct)
+	s, err := buildReflectStruct(t, building)
+	if err != nil {
+		return nil, err
+	}
+	for t, s := range building {
+		reflectCache[t] = s
+	}
+	return s, nil
+}
+
+func buildReflectStruct(t reflect.Type, building map[reflect.Type]*reflectStruct) (*reflectStruct, error) {
+	if s, ok := reflectCache[t]; ok {
+		return s, nil
+	}
+	if s, ok := building[t]; ok {
+		return s, nil // recursion
+	}
+	s := &reflectStruct{name: t.String()}
+	building[t] = s
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("colfer")
+		if !ok {
+			continue
+		}
+		f := &reflectField{num: i, name: s.name + "." + sf.Name}
+		index, err := strconv.ParseUint(tag, 10, 8)
+		if err != nil || index > 126 {
+			return nil, fmt.Errorf("colfer: field %s tag %q is not an index in [0, 126]", f.name, tag)
+		}
+		f.index = byte(index)
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("colfer: field %s is not exported", f.name)
+		}
+		if err := f.setType(sf.Type, building); err != nil {
+			return nil, err
+		}
+		s.fields = append(s.fields, f)
+	}
+
+	if len(s.fields) == 0 {
+		return nil, fmt.Errorf("colfer: %s has no fields with a colfer tag", s.name)
+	}
+	sort.SliceStable(s.fields, func(i, j int) bool {
+		return s.fields[i].index < s.fields[j].index
+	})
+	for i := 1; i < len(s.fields); i++ {
+		if s.fields[i].index == s.fields[i-1].index {
+			return nil, fmt.Errorf("colfer: fields %s and %s have the same index %d", s.fields[i-1].name, s.fields[i].name, s.fields[i].index)
+		}
+	}
+	return s, nil
+}
+
+// SetType applies the mapping of t.
+func (f *reflectField) setType(t reflect.Type, building map[reflect.Type]*reflectStruct) error {
+	if t.Kind() == reflect.Slice {
+		if t.Elem().Kind() == reflect.Uint8 {
+			f.typ = "binary"
+			return nil
+		}
+		f.list = true
+		t = t.Elem()
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64, reflect.Int32, reflect.Int64:
+			f.typ = t.Kind().String()
+			return nil
+		case reflect.String:
+			f.typ = "text"
+			return nil
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				f.typ = "binary"
+				return nil
+			}
+		case reflect.Struct, reflect.Ptr:
+			break
+		default:
+			return fmt.Errorf("colfer: unsupported list type %s for field %s", t, f.name)
+		}
+	} else {
+		switch t.Kind() {
+		case reflect.Bool, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+			f.typ = t.Kind().String()
+			return nil
+		case reflect.String:
+			f.typ = "text"
+			return nil
+		}
+	}
+
+	if t == timeType && !f.list {
+		f.typ = "timestamp"
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		f.ptr = true
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return fmt.Errorf("colfer: unsupported type %s for field %s", t, f.name)
+	}
+	var err error
+	f.ref, err = buildReflectStruct(t, building)
+	return err
+}
+
+func (s *reflectStruct) marshal(buf []byte, v reflect.Value) ([]byte, error) {
+	for _, f := range s.fields {
+		fv := v.Field(f.num)
+
+		if f.list {
+			l := fv.Len()
+			if l == 0 {
+				continue
+			}
+			if l > ListMax {
+				return nil, fmt.Errorf("colfer: field %s exceeds %d elements", f.name, ListMax)
+			}
+			buf = append(buf, f.index)
+			buf = appendVarint(buf, uint64(l))
+			for i := 0; i < l; i++ {
+				ev := fv.Index(i)
+				switch f.typ {
+				case "float32":
+					buf = appendUint32(buf, math.Float32bits(float32(ev.Float())))
+				case "float64":
+					buf = appendUint64(buf, math.Float64bits(ev.Float()))
+				case "int32":
+					x := int32(ev.Int())
+					buf = appendVarint(buf, uint64(uint32(x<<1)^uint32(x>>31)))
+				case "int64":
+					x := ev.Int()
+					buf = appendVarint9(buf, uint64(x<<1)^uint64(x>>63))
+				case "text":
+					buf = appendVarint(buf, uint64(ev.Len()))
+					buf = append(buf, ev.String()...)
+				case "binary":
+					buf = appendVarint(buf, uint64(ev.Len()))
+					buf = append(buf, ev.Bytes()...)
+				default:
+					if f.ptr {
+						if ev.IsNil() {
+							buf = append(buf, 0x7f)
+							continue
+						}
+						ev = ev.Elem()
+					}
+					var err error
+					buf, err = f.ref.marshal(buf, ev)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+			continue
+		}
+
+		switch f.typ {
+		case "bool":
+			if fv.Bool() {
+				buf = append(buf, f.index)
+			}
+
+		case "uint8":
+			if x := fv.Uint(); x != 0 {
+				buf = append(buf, f.index, byte(x))
+			}
+
+		case "uint16":
+			if x := fv.Uint(); x >= 1<<8 {
+				buf = append(buf, f.index, byte(x>>8), byte(x))
+			} else if x != 0 {
+				buf = append(buf, f.index|0x80, byte(x))
+			}
+
+		case "uint32":
+			if x := fv.Uint(); x >= 1<<21 {
+				buf = appendUint32(append(buf, f.index|0x80), uint32(x))
+			} else if x != 0 {
+				buf = appendVarint(append(buf, f.index), x)
+			}
+
+		case "uint64":
+			if x := fv.Uint(); x >= 1<<49 {
+				buf = appendUint64(append(buf, f.index|0x80), x)
+			} else if x != 0 {
+				buf = appendVarint(append(buf, f.index), x)
+			}
+
+		case "int32":
+			if v := int32(fv.Int()); v > 0 {
+				buf = appendVarint(append(buf, f.index), uint64(v))
+			} else if v < 0 {
+				buf = appendVarint(append(buf, f.index|0x80), uint64(^uint32(v)+1))
+			}
+
+		case "int64":
+			if v := fv.Int(); v > 0 {
+				buf = appendVarint9(append(buf, f.index), uint64(v))
+			} else if v < 0 {
+				buf = appendVarint9(append(buf, f.index|0x80), ^uint64(v)+1)
+			}
+
+		case "float32":
+			if v := float32(fv.Float()); v != 0 {
+				buf = appendUint32(append(buf, f.index), math.Float32bits(v))
+			}
+
+		case "float64":
+			if v := fv.Float(); v != 0 {
+				buf = appendUint64(append(buf, f.index), math.Float64bits(v))
+			}
+
+		case "timestamp":
+			t := fv.Interface().(time.Time)
+			if t.IsZero() {
+				continue
+			}
+			if s := uint64(t.Unix()); s < 1<<32 {
+				buf = appendUint32(append(buf, f.index), uint32(s))
+			} else {
+				buf = appendUint64(append(buf, f.index|0x80), s)
+			}
+			buf = appendUint32(buf, uint32(t.Nanosecond()))
+
+		case "text", "binary":
+			if l := fv.Len(); l != 0 {
+				if l > SizeMax {
+					return nil, fmt.Errorf("colfer: field %s exceeds %d bytes", f.name, SizeMax)
+				}
+				buf = appendVarint(append(buf, f.index), uint64(l))
+				if f.typ == "text" {
+					buf = append(buf, fv.String()...)
+				} else {
+					buf = append(buf, fv.Bytes()...)
+				}
+			}
+
+		default:
+			if f.ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			var err error
+			buf, err = f.ref.marshal(append(buf, f.index), fv)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return append(buf, 0x7f), nil
+}
+
+func appendVarint(buf []byte, x uint64) []byte {
+	for x >= 0x80 {
+		buf = append(buf, byte(x|0x80))
+		x >>= 7
+	}
+	return append(buf, byte(x))
+}
+
+// AppendVarint9 encodes x with at most 9 bytes, as the last byte has no
+// continuation bit.
+func appendVarint9(buf []byte, x uint64) []byte {
+	for n := 0; x >= 0x80 && n < 8; n++ {
+		buf = append(buf, byte(x|0x80))
+		x >>= 7
+	}
+	return append(buf, byte(x))
+}
+
+func appendUint32(buf []byte, x uint32) []byte {
+	return append(buf, byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+}
+
+func appendUint64(buf []byte, x uint64) []byte {
+	return append(buf, byte(x>>56), byte(x>>48), byte(x>>40), byte(x>>32),
+		byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+}
+
+// Decoder reads a serial. Any error sticks; reads return zero values from then
+// on.
+type decoder struct {
+	data []byte
+	// i is the read position.
+	i   int
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if d.i >= len(d.data) {
+		d.err = io.EOF
+		return 0
+	}
+	b := d.data[d.i]
+	d.i++
+	return b
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data)-d.i {
+		d.err = io.EOF
+		return nil
+	}
+	p := d.data[d.i : d.i+n]
+	d.i += n
+	return p
+}
+
+func (d *decoder) uint32() uint32 {
+	p := d.bytes(4)
+	if p == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(p)
+}
+
+func (d *decoder) uint64() uint64 {
+	p := d.bytes(8)
+	if p == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(p)
+}
+
+func (d *decoder) varint() uint64 {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		b := d.byte()
+		if b < 0x80 || shift == 56 {
+			return x | uint64(b)<<shift
+		}
+		x |= uint64(b&0x7f) << shift
+	}
+}
+
+// Size reads a length or count, bounded by max.
+func (d *decoder) size(f *reflectField, max int, unit string) int {
+	x := d.varint()
+	if d.err == nil && x > uint64(max) {
+		d.err = fmt.Errorf("colfer: field %s length %d exceeds %d %s", f.name, x, max, unit)
+	}
+	return int(x)
+}
+
+func (s *reflectStruct) unmarshal(d *decoder, v reflect.Value) {
+	header := d.byte()
+	for _, f := range s.fields {
+		if d.err != nil {
+			return
+		}
+		if header != f.index && header != f.index|0x80 {
+			continue
+		}
+		flag := header&0x80 != 0
+		fv := v.Field(f.num)
+
+		switch {
+		case f.list:
+			if flag {
+				continue // unknown header
+			}
+			l := d.size(f, ListMax, "elements")
+			if d.err != nil {
+				return
+			}
+			a := reflect.MakeSlice(fv.Type(), l, l)
+			for i := 0; i < l && d.err == nil; i++ {
+				ev := a.Index(i)
+				switch f.typ {
+				case "float32":
+					ev.SetFloat(float64(math.Float32frombits(d.uint32())))
+				case "float64":
+					ev.SetFloat(math.Float64frombits(d.uint64()))
+				case "int32":
+					x := uint32(d.varint())
+					ev.SetInt(int64(int32(x>>1) ^ -int32(x&1)))
+				case "int64":
+					x := d.varint()
+					ev.SetInt(int64(x>>1) ^ -int64(x&1))
+				case "text":
+					ev.SetString(string(d.bytes(d.size(f, SizeMax, "bytes"))))
+				case "binary":
+					p := d.bytes(d.size(f, SizeMax, "bytes"))
+					ev.SetBytes(append(make([]byte, 0, len(p)), p...))
+				default:
+					if f.ptr {
+						ev.Set(reflect.New(ev.Type().Elem()))
+						ev = ev.Elem()
+					}
+					f.ref.unmarshal(d, ev)
+				}
+			}
+			fv.Set(a)
+
+		case f.typ == "bool", f.typ == "uint8", f.typ == "float32", f.typ == "float64",
+			f.typ == "text", f.typ == "binary", f.ref != nil:
+			if flag {
+				continue // unknown header
+			}
+			switch f.typ {
+			case "bool":
+				fv.SetBool(true)
+			case "uint8":
+				fv.SetUint(uint64(d.byte()))
+			case "float32":
+				fv.SetFloat(float64(math.Float32frombits(d.uint32())))
+			case "float64":
+				fv.SetFloat(math.Float64frombits(d.uint64()))
+			case "text":
+				fv.SetString(string(d.bytes(d.size(f, SizeMax, "bytes"))))
+			case "binary":
+				p := d.bytes(d.size(f, SizeMax, "bytes"))
+				fv.SetBytes(append(make([]byte, 0, len(p)), p...))
+			default:
+				if f.ptr {
+					fv.Set(reflect.New(fv.Type().Elem()))
+					fv = fv.Elem()
+				}
+				f.ref.unmarshal(d, fv)
+			}
+
+		case f.typ == "uint16":
+			if flag {
+				fv.SetUint(uint64(d.byte()))
+			} else {
+				p := d.bytes(2)
+				if p != nil {
+					fv.SetUint(uint64(binary.BigEndian.Uint16(p)))
+				}
+			}
+
+		case f.typ == "uint32":
+			if flag {
+				fv.SetUint(uint64(d.uint32()))
+			} else {
+				fv.SetUint(uint64(uint32(d.varint())))
+			}
+
+		case f.typ == "uint64":
+			if flag {
+				fv.SetUint(d.uint64())
+			} else {
+				fv.SetUint(d.varint())
+			}
+
+		case f.typ == "int32":
+			x := uint32(d.varint())
+			if flag {
+				x = ^x + 1
+			}
+			fv.SetInt(int64(int32(x)))
+
+		case f.typ == "int64":
+			x := d.varint()
+			if flag {
+				x = ^x + 1
+			}
+			fv.SetInt(int64(x))
+
+		case f.typ == "timestamp":
+			var sec int64
+			if flag {
+				sec = int64(d.uint64())
+			} else {
+				sec = int64(d.uint32())
+			}
+			nsec := int64(d.uint32())
+			fv.Set(reflect.ValueOf(time.Unix(sec, nsec).In(time.UTC)))
+		}
+
+		header = d.byte()
+	}
+
+	if d.err == nil && header != 0x7f {
+		d.err = fmt.Errorf("colfer: unknown header at byte %d", d.i-1)
+	}
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pascaldekloe/colfer/rpc/internal"
+)
+
+// flateCompression is the internal.Header.Compression for DEFLATE.
+const flateCompression = 1
+
+// WithCompression enables DEFLATE for bodies of threshold bytes or more.
+// Bodies are only compressed once the peer has announced support, which
+// happens with each message from a codec with compression enabled. Bodies
+// which do not shrink are sent as is.
+//
+// Decompression is always available. The uncompressed size may not exceed
+// 16 MiB, which is the default ColferSizeMax of generated code.
+func WithCompression(threshold int) Option {
+	return func(c *codec) {
+		c.compress = true
+		c.compressMin = threshold
+	}
+}
+
+// FlateWriters are reused for performance.
+var flateWriters = sync.Pool{
+	New: func() interface{} {
+		w, err := flate.NewWriter(nil, flate.DefaultCompression)
+		if err != nil {
+			panic(err)
+		}
+		return w
+	},
+}
+
+// Deflate returns the compressed serial of body with size l. The return is
+// nil when compression does not pay off.
+func deflate(body colferer, l int) []byte {
+	plain := make([]byte, l)
+	body.MarshalTo(plain)
+
+	var buf bytes.Buffer
+	buf.Grow(l / 2)
+	w := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(w)
+	w.Reset(&buf)
+	// bytes.Buffer does not fail
+	w.Write(plain)
+	w.Close()
+
+	if buf.Len() >= l {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// Inflate reads the compressed body of the last header received. The output is
+// limited to the declared RawSize, and both sizes are bound to the header limit
+// to defeat compression bombs.
+func (c *codec) inflate() ([]byte, error) {
+	if c.header.Compression != flateCompression {
+		return nil, fmt.Errorf("colfer/rpc: unsupported compression %d", c.header.Compression)
+	}
+	size, rawSize := int(c.header.BodySize), int(c.header.RawSize)
+	if size > internal.ColferSizeMax || rawSize > internal.ColferSizeMax {
+		return nil, fmt.Errorf("colfer/rpc: compressed body size %d (%d raw) exceeds %d bytes", size, rawSize, internal.ColferSizeMax)
+	}
+
+	if err := c.fill(size); err != nil {
+		return nil, err
+	}
+	r := flate.NewReader(bytes.NewReader(c.buf[c.offset : c.offset+size]))
+	c.offset += size
+
+	data := make([]byte, rawSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("colfer/rpc: body decompression: %w", err)
+	}
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		return nil, fmt.Errorf("colfer/rpc: decompressed body exceeds %d bytes", rawSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,35 @@
+// Runs against a Go server with the Echo service on the WebSocket URL argument.
+var assert = require('assert');
+var gen = require('./Colfer.js').gen;
+var rpc = require('./ColferRPC.js');
+
+var client = new rpc.ColferRPC(process.argv[2]);
+
+async function run() {
+	var got = await client.call('Echo.Echo', new gen.O({s: 'hello', u64: 42, b: true}), new gen.O({}), {trace: 'T1'});
+	assert.deepStrictEqual(got, new gen.O({b: true, u64: 42, s: 'hello'}), 'echo reply');
+
+	// concurrent calls
+	var replies = await Promise.all([1, 2, 3].map((n) => client.call('Echo.Echo', new gen.O({u32: n}), new gen.O({}))));
+	assert.deepStrictEqual(replies, [1, 2, 3].map((n) => new gen.O({u32: n})), 'concurrent replies');
+
+	await assert.rejects(client.call('Echo.Fail', new gen.O({u32: 404, s: 'not found', b: true}), new gen.O({})), (e) => {
+		assert(e instanceof rpc.ColferRPCError, 'error type');
+		assert.strictEqual(e.code, 404, 'error code');
+		assert.strictEqual(e.message, 'not found', 'error message');
+		assert.strictEqual(e.retryable, true, 'error retryable');
+		return true;
+	});
+
+	await assert.rejects(client.call('Echo.None', new gen.O({}), new gen.O({})), /rpc: can't find method/);
+
+	got = await client.call('Echo.Echo', new gen.O({s: 'again'}), new gen.O({}));
+	assert.deepStrictEqual(got, new gen.O({s: 'again'}), 'reply after failures');
+}
+
+run().then(() => {
+	client.close();
+}, (e) => {
+	console.error(e);
+	process.exit(1);
+});
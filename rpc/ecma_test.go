@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pascaldekloe/colfer"
+)
+
+// TestECMA runs ecma/test.js against a Go server over WebSocket.
+func TestECMA(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("no node available:", err)
+	}
+	// global WebSocket is experimental in NodeJS before version 22
+	nodeArgs := []string{"--experimental-websocket"}
+	if exec.Command("node", append(nodeArgs, "-e", "new WebSocket('ws://localhost:1').close()")...).Run() != nil {
+		nodeArgs = nil
+		if exec.Command("node", "-e", "new WebSocket('ws://localhost:1').close()").Run() != nil {
+			t.Skip("no WebSocket support in node")
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "colfer-rpc-ecma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	packages, err := colfer.ParseFiles("../testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packages {
+		p.SizeMax = "16 * 1024 * 1024"
+		p.ListMax = "64 * 1024"
+	}
+	if err := colfer.GenerateECMA(dir, packages); err != nil {
+		t.Fatal(err)
+	}
+	test, err := ioutil.ReadFile("ecma/test.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test.js"), test, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(WebSocketHandler(server))
+	defer s.Close()
+
+	url := "ws" + strings.TrimPrefix(s.URL, "http")
+	node := exec.Command("node", append(nodeArgs, filepath.Join(dir, "test.js"), url)...)
+	if out, err := node.CombinedOutput(); err != nil {
+		t.Fatalf("node: %s\n%s", err, out)
+	}
+}
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/pascaldekloe/colfer/rpc/internal"
+)
+
+// Error is a structured failure. The net/rpc package passes errors as text
+// only. Error encodes its content into the text such that the codecs can put
+// each property in a dedicated header field, and such that clients can get the
+// original back with ParseError.
+type Error struct {
+	// Code classifies the failure. Zero is unspecified.
+	Code uint32
+	// Message is the description.
+	Message string
+	// Retryable flags whether the call may succeed when repeated.
+	Retryable bool
+	// Detail is an optional Colfer serial.
+	Detail []byte
+}
+
+// errorPrefix marks the textual encoding of an Error.
+const errorPrefix = "colfer/rpc: code "
+
+// Error honors the error interface. The format is
+// "colfer/rpc: code <N>[ retryable][ detail <base64url>]: <message>".
+func (e *Error) Error() string {
+	var buf strings.Builder
+	buf.WriteString(errorPrefix)
+	buf.WriteString(strconv.FormatUint(uint64(e.Code), 10))
+	if e.Retryable {
+		buf.WriteString(" retryable")
+	}
+	if len(e.Detail) != 0 {
+		buf.WriteString(" detail ")
+		buf.WriteString(base64.RawURLEncoding.EncodeToString(e.Detail))
+	}
+	buf.WriteString(": ")
+	buf.WriteString(e.Message)
+	return buf.String()
+}
+
+// UnmarshalDetail decodes the Detail into v, which must be a Colfer type.
+func (e *Error) UnmarshalDetail(v interface{ UnmarshalBinary([]byte) error }) error {
+	if len(e.Detail) == 0 {
+		return errors.New("colfer/rpc: error has no detail")
+	}
+	return v.UnmarshalBinary(e.Detail)
+}
+
+// ParseError returns the Error encoded in err, which includes the
+// rpc.ServerError values from a net/rpc Client. The return is nil when
+// err has no structured content.
+func ParseError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return parseErrorText(err.Error())
+}
+
+func parseErrorText(s string) *Error {
+	if !strings.HasPrefix(s, errorPrefix) {
+		return nil
+	}
+	s = s[len(errorPrefix):]
+	i := strings.Index(s, ": ")
+	if i < 0 {
+		return nil
+	}
+	e := &Error{Message: s[i+2:]}
+
+	attrs := strings.Split(s[:i], " ")
+	code, err := strconv.ParseUint(attrs[0], 10, 32)
+	if err != nil {
+		return nil
+	}
+	e.Code = uint32(code)
+	for j := 1; j < len(attrs); j++ {
+		switch attrs[j] {
+		case "retryable":
+			e.Retryable = true
+		case "detail":
+			j++
+			if j >= len(attrs) {
+				return nil
+			}
+			e.Detail, err = base64.RawURLEncoding.DecodeString(attrs[j])
+			if err != nil {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return e
+}
+
+// SetHeaderError applies the rpc.Response error text to h.
+func setHeaderError(h *internal.Header, text string) {
+	e := parseErrorText(text)
+	if e == nil {
+		h.Error = text
+		return
+	}
+	h.Error = e.Message
+	h.ErrorCode = e.Code
+	h.Retryable = e.Retryable
+	h.ErrorDetail = e.Detail
+}
+
+// HeaderError returns the rpc.Response error text for h.
+func headerError(h *internal.Header) string {
+	if h.ErrorCode == 0 && !h.Retryable && len(h.ErrorDetail) == 0 {
+		return h.Error
+	}
+	e := &Error{
+		Code:      h.ErrorCode,
+		Message:   h.Error,
+		Retryable: h.Retryable,
+		Detail:    h.ErrorDetail,
+	}
+	return e.Error()
+}
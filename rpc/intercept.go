@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pascaldekloe/colfer/rpc/internal"
+)
+
+// Metadata is a set of key-value pairs which travel with a call, such as
+// authentication tokens, trace IDs and tenant IDs.
+type Metadata map[string]string
+
+// Call is the interceptor view on a request.
+type Call struct {
+	// Method is the service method name.
+	Method string
+	// Seq is the sequence ID.
+	Seq uint64
+	// Body is the request payload on the client side. Servers see nil.
+	Body interface{}
+	// Request has the metadata from the client.
+	Request Metadata
+	// Response has the metadata from the server.
+	Response Metadata
+	// Start is the moment the interceptor chain was entered.
+	Start time.Time
+}
+
+// Interceptor hooks into each call, before the request is written on the client
+// side and before the request is dispatched on the server side. The metadata
+// in Call may be modified. A non-nil error aborts the call. Clients get such
+// error from rpc.Client.Call, and servers respond with the error text.
+//
+// The optional done function is invoked once the outcome is known. Clients
+// invoke done when the response header is read. Servers invoke done before the
+// response is written, such that it may still set Response metadata. Done
+// functions are invoked in reverse order of the interceptor chain.
+type Interceptor func(*Call) (done func(latency time.Duration, err error), err error)
+
+// WithInterceptors applies the chain to each call in order of appearance.
+func WithInterceptors(chain ...Interceptor) Option {
+	return func(c *codec) {
+		c.chain = append(c.chain, chain...)
+	}
+}
+
+// PendingCall is a call which went through the interceptor chain.
+type pendingCall struct {
+	call  *Call
+	dones []func(time.Duration, error)
+}
+
+// Intercept runs the chain on call. The outcome is registered as pending when
+// all interceptors pass.
+func (c *codec) intercept(call *Call) error {
+	call.Start = time.Now()
+	p := &pendingCall{call: call}
+	for _, f := range c.chain {
+		done, err := f(call)
+		if err != nil {
+			// cancel the ones which did pass
+			p.done(err)
+			return err
+		}
+		if done != nil {
+			p.dones = append(p.dones, done)
+		}
+	}
+
+	c.pendingMutex.Lock()
+	c.pending[call.Seq] = p
+	c.pendingMutex.Unlock()
+	return nil
+}
+
+// Take removes the call with seq from the pending set. The return is nil when
+// not found.
+func (c *codec) take(seq uint64) *pendingCall {
+	c.pendingMutex.Lock()
+	p := c.pending[seq]
+	delete(c.pending, seq)
+	c.pendingMutex.Unlock()
+	return p
+}
+
+// Done invokes the completion hooks.
+func (p *pendingCall) done(err error) {
+	latency := time.Since(p.call.Start)
+	for i := len(p.dones) - 1; i >= 0; i-- {
+		p.dones[i](latency, err)
+	}
+}
+
+// SetHeaderMeta applies m to h in order of key.
+func setHeaderMeta(h *internal.Header, m Metadata) {
+	if len(m) == 0 {
+		return
+	}
+	h.Meta = make([]*internal.Entry, 0, len(m))
+	for k, v := range m {
+		h.Meta = append(h.Meta, &internal.Entry{Key: k, Value: v})
+	}
+	sort.Slice(h.Meta, func(i, j int) bool {
+		return h.Meta[i].Key < h.Meta[j].Key
+	})
+}
+
+// HeaderMeta returns the metadata from h.
+func headerMeta(h *internal.Header) Metadata {
+	m := make(Metadata, len(h.Meta))
+	for _, e := range h.Meta {
+		m[e.Key] = e.Value
+	}
+	return m
+}
@@ -0,0 +1,96 @@
+package net.quies.colfer.rpc;
+
+import net.quies.colfer.rpc.internal.Header;
+
+import static java.lang.String.format;
+import java.io.Closeable;
+import java.io.EOFException;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.net.Socket;
+import java.util.InputMismatchException;
+import java.util.Map;
+
+
+/**
+ * RPC client conform the Go package github.com/pascaldekloe/colfer/rpc.
+ * Calls are executed one at a time.
+ */
+public class Client implements Closeable {
+
+	private final Codec codec;
+
+	private final Closeable conn;
+
+	/** The sequence ID of the last request. */
+	private long seq;
+
+
+	/**
+	 * @param conn the connection.
+	 * @throws IOException from the socket.
+	 */
+	public Client(Socket conn) throws IOException {
+		this(conn.getInputStream(), conn.getOutputStream(), conn);
+	}
+
+	/**
+	 * @param in the data source.
+	 * @param out the data destination.
+	 * @param conn the resource to release on {@link #close()} or {@code null}.
+	 */
+	public Client(InputStream in, OutputStream out, Closeable conn) {
+		this.codec = new Codec(in, out);
+		this.conn = conn;
+	}
+
+	/**
+	 * Invokes a remote procedure.
+	 * @param serviceMethod the name as in {@code "Service.Method"}.
+	 * @param args the request body.
+	 * @param reply the response body destination.
+	 * @throws IOException on connection failure.
+	 * @throws RPCException on a failure response.
+	 */
+	public void call(String serviceMethod, Colferable args, Colferable reply) throws IOException, RPCException {
+		call(serviceMethod, null, args, reply);
+	}
+
+	/**
+	 * Invokes a remote procedure.
+	 * @param serviceMethod the name as in {@code "Service.Method"}.
+	 * @param meta the request metadata or {@code null} for none.
+	 * @param args the request body.
+	 * @param reply the response body destination.
+	 * @return the response metadata.
+	 * @throws IOException on connection failure.
+	 * @throws RPCException on a failure response.
+	 */
+	public synchronized Map<String,String> call(String serviceMethod, Map<String,String> meta, Colferable args, Colferable reply) throws IOException, RPCException {
+		Header req = new Header();
+		req.seqID = this.seq++;
+		req.method = serviceMethod;
+		Codec.setMeta(req, meta);
+		this.codec.write(req, args);
+
+		Header res = this.codec.readHeader();
+		if (res == null) throw new EOFException("colfer/rpc: connection closed before response");
+		if (res.seqID != req.seqID)
+			throw new InputMismatchException(format("colfer/rpc: got response sequence ID %d, want %d", res.seqID, req.seqID));
+
+		RPCException e = RPCException.of(res);
+		if (e != null) {
+			this.codec.readBody(res, null);
+			throw e;
+		}
+		this.codec.readBody(res, reply);
+		return Codec.getMeta(res);
+	}
+
+	@Override
+	public void close() throws IOException {
+		if (this.conn != null) this.conn.close();
+	}
+
+}
@@ -0,0 +1,193 @@
+package net.quies.colfer.rpc;
+
+import net.quies.colfer.rpc.internal.Entry;
+import net.quies.colfer.rpc.internal.Header;
+
+import static java.lang.String.format;
+import java.io.EOFException;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.nio.BufferUnderflowException;
+import java.util.InputMismatchException;
+import java.util.Map;
+import java.util.TreeMap;
+
+
+/**
+ * Message framing conform the Go package github.com/pascaldekloe/colfer/rpc.
+ * Each message is a {@link Header} followed by a body of
+ * {@link Header#bodySize} bytes. The codec does not announce compression
+ * support, so peers never send compressed bodies.
+ */
+public class Codec {
+
+	/** The data source. */
+	private final InputStream in;
+
+	/** The data destination. */
+	private final OutputStream out;
+
+	/** The read buffer. */
+	private byte[] buf = new byte[32 * 1024];
+
+	/** The {@link #buf buffer}'s data start index, inclusive. */
+	private int offset;
+
+	/** The {@link #buf buffer}'s data end index, exclusive. */
+	private int i;
+
+
+	/**
+	 * @param in the data source.
+	 * @param out the data destination.
+	 */
+	public Codec(InputStream in, OutputStream out) {
+		this.in = in;
+		this.out = out;
+	}
+
+	/**
+	 * Writes a message.
+	 * @param header the prefix. The {@link Header#bodySize body size} is set accordingly.
+	 * @param body the payload or {@code null} for none.
+	 * @throws IOException from the output stream.
+	 */
+	public synchronized void write(Header header, Colferable body) throws IOException {
+		byte[] bodyBuf = null;
+		int bodyLen = 0;
+		if (body != null) {
+			bodyBuf = new byte[body.marshalFit()];
+			bodyLen = body.marshal(bodyBuf, 0);
+		}
+		header.bodySize = bodyLen;
+
+		byte[] headerBuf = new byte[header.marshalFit()];
+		int headerLen = header.marshal(headerBuf, 0);
+
+		out.write(headerBuf, 0, headerLen);
+		if (bodyLen != 0) out.write(bodyBuf, 0, bodyLen);
+		out.flush();
+	}
+
+	/**
+	 * Reads the next message prefix. The body must be consumed with
+	 * {@link #readBody(Header,Colferable)} before the next header.
+	 * @return the result or {@code null} when EOF.
+	 * @throws IOException from the input stream.
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws InputMismatchException when the data does not match the schema.
+	 */
+	public Header readHeader() throws IOException {
+		while (true) {
+			if (this.i > this.offset) {
+				try {
+					Header h = new Header();
+					this.offset = h.unmarshal(this.buf, this.offset, this.i);
+					return h;
+				} catch (BufferUnderflowException e) {
+				}
+			}
+			// not enough data
+
+			if (this.i <= this.offset) {
+				this.offset = 0;
+				this.i = 0;
+			} else if (this.i == this.buf.length) {
+				byte[] src = this.buf;
+				if (this.offset == 0) this.buf = new byte[Math.min(Header.colferSizeMax, this.buf.length * 4)];
+				System.arraycopy(src, this.offset, this.buf, 0, this.i - this.offset);
+				this.i -= this.offset;
+				this.offset = 0;
+			}
+
+			int n = this.in.read(this.buf, this.i, this.buf.length - this.i);
+			if (n < 0) {
+				if (this.i > this.offset)
+					throw new EOFException("colfer/rpc: pending header data with EOF");
+				return null;
+			}
+			this.i += n;
+		}
+	}
+
+	/**
+	 * Reads the payload for a header.
+	 * @param header the prefix from {@link #readHeader()}.
+	 * @param body the destination or {@code null} to discard.
+	 * @throws IOException from the input stream.
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws InputMismatchException when the data does not match the schema.
+	 */
+	public void readBody(Header header, Colferable body) throws IOException {
+		int size = header.bodySize;
+		if (size < 0 || size > Header.colferSizeMax)
+			throw new SecurityException(format("colfer/rpc: body size %d exceeds %d bytes", size & 0xffffffffL, Header.colferSizeMax));
+		if (header.compression != 0 && body != null)
+			throw new InputMismatchException(format("colfer/rpc: unsupported compression %d", header.compression));
+
+		require(size);
+		int end = this.offset + size;
+		if (body != null) {
+			int n;
+			try {
+				n = body.unmarshal(this.buf, this.offset, end);
+			} catch (BufferUnderflowException e) {
+				throw new InputMismatchException("colfer/rpc: body incomplete");
+			}
+			if (n != end)
+				throw new InputMismatchException(format("colfer/rpc: %d bytes of body data remaining", end - n));
+		}
+		this.offset = end;
+	}
+
+	/**
+	 * Reads until n bytes are available in the buffer.
+	 */
+	private void require(int n) throws IOException {
+		while (this.i - this.offset < n) {
+			if (this.offset + n > this.buf.length) {
+				byte[] src = this.buf;
+				if (n > this.buf.length) this.buf = new byte[n];
+				System.arraycopy(src, this.offset, this.buf, 0, this.i - this.offset);
+				this.i -= this.offset;
+				this.offset = 0;
+			}
+
+			int read = this.in.read(this.buf, this.i, this.buf.length - this.i);
+			if (read < 0) throw new EOFException("colfer/rpc: pending body data with EOF");
+			this.i += read;
+		}
+	}
+
+	/**
+	 * Gets the metadata.
+	 * @param header the source.
+	 * @return the key-value pairs.
+	 */
+	public static Map<String,String> getMeta(Header header) {
+		Map<String,String> m = new TreeMap<>();
+		for (Entry e : header.meta) m.put(e.key, e.value);
+		return m;
+	}
+
+	/**
+	 * Sets the metadata.
+	 * @param header the destination.
+	 * @param meta the key-value pairs or {@code null} for none.
+	 */
+	public static void setMeta(Header header, Map<String,String> meta) {
+		if (meta == null || meta.isEmpty()) return;
+
+		Map<String,String> sorted = new TreeMap<>(meta);
+		header.meta = new Entry[sorted.size()];
+		int i = 0;
+		for (Map.Entry<String,String> e : sorted.entrySet()) {
+			Entry entry = new Entry();
+			entry.key = e.getKey();
+			entry.value = e.getValue();
+			header.meta[i++] = entry;
+		}
+	}
+
+}
@@ -0,0 +1,38 @@
+package net.quies.colfer.rpc;
+
+
+/**
+ * Core marshalling API of Colfer. Generated classes comply when compiled with
+ * the {@code -i net/quies/colfer/rpc/Colferable} option.
+ */
+public interface Colferable {
+
+	/**
+	 * Gets the serial size estimate as an upper boundary.
+	 * @return the number of bytes.
+	 */
+	int marshalFit();
+
+	/**
+	 * Serializes the object.
+	 * @param buf the data destination.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws java.nio.BufferOverflowException when {@code buf} is too small.
+	 * @throws IllegalStateException on an upper limit breach.
+	 */
+	int marshal(byte[] buf, int offset);
+
+	/**
+	 * Deserializes the object.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws java.nio.BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws java.util.InputMismatchException when the data does not match the schema.
+	 */
+	int unmarshal(byte[] buf, int offset, int end);
+
+}
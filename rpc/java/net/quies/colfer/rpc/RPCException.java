@@ -0,0 +1,78 @@
+package net.quies.colfer.rpc;
+
+import net.quies.colfer.rpc.internal.Header;
+
+
+/**
+ * Structured failure conform the Go type github.com/pascaldekloe/colfer/rpc.Error.
+ */
+public class RPCException extends Exception {
+
+	private static final long serialVersionUID = 1L;
+
+	/** Classification with zero for unspecified. */
+	public final int code;
+
+	/** Whether the call may succeed when repeated. */
+	public final boolean retryable;
+
+	/** Optional Colfer serial, never {@code null}. */
+	public final byte[] detail;
+
+
+	/**
+	 * @param code the classification with zero for unspecified.
+	 * @param message the description.
+	 * @param retryable whether the call may succeed when repeated.
+	 * @param detail the optional Colfer serial or {@code null}.
+	 */
+	public RPCException(int code, String message, boolean retryable, byte[] detail) {
+		super(message);
+		this.code = code;
+		this.retryable = retryable;
+		this.detail = detail == null ? new byte[0] : detail;
+	}
+
+	/**
+	 * @param message the description.
+	 */
+	public RPCException(String message) {
+		this(0, message, false, null);
+	}
+
+	/**
+	 * Gets the failure from a response header.
+	 * @param header the source.
+	 * @return the failure or {@code null} when none.
+	 */
+	public static RPCException of(Header header) {
+		if (header.error.isEmpty() && header.errorCode == 0 && ! header.retryable && header.errorDetail.length == 0)
+			return null;
+		return new RPCException(header.errorCode, header.error, header.retryable, header.errorDetail);
+	}
+
+	/**
+	 * Sets the failure on a response header.
+	 * @param header the destination.
+	 */
+	public void applyTo(Header header) {
+		String message = getMessage();
+		header.error = message == null ? "" : message;
+		header.errorCode = this.code;
+		header.retryable = this.retryable;
+		header.errorDetail = this.detail;
+	}
+
+	/**
+	 * Gets the Colfer payload.
+	 * @param dst the destination.
+	 * @return {@code dst}.
+	 * @throws IllegalStateException when there is no detail.
+	 */
+	public <T extends Colferable> T getDetail(T dst) {
+		if (this.detail.length == 0) throw new IllegalStateException("colfer/rpc: exception has no detail");
+		dst.unmarshal(this.detail, 0, this.detail.length);
+		return dst;
+	}
+
+}
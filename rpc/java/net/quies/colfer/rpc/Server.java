@@ -0,0 +1,123 @@
+package net.quies.colfer.rpc;
+
+import net.quies.colfer.rpc.internal.Header;
+
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.net.Socket;
+import java.util.Map;
+import java.util.concurrent.ConcurrentHashMap;
+import java.util.function.Supplier;
+
+
+/**
+ * RPC server conform the Go package github.com/pascaldekloe/colfer/rpc.
+ * Requests on a connection are served in order of arrival.
+ */
+public class Server {
+
+	/**
+	 * Remote procedure implementation.
+	 * @param <A> the request body type.
+	 */
+	@FunctionalInterface
+	public interface Handler<A extends Colferable> {
+
+		/**
+		 * Serves a call.
+		 * @param args the request body.
+		 * @param meta the request metadata.
+		 * @return the response body.
+		 * @throws RPCException for a structured failure response.
+		 * @throws Exception for a failure response with the message only.
+		 */
+		Colferable serve(A args, Map<String,String> meta) throws Exception;
+
+	}
+
+	/** Registration entry. */
+	private static final class Method<A extends Colferable> {
+
+		final Supplier<A> argsFactory;
+
+		final Handler<A> handler;
+
+		Method(Supplier<A> argsFactory, Handler<A> handler) {
+			this.argsFactory = argsFactory;
+			this.handler = handler;
+		}
+
+		/**
+		 * Serves a request. Failures are set on the response header.
+		 * @return the response body or {@code null} on failure.
+		 */
+		Colferable call(Codec codec, Header req, Header res) throws IOException {
+			A args = this.argsFactory.get();
+			codec.readBody(req, args);
+
+			try {
+				return this.handler.serve(args, Codec.getMeta(req));
+			} catch (RPCException e) {
+				e.applyTo(res);
+			} catch (Exception e) {
+				res.error = String.valueOf(e.getMessage());
+			}
+			return null;
+		}
+
+	}
+
+	private final Map<String,Method<?>> methods = new ConcurrentHashMap<>();
+
+
+	/**
+	 * Installs a remote procedure.
+	 * @param serviceMethod the name as in {@code "Service.Method"}.
+	 * @param argsFactory the request body instantiation.
+	 * @param handler the implementation.
+	 */
+	public <A extends Colferable> void register(String serviceMethod, Supplier<A> argsFactory, Handler<A> handler) {
+		this.methods.put(serviceMethod, new Method<>(argsFactory, handler));
+	}
+
+	/**
+	 * Serves a connection until EOF, and closes the socket.
+	 * @param conn the connection.
+	 * @throws IOException on connection failure.
+	 */
+	public void serve(Socket conn) throws IOException {
+		try {
+			serve(conn.getInputStream(), conn.getOutputStream());
+		} finally {
+			conn.close();
+		}
+	}
+
+	/**
+	 * Serves a connection until EOF.
+	 * @param in the data source.
+	 * @param out the data destination.
+	 * @throws IOException on connection failure.
+	 */
+	public void serve(InputStream in, OutputStream out) throws IOException {
+		Codec codec = new Codec(in, out);
+		for (Header req; (req = codec.readHeader()) != null; ) {
+			Header res = new Header();
+			res.seqID = req.seqID;
+			res.method = req.method;
+
+			Method<?> m = this.methods.get(req.method);
+			if (m == null) {
+				codec.readBody(req, null);
+				res.error = "rpc: can't find method " + req.method;
+				codec.write(res, null);
+				continue;
+			}
+
+			Colferable reply = m.call(codec, req, res);
+			codec.write(res, reply);
+		}
+	}
+
+}
@@ -0,0 +1,1124 @@
+package net.quies.colfer.rpc.internal;
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file internal.colf.
+
+
+import static java.lang.String.format;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.ObjectInputStream;
+import java.io.ObjectOutputStream;
+import java.io.ObjectStreamException;
+import java.io.OutputStream;
+import java.io.Serializable;
+import java.nio.charset.StandardCharsets;
+import java.util.InputMismatchException;
+import java.nio.BufferOverflowException;
+import java.nio.BufferUnderflowException;
+import java.nio.ByteBuffer;
+import java.nio.channels.ReadableByteChannel;
+
+
+/**
+ * Data bean with built-in serialization support.
+ * Header is a prefix for requests and responses.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public class Header implements Serializable {
+
+	/** The upper limit for serial byte sizes. */
+	public static int colferSizeMax = 16 * 1024 * 1024;
+
+	/** The upper limit for the number of elements in a list. */
+	public static int colferListMax = 64 * 1024;
+
+
+	public long seqID;
+
+	public String method;
+
+	public String error;
+
+	public int bodySize;
+
+	/**
+	 * ErrorCode classifies Error. Zero is unspecified.
+	 */
+	public int errorCode;
+
+	/**
+	 * Retryable flags whether the call may succeed when repeated.
+	 */
+	public boolean retryable;
+
+	/**
+	 * ErrorDetail is an optional Colfer serial.
+	 */
+	public byte[] errorDetail;
+
+	/**
+	 * Meta has the call metadata in order of key.
+	 */
+	public Entry[] meta;
+
+	/**
+	 * Compression is the body encoding. Zero is none and one is flate.
+	 */
+	public byte compression;
+
+	/**
+	 * RawSize is the BodySize before compression.
+	 */
+	public int rawSize;
+
+	/**
+	 * Accept has the supported compressions as a bit set [1 << Compression].
+	 */
+	public byte accept;
+
+	/** Default constructor */
+	public Header() {
+		init();
+	}
+
+	private static final byte[] _zeroBytes = new byte[0];
+	private static final Entry[] _zeroMeta = new Entry[0];
+
+	/** Colfer zero values. */
+	private void init() {
+		method = "";
+		error = "";
+		errorDetail = _zeroBytes;
+		meta = _zeroMeta;
+	}
+
+	/**
+	 * {@link #reset(InputStream) Reusable} deserialization of Colfer streams.
+	 */
+	public static class Unmarshaller {
+
+		/** The data source. */
+		protected InputStream in;
+
+		/** The read buffer. */
+		public byte[] buf;
+
+		/** The {@link #buf buffer}'s data start index, inclusive. */
+		protected int offset;
+
+		/** The {@link #buf buffer}'s data end index, exclusive. */
+		protected int i;
+
+
+		/**
+		 * @param in the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 */
+		public Unmarshaller(InputStream in, byte[] buf) {
+			if (buf == null || buf.length == 0)
+				buf = new byte[Math.min(Header.colferSizeMax, 2048)];
+			this.buf = buf;
+			reset(in);
+		}
+
+		/**
+		 * Reuses the marshaller.
+		 * @param in the data source or {@code null}.
+		 * @throws IllegalStateException on pending data.
+		 */
+		public void reset(InputStream in) {
+			if (this.i != this.offset) throw new IllegalStateException("colfer: pending data");
+			this.in = in;
+			this.offset = 0;
+			this.i = 0;
+		}
+
+		/**
+		 * Deserializes the following object.
+		 * @return the result or {@code null} when no data is available,
+		 * which is either EOF or, for a non-blocking {@link ChannelUnmarshaller},
+		 * no data at the moment; see {@link ChannelUnmarshaller#isEOF()}.
+		 * @throws IOException from the input stream.
+		 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+		 * @throws InputMismatchException when the data does not match this object's schema.
+		 */
+		public Header next() throws IOException {
+			while (true) {
+				if (this.i > this.offset) {
+					try {
+						Header o = new Header();
+						this.offset = o.unmarshal(this.buf, this.offset, this.i);
+						return o;
+					} catch (BufferUnderflowException e) {
+					}
+				}
+				// not enough data
+
+				if (this.i <= this.offset) {
+					this.offset = 0;
+					this.i = 0;
+				} else if (i == buf.length) {
+					byte[] src = this.buf;
+					if (offset == 0) this.buf = new byte[Math.min(Header.colferSizeMax, this.buf.length * 4)];
+					System.arraycopy(src, this.offset, this.buf, 0, this.i - this.offset);
+					this.i -= this.offset;
+					this.offset = 0;
+				}
+				assert this.i < this.buf.length;
+
+				int n = read(buf, i, buf.length - i);
+				if (n < 0) {
+					if (this.i > this.offset)
+						throw new InputMismatchException("colfer: pending data with EOF");
+					return null;
+				}
+				if (n == 0) return null;
+				i += n;
+			}
+		}
+
+		/**
+		 * Reads from the data source.
+		 * @param buf the destination.
+		 * @param off the initial index for {@code buf}, inclusive.
+		 * @param len the maximum number of bytes.
+		 * @return the number of bytes read, or -1 when EOF, or 0 when no
+		 * data is available at the moment.
+		 * @throws IOException from the data source.
+		 */
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (in == null) return -1;
+			return in.read(buf, off, len);
+		}
+
+	}
+
+	/**
+	 * {@link #reset(ReadableByteChannel) Reusable} deserialization of Colfer
+	 * streams from a channel. Data is read into the buffer directly. With a
+	 * non-blocking channel, {@link #next() next} may return {@code null} when
+	 * no more data is available at the moment; see {@link #isEOF()}.
+	 */
+	public static class ChannelUnmarshaller extends Unmarshaller {
+
+		/** The data source. */
+		protected ReadableByteChannel channel;
+
+		/** Whether {@link #channel} reached EOF. */
+		protected boolean eof;
+
+		/**
+		 * @param channel the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 */
+		public ChannelUnmarshaller(ReadableByteChannel channel, byte[] buf) {
+			super(null, buf);
+			reset(channel);
+		}
+
+		/**
+		 * Reuses the marshaller.
+		 * @param channel the data source or {@code null}.
+		 * @throws IllegalStateException on pending data.
+		 */
+		public void reset(ReadableByteChannel channel) {
+			reset((InputStream) null);
+			this.channel = channel;
+			this.eof = channel == null;
+		}
+
+		/**
+		 * Gets whether the data source is exhausted.
+		 * @return whether the channel reached EOF.
+		 */
+		public boolean isEOF() {
+			return this.eof;
+		}
+
+		@Override
+		protected int read(byte[] buf, int off, int len) throws IOException {
+			if (this.eof) return -1;
+			int n = this.channel.read(ByteBuffer.wrap(buf, off, len));
+			if (n < 0) this.eof = true;
+			return n;
+		}
+
+	}
+
+	/**
+	 * Gets the serial size estimate as an upper boundary, whereby
+	 * {@link #marshal(byte[],int)} ≤ {@link #marshalFit()} ≤ {@link #colferSizeMax}.
+	 * @return the number of bytes.
+	 */
+	public int marshalFit() {
+		long n = 1L + 9 + 6 + (long)this.method.length() * 3 + 6 + (long)this.error.length() * 3 + 5 + 5 + 1 + 6 + (long)this.errorDetail.length + 6 + 2 + 5 + 2;
+		for (Entry o : this.meta) {
+			if (o == null) n++;
+			else n += o.marshalFit();
+		}
+		if (n < 0 || n > (long)Header.colferSizeMax) return Header.colferSizeMax;
+		return (int) n;
+	}
+
+	/**
+	 * Serializes the object.
+	 * All {@code null} elements in {@link #meta} will be replaced with a {@code new} value.
+	 * @param out the data destination.
+	 * @param buf the initial buffer or {@code null}.
+	 * @return the final buffer. When the serial fits into {@code buf} then the return is {@code buf}.
+	 *  Otherwise the return is a new buffer, large enough to hold the whole serial.
+	 * @throws IOException from {@code out}.
+	 * @throws IllegalStateException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 */
+	public byte[] marshal(OutputStream out, byte[] buf) throws IOException {
+		int n = 0;
+		if (buf != null && buf.length != 0) try {
+			n = marshal(buf, 0);
+		} catch (BufferOverflowException e) {}
+		if (n == 0) {
+			buf = new byte[marshalFit()];
+			n = marshal(buf, 0);
+		}
+		out.write(buf, 0, n);
+		return buf;
+	}
+
+	/**
+	 * Serializes the object into the remaining space of {@code buf}, and it
+	 * advances the position accordingly. Heap buffers are written in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+	 * All {@code null} elements in {@link #meta} will be replaced with a {@code new} value.
+	 * @param buf the data destination.
+	 * @return the number of bytes written.
+	 * @throws BufferOverflowException when {@code buf} has insufficient space remaining.
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws java.nio.ReadOnlyBufferException when {@code buf} is read-only.
+	 * @throws IllegalStateException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 */
+	public int marshal(ByteBuffer buf) {
+		int fit = marshalFit();
+		if (buf.hasArray() && buf.remaining() >= fit) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = marshal(buf.array(), offset) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[fit];
+		int n = marshal(a, 0);
+		buf.put(a, 0, n);
+		return n;
+	}
+
+	/**
+	 * Serializes the object.
+	 * All {@code null} elements in {@link #meta} will be replaced with a {@code new} value.
+	 * @param buf the data destination.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferOverflowException when {@code buf} is too small.
+	 * @throws IllegalStateException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 */
+	public int marshal(byte[] buf, int offset) {
+		int i = offset;
+
+		try {
+			if (this.seqID != 0) {
+				long x = this.seqID;
+				if ((x & ~((1L << 49) - 1)) != 0) {
+					buf[i++] = (byte) (0 | 0x80);
+					buf[i++] = (byte) (x >>> 56);
+					buf[i++] = (byte) (x >>> 48);
+					buf[i++] = (byte) (x >>> 40);
+					buf[i++] = (byte) (x >>> 32);
+					buf[i++] = (byte) (x >>> 24);
+					buf[i++] = (byte) (x >>> 16);
+					buf[i++] = (byte) (x >>> 8);
+					buf[i++] = (byte) (x);
+				} else {
+					buf[i++] = (byte) 0;
+					while (x > 0x7fL) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
+				}
+			}
+
+			if (! this.method.isEmpty()) {
+				buf[i++] = (byte) 1;
+				int start = ++i;
+
+				String s = this.method;
+				for (int sIndex = 0, sLength = s.length(); sIndex < sLength; sIndex++) {
+					char c = s.charAt(sIndex);
+					if (c < '\u0080') {
+						buf[i++] = (byte) c;
+					} else if (c < '\u0800') {
+						buf[i++] = (byte) (192 | c >>> 6);
+						buf[i++] = (byte) (128 | c & 63);
+					} else if (c < '\ud800' || c > '\udfff') {
+						buf[i++] = (byte) (224 | c >>> 12);
+						buf[i++] = (byte) (128 | c >>> 6 & 63);
+						buf[i++] = (byte) (128 | c & 63);
+					} else {
+						int cp = 0;
+						if (++sIndex < sLength) cp = Character.toCodePoint(c, s.charAt(sIndex));
+						if ((cp >= 1 << 16) && (cp < 1 << 21)) {
+							buf[i++] = (byte) (240 | cp >>> 18);
+							buf[i++] = (byte) (128 | cp >>> 12 & 63);
+							buf[i++] = (byte) (128 | cp >>> 6 & 63);
+							buf[i++] = (byte) (128 | cp & 63);
+						} else
+							buf[i++] = (byte) '?';
+					}
+				}
+				int size = i - start;
+				if (size > Header.colferSizeMax)
+					throw new IllegalStateException(format("colfer: net/quies/colfer/rpc/internal.header.method size %d exceeds %d UTF-8 bytes", size, Header.colferSizeMax));
+
+				int ii = start - 1;
+				if (size > 0x7f) {
+					i++;
+					for (int x = size; x >= 1 << 14; x >>>= 7) i++;
+					System.arraycopy(buf, start, buf, i - size, size);
+
+					do {
+						buf[ii++] = (byte) (size | 0x80);
+						size >>>= 7;
+					} while (size > 0x7f);
+				}
+				buf[ii] = (byte) size;
+			}
+
+			if (! this.error.isEmpty()) {
+				buf[i++] = (byte) 2;
+				int start = ++i;
+
+				String s = this.error;
+				for (int sIndex = 0, sLength = s.length(); sIndex < sLength; sIndex++) {
+					char c = s.charAt(sIndex);
+					if (c < '\u0080') {
+						buf[i++] = (byte) c;
+					} else if (c < '\u0800') {
+						buf[i++] = (byte) (192 | c >>> 6);
+						buf[i++] = (byte) (128 | c & 63);
+					} else if (c < '\ud800' || c > '\udfff') {
+						buf[i++] = (byte) (224 | c >>> 12);
+						buf[i++] = (byte) (128 | c >>> 6 & 63);
+						buf[i++] = (byte) (128 | c & 63);
+					} else {
+						int cp = 0;
+						if (++sIndex < sLength) cp = Character.toCodePoint(c, s.charAt(sIndex));
+						if ((cp >= 1 << 16) && (cp < 1 << 21)) {
+							buf[i++] = (byte) (240 | cp >>> 18);
+							buf[i++] = (byte) (128 | cp >>> 12 & 63);
+							buf[i++] = (byte) (128 | cp >>> 6 & 63);
+							buf[i++] = (byte) (128 | cp & 63);
+						} else
+							buf[i++] = (byte) '?';
+					}
+				}
+				int size = i - start;
+				if (size > Header.colferSizeMax)
+					throw new IllegalStateException(format("colfer: net/quies/colfer/rpc/internal.header.error size %d exceeds %d UTF-8 bytes", size, Header.colferSizeMax));
+
+				int ii = start - 1;
+				if (size > 0x7f) {
+					i++;
+					for (int x = size; x >= 1 << 14; x >>>= 7) i++;
+					System.arraycopy(buf, start, buf, i - size, size);
+
+					do {
+						buf[ii++] = (byte) (size | 0x80);
+						size >>>= 7;
+					} while (size > 0x7f);
+				}
+				buf[ii] = (byte) size;
+			}
+
+			if (this.bodySize != 0) {
+				int x = this.bodySize;
+				if ((x & ~((1 << 21) - 1)) != 0) {
+					buf[i++] = (byte) (3 | 0x80);
+					buf[i++] = (byte) (x >>> 24);
+					buf[i++] = (byte) (x >>> 16);
+					buf[i++] = (byte) (x >>> 8);
+				} else {
+					buf[i++] = (byte) 3;
+					while (x > 0x7f) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+				}
+				buf[i++] = (byte) x;
+			}
+
+			if (this.errorCode != 0) {
+				int x = this.errorCode;
+				if ((x & ~((1 << 21) - 1)) != 0) {
+					buf[i++] = (byte) (4 | 0x80);
+					buf[i++] = (byte) (x >>> 24);
+					buf[i++] = (byte) (x >>> 16);
+					buf[i++] = (byte) (x >>> 8);
+				} else {
+					buf[i++] = (byte) 4;
+					while (x > 0x7f) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+				}
+				buf[i++] = (byte) x;
+			}
+
+			if (this.retryable) {
+				buf[i++] = (byte) 5;
+			}
+
+			if (this.errorDetail.length != 0) {
+				buf[i++] = (byte) 6;
+
+				int size = this.errorDetail.length;
+				if (size > Header.colferSizeMax)
+					throw new IllegalStateException(format("colfer: net/quies/colfer/rpc/internal.header.errorDetail size %d exceeds %d bytes", size, Header.colferSizeMax));
+
+				int x = size;
+				while (x > 0x7f) {
+					buf[i++] = (byte) (x | 0x80);
+					x >>>= 7;
+				}
+				buf[i++] = (byte) x;
+
+				int start = i;
+				i += size;
+				System.arraycopy(this.errorDetail, 0, buf, start, size);
+			}
+
+			if (this.meta.length != 0) {
+				buf[i++] = (byte) 7;
+				Entry[] a = this.meta;
+
+				int x = a.length;
+				if (x > Header.colferListMax)
+					throw new IllegalStateException(format("colfer: net/quies/colfer/rpc/internal.header.meta length %d exceeds %d elements", x, Header.colferListMax));
+				while (x > 0x7f) {
+					buf[i++] = (byte) (x | 0x80);
+					x >>>= 7;
+				}
+				buf[i++] = (byte) x;
+
+				for (int ai = 0; ai < a.length; ai++) {
+					Entry o = a[ai];
+					if (o == null) {
+						o = new Entry();
+						a[ai] = o;
+					}
+					i = o.marshal(buf, i);
+				}
+			}
+
+			if (this.compression != 0) {
+				buf[i++] = (byte) 8;
+				buf[i++] = this.compression;
+			}
+
+			if (this.rawSize != 0) {
+				int x = this.rawSize;
+				if ((x & ~((1 << 21) - 1)) != 0) {
+					buf[i++] = (byte) (9 | 0x80);
+					buf[i++] = (byte) (x >>> 24);
+					buf[i++] = (byte) (x >>> 16);
+					buf[i++] = (byte) (x >>> 8);
+				} else {
+					buf[i++] = (byte) 9;
+					while (x > 0x7f) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+				}
+				buf[i++] = (byte) x;
+			}
+
+			if (this.accept != 0) {
+				buf[i++] = (byte) 10;
+				buf[i++] = this.accept;
+			}
+
+			buf[i++] = (byte) 0x7f;
+			return i;
+		} catch (ArrayIndexOutOfBoundsException e) {
+			if (i - offset > Header.colferSizeMax)
+				throw new IllegalStateException(format("colfer: net/quies/colfer/rpc/internal.header exceeds %d bytes", Header.colferSizeMax));
+			if (i > buf.length) throw new BufferOverflowException();
+			throw e;
+		}
+	}
+
+	/**
+	 * Deserializes the object.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset) {
+		return unmarshal(buf, offset, buf.length);
+	}
+
+	/**
+	 * Deserializes the object from the remaining content of {@code buf}, and
+	 * it advances the position accordingly. Heap buffers are read in place.
+	 * Direct buffers and read-only buffers get a bulk copy.
+	 * @param buf the data source.
+	 * @return the number of bytes read.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 *  The position of {@code buf} is not changed in such case.
+	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(ByteBuffer buf) {
+		if (buf.hasArray()) {
+			int offset = buf.arrayOffset() + buf.position();
+			int n = unmarshal(buf.array(), offset, buf.arrayOffset() + buf.limit()) - offset;
+			buf.position(buf.position() + n);
+			return n;
+		}
+
+		byte[] a = new byte[Math.min(buf.remaining(), Header.colferSizeMax)];
+		buf.duplicate().get(a);
+		int n = unmarshal(a, 0);
+		buf.position(buf.position() + n);
+		return n;
+	}
+
+	/**
+	 * Deserializes the object.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset, int end) {
+		if (end > buf.length) end = buf.length;
+		int i = offset;
+
+		try {
+			byte header = buf[i++];
+
+			if (header == (byte) 0) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.seqID = x;
+				header = buf[i++];
+			} else if (header == (byte) (0 | 0x80)) {
+				this.seqID = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 1) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > Header.colferSizeMax)
+					throw new SecurityException(format("colfer: net/quies/colfer/rpc/internal.header.method size %d exceeds %d UTF-8 bytes", size, Header.colferSizeMax));
+
+				int start = i;
+				i += size;
+				this.method = new String(buf, start, size, StandardCharsets.UTF_8);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 2) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > Header.colferSizeMax)
+					throw new SecurityException(format("colfer: net/quies/colfer/rpc/internal.header.error size %d exceeds %d UTF-8 bytes", size, Header.colferSizeMax));
+
+				int start = i;
+				i += size;
+				this.error = new String(buf, start, size, StandardCharsets.UTF_8);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 3) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.bodySize = x;
+				header = buf[i++];
+			} else if (header == (byte) (3 | 0x80)) {
+				this.bodySize = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 4) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.errorCode = x;
+				header = buf[i++];
+			} else if (header == (byte) (4 | 0x80)) {
+				this.errorCode = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 5) {
+				this.retryable = true;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 6) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > Header.colferSizeMax)
+					throw new SecurityException(format("colfer: net/quies/colfer/rpc/internal.header.errorDetail size %d exceeds %d bytes", size, Header.colferSizeMax));
+
+				this.errorDetail = new byte[size];
+				int start = i;
+				i += size;
+				System.arraycopy(buf, start, this.errorDetail, 0, size);
+
+				header = buf[i++];
+			}
+
+			if (header == (byte) 7) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > Header.colferListMax)
+					throw new SecurityException(format("colfer: net/quies/colfer/rpc/internal.header.meta length %d exceeds %d elements", length, Header.colferListMax));
+
+				Entry[] a = new Entry[length];
+				for (int ai = 0; ai < length; ai++) {
+					Entry o = new Entry();
+					i = o.unmarshal(buf, i, end);
+					a[ai] = o;
+				}
+				this.meta = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 8) {
+				this.compression = buf[i++];
+				header = buf[i++];
+			}
+
+			if (header == (byte) 9) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.rawSize = x;
+				header = buf[i++];
+			} else if (header == (byte) (9 | 0x80)) {
+				this.rawSize = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 10) {
+				this.accept = buf[i++];
+				header = buf[i++];
+			}
+
+			if (header != (byte) 0x7f)
+				throw new InputMismatchException(format("colfer: unknown header at byte %d", i - 1));
+		} finally {
+			if (i > end && end - offset < Header.colferSizeMax) throw new BufferUnderflowException();
+			if (i < 0 || i - offset > Header.colferSizeMax)
+				throw new SecurityException(format("colfer: net/quies/colfer/rpc/internal.header exceeds %d bytes", Header.colferSizeMax));
+			if (i > end) throw new BufferUnderflowException();
+		}
+
+		return i;
+	}
+
+	// {@link Serializable} version number.
+	private static final long serialVersionUID = 11L;
+
+	// {@link Serializable} Colfer extension.
+	private void writeObject(ObjectOutputStream out) throws IOException {
+		byte[] buf = new byte[marshalFit()];
+		int n = marshal(buf, 0);
+		out.writeInt(n);
+		out.write(buf, 0, n);
+	}
+
+	// {@link Serializable} Colfer extension.
+	private void readObject(ObjectInputStream in) throws ClassNotFoundException, IOException {
+		init();
+
+		int n = in.readInt();
+		byte[] buf = new byte[n];
+		in.readFully(buf);
+		unmarshal(buf, 0);
+	}
+
+	// {@link Serializable} Colfer extension.
+	private void readObjectNoData() throws ObjectStreamException {
+		init();
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.seqID.
+	 * @return the value.
+	 */
+	public long getSeqID() {
+		return this.seqID;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.seqID.
+	 * @param value the replacement.
+	 */
+	public void setSeqID(long value) {
+		this.seqID = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.seqID.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withSeqID(long value) {
+		this.seqID = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.method.
+	 * @return the value.
+	 */
+	public String getMethod() {
+		return this.method;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.method.
+	 * @param value the replacement.
+	 */
+	public void setMethod(String value) {
+		this.method = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.method.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withMethod(String value) {
+		this.method = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.error.
+	 * @return the value.
+	 */
+	public String getError() {
+		return this.error;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.error.
+	 * @param value the replacement.
+	 */
+	public void setError(String value) {
+		this.error = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.error.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withError(String value) {
+		this.error = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.bodySize.
+	 * @return the value.
+	 */
+	public int getBodySize() {
+		return this.bodySize;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.bodySize.
+	 * @param value the replacement.
+	 */
+	public void setBodySize(int value) {
+		this.bodySize = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.bodySize.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withBodySize(int value) {
+		this.bodySize = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.errorCode.
+	 * @return the value.
+	 */
+	public int getErrorCode() {
+		return this.errorCode;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.errorCode.
+	 * @param value the replacement.
+	 */
+	public void setErrorCode(int value) {
+		this.errorCode = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.errorCode.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withErrorCode(int value) {
+		this.errorCode = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.retryable.
+	 * @return the value.
+	 */
+	public boolean getRetryable() {
+		return this.retryable;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.retryable.
+	 * @param value the replacement.
+	 */
+	public void setRetryable(boolean value) {
+		this.retryable = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.retryable.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withRetryable(boolean value) {
+		this.retryable = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.errorDetail.
+	 * @return the value.
+	 */
+	public byte[] getErrorDetail() {
+		return this.errorDetail;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.errorDetail.
+	 * @param value the replacement.
+	 */
+	public void setErrorDetail(byte[] value) {
+		this.errorDetail = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.errorDetail.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withErrorDetail(byte[] value) {
+		this.errorDetail = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.meta.
+	 * @return the value.
+	 */
+	public Entry[] getMeta() {
+		return this.meta;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.meta.
+	 * @param value the replacement.
+	 */
+	public void setMeta(Entry[] value) {
+		this.meta = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.meta.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withMeta(Entry[] value) {
+		this.meta = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.compression.
+	 * @return the value.
+	 */
+	public byte getCompression() {
+		return this.compression;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.compression.
+	 * @param value the replacement.
+	 */
+	public void setCompression(byte value) {
+		this.compression = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.compression.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withCompression(byte value) {
+		this.compression = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.rawSize.
+	 * @return the value.
+	 */
+	public int getRawSize() {
+		return this.rawSize;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.rawSize.
+	 * @param value the replacement.
+	 */
+	public void setRawSize(int value) {
+		this.rawSize = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.rawSize.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withRawSize(int value) {
+		this.rawSize = value;
+		return this;
+	}
+
+	/**
+	 * Gets net/quies/colfer/rpc/internal.header.accept.
+	 * @return the value.
+	 */
+	public byte getAccept() {
+		return this.accept;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.accept.
+	 * @param value the replacement.
+	 */
+	public void setAccept(byte value) {
+		this.accept = value;
+	}
+
+	/**
+	 * Sets net/quies/colfer/rpc/internal.header.accept.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public Header withAccept(byte value) {
+		this.accept = value;
+		return this;
+	}
+
+	@Override
+	public final int hashCode() {
+		int h = 1;
+		h = 31 * h + (int)(this.seqID ^ this.seqID >>> 32);
+		if (this.method != null) h = 31 * h + this.method.hashCode();
+		if (this.error != null) h = 31 * h + this.error.hashCode();
+		h = 31 * h + this.bodySize;
+		h = 31 * h + this.errorCode;
+		h = 31 * h + (this.retryable ? 1231 : 1237);
+		for (byte b : this.errorDetail) h = 31 * h + b;
+		for (Entry o : this.meta) h = 31 * h + (o == null ? 0 : o.hashCode());
+		h = 31 * h + (this.compression & 0xff);
+		h = 31 * h + this.rawSize;
+		h = 31 * h + (this.accept & 0xff);
+		return h;
+	}
+
+	@Override
+	public final boolean equals(Object o) {
+		return o instanceof Header && equals((Header) o);
+	}
+
+	public final boolean equals(Header o) {
+		if (o == null) return false;
+		if (o == this) return true;
+
+		return this.seqID == o.seqID
+			&& (this.method == null ? o.method == null : this.method.equals(o.method))
+			&& (this.error == null ? o.error == null : this.error.equals(o.error))
+			&& this.bodySize == o.bodySize
+			&& this.errorCode == o.errorCode
+			&& this.retryable == o.retryable
+			&& java.util.Arrays.equals(this.errorDetail, o.errorDetail)
+			&& java.util.Arrays.equals(this.meta, o.meta)
+			&& this.compression == o.compression
+			&& this.rawSize == o.rawSize
+			&& this.accept == o.accept;
+	}
+
+}
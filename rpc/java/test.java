@@ -0,0 +1,160 @@
+import gen.O;
+import net.quies.colfer.rpc.Client;
+import net.quies.colfer.rpc.Codec;
+import net.quies.colfer.rpc.RPCException;
+import net.quies.colfer.rpc.Server;
+import net.quies.colfer.rpc.internal.Header;
+
+import java.io.ByteArrayInputStream;
+import java.io.ByteArrayOutputStream;
+import java.io.PipedInputStream;
+import java.io.PipedOutputStream;
+import java.math.BigInteger;
+import java.net.Socket;
+import java.util.Collections;
+import java.util.Map;
+
+
+public class test {
+
+	static boolean testSuccess = true;
+
+
+	/**
+	 * @param args the host and port of a Go server with the Echo service.
+	 */
+	public static void main(String[] args) {
+		try {
+			frames();
+			goServer(args[0], Integer.parseInt(args[1]));
+			javaServer();
+		} catch (Exception e) {
+			e.printStackTrace();
+			System.exit(1);
+		}
+
+		if (! testSuccess) System.exit(2);
+	}
+
+	static void fail(String format, Object... args) {
+		format += "\n";
+		System.err.printf(format, args);
+
+		testSuccess = false;
+	}
+
+	// Frames must match javaFrames in java_test.go.
+	static void frames() throws Exception {
+		ByteArrayOutputStream out = new ByteArrayOutputStream();
+		Codec codec = new Codec(new ByteArrayInputStream(new byte[0]), out);
+		O body = new O();
+		body.b = true;
+
+		Header h = new Header();
+		h.seqID = 1;
+		h.method = "Echo.Echo";
+		codec.write(h, body);
+		checkFrame("request", out, "000101094563686f2e4563686f03027f" + "007f");
+
+		h = new Header();
+		h.seqID = 2;
+		h.method = "Echo.Echo";
+		Codec.setMeta(h, Collections.singletonMap("trace", "T1"));
+		codec.write(h, body);
+		checkFrame("request with metadata", out, "000201094563686f2e4563686f0302070100057472616365010254317f7f" + "007f");
+
+		h = new Header();
+		h.seqID = 3;
+		h.method = "Echo.Fail";
+		new RPCException(404, "not found", true, null).applyTo(h);
+		codec.write(h, null);
+		checkFrame("failure response", out, "000301094563686f2e4661696c02096e6f7420666f756e64049403057f");
+	}
+
+	static void checkFrame(String desc, ByteArrayOutputStream out, String want) {
+		byte[] bytes = out.toByteArray();
+		out.reset();
+		String got = new BigInteger(1, bytes).toString(16);
+		while (bytes.length * 2 > got.length())
+			got = "0" + got;
+		if (! got.equals(want))
+			fail("%s: got 0x%s, want 0x%s", desc, got, want);
+	}
+
+	static void goServer(String host, int port) throws Exception {
+		try (Client client = new Client(new Socket(host, port))) {
+			calls("Go server", client);
+		}
+	}
+
+	static void javaServer() throws Exception {
+		Server server = new Server();
+		server.register("Echo.Echo", O::new, (arg, meta) -> arg);
+		server.register("Echo.Fail", O::new, (arg, meta) -> {
+			throw new RPCException(arg.u32, arg.s, arg.b, null);
+		});
+
+		PipedInputStream serverIn = new PipedInputStream();
+		PipedOutputStream clientOut = new PipedOutputStream(serverIn);
+		PipedInputStream clientIn = new PipedInputStream();
+		PipedOutputStream serverOut = new PipedOutputStream(clientIn);
+
+		Thread t = new Thread(() -> {
+			try {
+				server.serve(serverIn, serverOut);
+				serverOut.close();
+			} catch (Exception e) {
+				e.printStackTrace();
+				System.exit(1);
+			}
+		});
+		t.start();
+
+		try (Client client = new Client(clientIn, clientOut, clientOut)) {
+			calls("Java server", client);
+		}
+		t.join();
+	}
+
+	static void calls(String desc, Client client) throws Exception {
+		O arg = new O();
+		arg.s = "hello";
+		arg.u64 = 42L;
+		arg.os = new O[] {new O()};
+		O reply = new O();
+		Map<String,String> meta = client.call("Echo.Echo", Collections.singletonMap("trace", "T1"), arg, reply);
+		if (! arg.equals(reply))
+			fail("%s: got reply %s, want %s", desc, reply, arg);
+		if (meta == null)
+			fail("%s: got null response metadata", desc);
+
+		arg = new O();
+		arg.u32 = 404;
+		arg.s = "not found";
+		arg.b = true;
+		try {
+			client.call("Echo.Fail", arg, new O());
+			fail("%s: no exception for failure", desc);
+		} catch (RPCException e) {
+			if (e.code != 404 || ! "not found".equals(e.getMessage()) || ! e.retryable)
+				fail("%s: got exception code %d, message %s, retryable %b", desc, e.code, e.getMessage(), e.retryable);
+		}
+
+		try {
+			client.call("Echo.None", arg, new O());
+			fail("%s: no exception for unknown method", desc);
+		} catch (RPCException e) {
+			if (! e.getMessage().startsWith("rpc: can't find "))
+				fail("%s: got exception message %s for unknown method", desc, e.getMessage());
+		}
+
+		// connection still usable after failures
+		arg = new O();
+		arg.s = "again";
+		reply = new O();
+		client.call("Echo.Echo", arg, reply);
+		if (! arg.equals(reply))
+			fail("%s: got reply %s after failures, want %s", desc, reply, arg);
+	}
+
+}
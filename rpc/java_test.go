@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/colfer"
+	"github.com/pascaldekloe/colfer/rpc/gen"
+)
+
+// JavaFrames are the messages from the Java Codec in hex, as verified by
+// java/test.java. The Go codec must produce and consume the same.
+var javaFrames = []struct {
+	desc string
+	hex  string
+}{
+	{"request", "000101094563686f2e4563686f03027f" + "007f"},
+	{"request with metadata", "000201094563686f2e4563686f0302070100057472616365010254317f7f" + "007f"},
+	{"failure response", "000301094563686f2e4661696c02096e6f7420666f756e64049403057f"},
+}
+
+// TestJavaFrames covers the wire format of the Java Codec without javac.
+func TestJavaFrames(t *testing.T) {
+	frames := make([][]byte, len(javaFrames))
+	for i, gold := range javaFrames {
+		var err error
+		frames[i], err = hex.DecodeString(gold.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("write", func(t *testing.T) {
+		conn := new(mockConn)
+		c := NewClientCodec(conn, WithInterceptors(func(call *Call) (func(time.Duration, error), error) {
+			if call.Seq == 2 {
+				call.Request["trace"] = "T1"
+			}
+			return nil, nil
+		}))
+		for seq := uint64(1); seq <= 2; seq++ {
+			if err := c.WriteRequest(&rpc.Request{Seq: seq, ServiceMethod: "Echo.Echo"}, &gen.O{B: true}); err != nil {
+				t.Fatal("write error:", err)
+			}
+			if got, want := hex.EncodeToString(conn.buf.Next(conn.buf.Len())), javaFrames[seq-1].hex; got != want {
+				t.Errorf("%s: got 0x%s, want 0x%s", javaFrames[seq-1].desc, got, want)
+			}
+		}
+
+		s := NewServerCodec(conn)
+		failure := &Error{Code: 404, Message: "not found", Retryable: true}
+		if err := s.WriteResponse(&rpc.Response{Seq: 3, ServiceMethod: "Echo.Fail", Error: failure.Error()}, nil); err != nil {
+			t.Fatal("write error:", err)
+		}
+		if got, want := hex.EncodeToString(conn.buf.Bytes()), javaFrames[2].hex; got != want {
+			t.Errorf("%s: got 0x%s, want 0x%s", javaFrames[2].desc, got, want)
+		}
+	})
+
+	t.Run("read", func(t *testing.T) {
+		conn := new(mockConn)
+		s := NewServerCodec(conn).(*codec)
+		conn.buf.Write(frames[0])
+		conn.buf.Write(frames[1])
+		for seq := uint64(1); seq <= 2; seq++ {
+			var req rpc.Request
+			if err := s.ReadRequestHeader(&req); err != nil {
+				t.Fatal("read header error:", err)
+			}
+			if req.Seq != seq || req.ServiceMethod != "Echo.Echo" {
+				t.Errorf("%s: got sequence ID %d for method %q", javaFrames[seq-1].desc, req.Seq, req.ServiceMethod)
+			}
+			var body gen.O
+			if err := s.ReadRequestBody(&body); err != nil {
+				t.Fatal("read body error:", err)
+			}
+			if !body.B {
+				t.Errorf("%s: got body %+v", javaFrames[seq-1].desc, body)
+			}
+		}
+		if meta := s.header.Meta; len(meta) != 1 || meta[0].Key != "trace" || meta[0].Value != "T1" {
+			t.Errorf("%s: got metadata %+v", javaFrames[1].desc, meta)
+		}
+
+		c := NewClientCodec(conn)
+		conn.buf.Write(frames[2])
+		var res rpc.Response
+		if err := c.ReadResponseHeader(&res); err != nil {
+			t.Fatal("read header error:", err)
+		}
+		if err := c.ReadResponseBody(nil); err != nil {
+			t.Fatal("read body error:", err)
+		}
+		e := ParseError(rpc.ServerError(res.Error))
+		if e == nil || e.Code != 404 || e.Message != "not found" || !e.Retryable {
+			t.Errorf("%s: got error %q", javaFrames[2].desc, res.Error)
+		}
+	})
+}
+
+// TestJava runs java/test.java against a Go server.
+func TestJava(t *testing.T) {
+	for _, cmd := range []string{"javac", "java"} {
+		if _, err := exec.LookPath(cmd); err != nil {
+			t.Skipf("no %s available: %s", cmd, err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "colfer-rpc-java")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	packages, err := colfer.ParseFiles("../testdata/test.colf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packages {
+		p.SizeMax = "16 * 1024 * 1024"
+		p.ListMax = "64 * 1024"
+		p.Interfaces = []string{"net/quies/colfer/rpc/Colferable"}
+	}
+	if err := colfer.GenerateJava(dir, packages); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := filepath.Glob(filepath.Join(dir, "gen", "*.java"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pattern := range []string{
+		"java/net/quies/colfer/rpc/*.java",
+		"java/net/quies/colfer/rpc/internal/*.java",
+		"java/test.java",
+	} {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, paths...)
+	}
+	javac := exec.Command("javac", append([]string{"-d", dir}, sources...)...)
+	if out, err := javac.CombinedOutput(); err != nil {
+		t.Fatalf("javac: %s\n%s", err, out)
+	}
+
+	server := rpc.NewServer()
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(NewServerCodec(conn))
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	java := exec.Command("java", "-ea", "-cp", dir, "test", addr.IP.String(), strconv.Itoa(addr.Port))
+	if out, err := java.CombinedOutput(); err != nil {
+		t.Fatalf("java: %s\n%s", err, out)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/rpc"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pascaldekloe/colfer/rpc/internal"
 )
@@ -28,46 +30,128 @@ type codec struct {
 	// i is the index of the data end (exclusive) in buf.
 	i int
 
+	// readErr is pending from a read which completed a fill.
+	readErr error
+
 	// header holds the last received header. (reusable)
 	header internal.Header
+
+	// chain has the interceptors in order of execution.
+	chain []Interceptor
+
+	// pending has the intercepted calls per sequence ID.
+	pending      map[uint64]*pendingCall
+	pendingMutex sync.Mutex
+
+	// writeMutex serializes writes for interceptor rejections.
+	writeMutex sync.Mutex
+
+	// compress enables body compression from compressMin bytes.
+	compress    bool
+	compressMin int
+
+	// peerAccept is set [atomic] once the peer supports compression.
+	peerAccept int32
+
+	// origins are permitted for WebSocketHandler.
+	origins []string
 }
 
+// Option is a codec configuration.
+type Option func(*codec)
+
 // NewClientCodec returns a new RPC codec.
-func NewClientCodec(conn io.ReadWriteCloser) rpc.ClientCodec {
-	return &codec{
-		conn: conn,
-		buf:  make([]byte, 32*1024),
-	}
+func NewClientCodec(conn io.ReadWriteCloser, options ...Option) rpc.ClientCodec {
+	return newCodec(conn, options)
 }
 
 // NewServerCodec returns a new RPC codec.
-func NewServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
-	return &codec{
+func NewServerCodec(conn io.ReadWriteCloser, options ...Option) rpc.ServerCodec {
+	return newCodec(conn, options)
+}
+
+func newCodec(conn io.ReadWriteCloser, options []Option) *codec {
+	c := &codec{
 		conn: conn,
 		buf:  make([]byte, 32*1024),
 	}
+	for _, o := range options {
+		o(c)
+	}
+	if c.chain != nil {
+		c.pending = make(map[uint64]*pendingCall)
+	}
+	return c
 }
 
-func (c *codec) ReadRequestHeader(r *rpc.Request) error {
+// ReadHeader decodes the next header into c.header.
+func (c *codec) readHeader() error {
 	c.header = internal.Header{} // reset
 	if err := c.decode(&c.header); err != nil {
 		return err
 	}
-
-	r.ServiceMethod = c.header.Method
-	r.Seq = c.header.SeqID
+	if c.header.Accept&(1<<flateCompression) != 0 {
+		atomic.StoreInt32(&c.peerAccept, 1)
+	}
 	return nil
 }
 
+func (c *codec) ReadRequestHeader(r *rpc.Request) error {
+	for {
+		if err := c.readHeader(); err != nil {
+			return err
+		}
+
+		r.ServiceMethod = c.header.Method
+		r.Seq = c.header.SeqID
+		if c.chain == nil {
+			return nil
+		}
+
+		err := c.intercept(&Call{
+			Method:   c.header.Method,
+			Seq:      c.header.SeqID,
+			Request:  headerMeta(&c.header),
+			Response: make(Metadata),
+		})
+		if err == nil {
+			return nil
+		}
+
+		// reject without dispatch
+		if err := c.skip(int(c.header.BodySize)); err != nil {
+			return err
+		}
+		h := &internal.Header{
+			Method: c.header.Method,
+			SeqID:  c.header.SeqID,
+		}
+		setHeaderError(h, err.Error())
+		if err := c.encode(h, nil); err != nil {
+			return err
+		}
+	}
+}
+
 func (c *codec) ReadResponseHeader(r *rpc.Response) error {
-	c.header = internal.Header{} // reset
-	if err := c.decode(&c.header); err != nil {
+	if err := c.readHeader(); err != nil {
 		return err
 	}
 
 	r.ServiceMethod = c.header.Method
 	r.Seq = c.header.SeqID
-	r.Error = c.header.Error
+	r.Error = headerError(&c.header)
+
+	if c.chain != nil {
+		if p := c.take(r.Seq); p != nil {
+			p.call.Response = headerMeta(&c.header)
+			var err error
+			if r.Error != "" {
+				err = rpc.ServerError(r.Error)
+			}
+			p.done(err)
+		}
+	}
 	return nil
 }
 
@@ -81,7 +165,7 @@ func (c *codec) ReadRequestBody(body interface{}) error {
 	if !ok {
 		return fmt.Errorf("colfer/rpc: body type %T not a Colfer type", body)
 	}
-	return c.decode(b)
+	return c.decodeBody(b)
 }
 
 func (c *codec) ReadResponseBody(body interface{}) error {
@@ -94,7 +178,7 @@ func (c *codec) ReadResponseBody(body interface{}) error {
 	if !ok {
 		return fmt.Errorf("colfer/rpc: body type %T not a Colfer type", body)
 	}
-	return c.decode(b)
+	return c.decodeBody(b)
 }
 
 func (c *codec) WriteRequest(header *rpc.Request, body interface{}) error {
@@ -107,7 +191,27 @@ func (c *codec) WriteRequest(header *rpc.Request, body interface{}) error {
 	if !ok {
 		return fmt.Errorf("colfer/rpc: body type %T not a Colfer type", body)
 	}
-	return c.encode(h, b)
+
+	if c.chain == nil {
+		return c.encode(h, b)
+	}
+	call := &Call{
+		Method:  header.ServiceMethod,
+		Seq:     header.Seq,
+		Body:    body,
+		Request: make(Metadata),
+	}
+	if err := c.intercept(call); err != nil {
+		return err
+	}
+	setHeaderMeta(h, call.Request)
+	err := c.encode(h, b)
+	if err != nil {
+		if p := c.take(header.Seq); p != nil {
+			p.done(err)
+		}
+	}
+	return err
 }
 
 func (c *codec) WriteResponse(header *rpc.Response, body interface{}) error {
@@ -115,7 +219,22 @@ func (c *codec) WriteResponse(header *rpc.Response, body interface{}) error {
 	h := &internal.Header{
 		Method: header.ServiceMethod,
 		SeqID:  header.Seq,
-		Error:  header.Error,
+	}
+	setHeaderError(h, header.Error)
+	if c.chain != nil {
+		if p := c.take(header.Seq); p != nil {
+			var err error
+			if header.Error != "" {
+				err = rpc.ServerError(header.Error)
+			}
+			p.done(err)
+			setHeaderMeta(h, p.call.Response)
+		}
+	}
+
+	if header.Error != "" {
+		// net/rpc passes an invalid body on error
+		return c.encode(h, nil)
 	}
 	b, ok := body.(colferer)
 	if !ok {
@@ -128,10 +247,28 @@ func (c *codec) Close() error {
 	return c.conn.Close()
 }
 
+// Encode writes h followed by the (optional) body.
 func (c *codec) encode(h *internal.Header, body colferer) error {
-	bl, err := body.MarshalLen()
-	if err != nil {
-		return err
+	var bl int
+	if body != nil {
+		var err error
+		bl, err = body.MarshalLen()
+		if err != nil {
+			return err
+		}
+	}
+
+	var z []byte // compressed body
+	if c.compress {
+		h.Accept = 1 << flateCompression
+		if body != nil && bl >= c.compressMin && atomic.LoadInt32(&c.peerAccept) != 0 {
+			z = deflate(body, bl)
+			if z != nil {
+				h.Compression = flateCompression
+				h.RawSize = uint32(bl)
+				bl = len(z)
+			}
+		}
 	}
 
 	h.BodySize = uint32(bl)
@@ -143,8 +280,16 @@ func (c *codec) encode(h *internal.Header, body colferer) error {
 
 	buf := make([]byte, hl+bl)
 	h.MarshalTo(buf)
-	body.MarshalTo(buf[hl:])
+	if z != nil {
+		copy(buf[hl:], z)
+	} else if body != nil {
+		body.MarshalTo(buf[hl:])
+	}
 
+	if c.chain != nil {
+		c.writeMutex.Lock()
+		defer c.writeMutex.Unlock()
+	}
 	_, err = c.conn.Write(buf)
 	return err
 }
@@ -180,7 +325,7 @@ func (c *codec) decode(v colferer) error {
 			}
 		}
 
-		n, err := c.conn.Read(c.buf[c.i:])
+		n, err := c.read(c.buf[c.i:])
 		c.i += n
 		if err != nil {
 			return err
@@ -188,6 +333,69 @@ func (c *codec) decode(v colferer) error {
 	}
 }
 
+// DecodeBody reads the body of the last header received.
+func (c *codec) decodeBody(v colferer) error {
+	if c.header.Compression == 0 {
+		return c.decode(v)
+	}
+
+	data, err := c.inflate()
+	if err != nil {
+		return err
+	}
+	n, err := v.Unmarshal(data)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if n != len(data) {
+		return fmt.Errorf("colfer/rpc: %d bytes of body data remaining after decompression", len(data)-n)
+	}
+	return nil
+}
+
+// fill reads until n bytes are available in the buffer.
+func (c *codec) fill(n int) error {
+	for c.i-c.offset < n {
+		if c.offset+n > len(c.buf) {
+			if n > len(c.buf) {
+				// grow
+				bigger := make([]byte, n)
+				copy(bigger, c.buf[c.offset:c.i])
+				c.buf = bigger
+			} else {
+				// move data to start of buffer
+				copy(c.buf, c.buf[c.offset:c.i])
+			}
+			c.i -= c.offset
+			c.offset = 0
+		}
+
+		read, err := c.read(c.buf[c.i:])
+		c.i += read
+		if err != nil {
+			if c.i-c.offset >= n {
+				// report on next read
+				c.readErr = err
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// read is conn.Read with the pending error, if any, first.
+func (c *codec) read(p []byte) (n int, err error) {
+	if err := c.readErr; err != nil {
+		c.readErr = nil
+		return 0, err
+	}
+	return c.conn.Read(p)
+}
+
 // skip advances n bytes in the stream.
 func (c *codec) skip(n int) error {
 	for {
@@ -201,7 +409,7 @@ func (c *codec) skip(n int) error {
 		c.offset = 0
 
 		var err error
-		c.i, err = c.conn.Read(c.buf)
+		c.i, err = c.read(c.buf)
 		if err != nil {
 			return err
 		}
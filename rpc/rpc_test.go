@@ -2,14 +2,19 @@ package rpc
 
 import (
 	"bytes"
+	"compress/flate"
+	"fmt"
 	"io"
 	"net"
 	"net/rpc"
+	"reflect"
 	"strings"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/pascaldekloe/colfer/rpc/gen"
+	"github.com/pascaldekloe/colfer/rpc/internal"
 )
 
 type mockConn struct {
@@ -81,6 +86,262 @@ func TestResponse(t *testing.T) {
 	}
 }
 
+func TestErrorResponse(t *testing.T) {
+	conn := new(mockConn)
+	s := NewServerCodec(conn)
+	c := NewClientCodec(conn)
+
+	want := &Error{Code: 404, Message: "no such: thing", Retryable: true, Detail: []byte{1, 2, 0x7f}}
+	if err := s.WriteResponse(&rpc.Response{Seq: 42, Error: want.Error()}, &gen.O{}); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	if err := s.WriteResponse(&rpc.Response{Seq: 43, Error: "plain"}, &gen.O{}); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+
+	gotH := new(rpc.Response)
+	if err := c.ReadResponseHeader(gotH); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	if err := c.ReadResponseBody(nil); err != nil {
+		t.Fatalf("read body error: %s", err)
+	}
+	got := ParseError(rpc.ServerError(gotH.Error))
+	if got == nil {
+		t.Fatalf("got no structured error from %q", gotH.Error)
+	}
+	if got.Code != want.Code || got.Message != want.Message || got.Retryable != want.Retryable || !bytes.Equal(got.Detail, want.Detail) {
+		t.Errorf("got error %#v, want %#v", got, want)
+	}
+
+	if err := c.ReadResponseHeader(gotH); err != nil {
+		t.Fatalf("read header error: %s", err)
+	} else if gotH.Error != "plain" {
+		t.Errorf("got error %q, want %q", gotH.Error, "plain")
+	}
+	if got := ParseError(rpc.ServerError(gotH.Error)); got != nil {
+		t.Errorf("got structured error %#v for plain text", got)
+	}
+}
+
+// Echo is a test service.
+type Echo struct{}
+
+func (Echo) Echo(arg *gen.O, reply *gen.O) error {
+	*reply = *arg
+	return nil
+}
+
+// Fail returns an Error with the code in U32, the message in S and the
+// retryable flag in B.
+func (Echo) Fail(arg *gen.O, reply *gen.O) error {
+	return &Error{Code: arg.U32, Message: arg.S, Retryable: arg.B}
+}
+
+func TestInterceptors(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var serverLog []string
+	serverAuth := func(call *Call) (func(time.Duration, error), error) {
+		if call.Request["auth"] != "secret" {
+			return nil, &Error{Code: 401, Message: "denied"}
+		}
+		return nil, nil
+	}
+	serverTrace := func(call *Call) (func(time.Duration, error), error) {
+		return func(_ time.Duration, err error) {
+			call.Response["trace"] = call.Request["trace"]
+			serverLog = append(serverLog, fmt.Sprintf("%s %v", call.Method, err))
+		}, nil
+	}
+
+	var clientLog []string
+	clientAuth := func(call *Call) (func(time.Duration, error), error) {
+		if call.Body.(*gen.O).B {
+			call.Request["auth"] = "secret"
+		}
+		return nil, nil
+	}
+	clientTrace := func(call *Call) (func(time.Duration, error), error) {
+		call.Request["trace"] = fmt.Sprint("T", call.Seq)
+		return func(latency time.Duration, err error) {
+			if latency <= 0 {
+				t.Errorf("got latency %s", latency)
+			}
+			clientLog = append(clientLog, fmt.Sprintf("%s %q %v", call.Method, call.Response["trace"], ParseError(err)))
+		}, nil
+	}
+
+	cc, sc := net.Pipe()
+	go server.ServeCodec(NewServerCodec(sc, WithInterceptors(serverAuth, serverTrace)))
+	client := rpc.NewClientWithCodec(NewClientCodec(cc, WithInterceptors(clientAuth, clientTrace)))
+	defer client.Close()
+
+	reply := new(gen.O)
+	if err := client.Call("Echo.Echo", &gen.O{B: true, S: "hello"}, reply); err != nil {
+		t.Fatal("authorized call error:", err)
+	} else if reply.S != "hello" {
+		t.Errorf("got reply %q, want %q", reply.S, "hello")
+	}
+	err := client.Call("Echo.Echo", &gen.O{S: "hello"}, reply)
+	if e := ParseError(err); e == nil || e.Code != 401 {
+		t.Errorf("unauthorized call got error %v, want code 401", err)
+	}
+	if err := client.Call("Echo.Echo", &gen.O{B: true}, reply); err != nil {
+		t.Fatal("authorized call after rejection error:", err)
+	}
+
+	wantServerLog := []string{"Echo.Echo <nil>", "Echo.Echo <nil>"}
+	if !reflect.DeepEqual(serverLog, wantServerLog) {
+		t.Errorf("got server log %q, want %q", serverLog, wantServerLog)
+	}
+	wantClientLog := []string{
+		`Echo.Echo "T0" <nil>`,
+		`Echo.Echo "" colfer/rpc: code 401: denied`,
+		`Echo.Echo "T2" <nil>`,
+	}
+	if !reflect.DeepEqual(clientLog, wantClientLog) {
+		t.Errorf("got client log %q, want %q", clientLog, wantClientLog)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	conn := new(mockConn)
+	s := NewServerCodec(conn, WithCompression(1024))
+	c := NewClientCodec(conn, WithCompression(1024))
+
+	big := &gen.O{S: strings.Repeat("compress me ", 8*1024)}
+	bigLen, err := big.MarshalLen()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no compression until peer announced support
+	if err := c.WriteRequest(&rpc.Request{Seq: 1}, big); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	if n := conn.buf.Len(); n <= bigLen {
+		t.Errorf("first request took %d bytes, want more than %d", n, bigLen)
+	}
+	if err := s.ReadRequestHeader(new(rpc.Request)); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	got := new(gen.O)
+	if err := s.ReadRequestBody(got); err != nil {
+		t.Fatalf("read body error: %s", err)
+	} else if got.S != big.S {
+		t.Error("first request body mismatch")
+	}
+
+	// below threshold
+	if err := s.WriteResponse(&rpc.Response{Seq: 1}, &gen.O{S: "small"}); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	// compressed
+	if err := s.WriteResponse(&rpc.Response{Seq: 2}, big); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	if n := conn.buf.Len(); n >= bigLen/2 {
+		t.Errorf("responses took %d bytes, want less than %d", n, bigLen/2)
+	}
+
+	if err := c.ReadResponseHeader(new(rpc.Response)); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	if err := c.ReadResponseBody(got); err != nil {
+		t.Fatalf("read body error: %s", err)
+	} else if got.S != "small" {
+		t.Errorf("got small response body %q", got.S)
+	}
+	if err := c.ReadResponseHeader(new(rpc.Response)); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	if err := c.ReadResponseBody(got); err != nil {
+		t.Fatalf("read body error: %s", err)
+	} else if got.S != big.S {
+		t.Error("compressed response body mismatch")
+	}
+}
+
+// TestCompressionEOF checks a compressed body which completes with io.EOF.
+func TestCompressionEOF(t *testing.T) {
+	want := &gen.O{S: "last"}
+	raw, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var z bytes.Buffer
+	w, _ := flate.NewWriter(&z, flate.BestCompression)
+	w.Write(raw)
+	w.Close()
+
+	h := &internal.Header{
+		SeqID:       1,
+		BodySize:    uint32(z.Len()),
+		Compression: flateCompression,
+		RawSize:     uint32(len(raw)),
+	}
+	buf, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServerCodec(struct {
+		io.Reader
+		io.WriteCloser
+	}{
+		io.MultiReader(bytes.NewReader(buf), iotest.DataErrReader(bytes.NewReader(z.Bytes()))),
+		new(mockConn),
+	})
+
+	if err := s.ReadRequestHeader(new(rpc.Request)); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	got := new(gen.O)
+	if err := s.ReadRequestBody(got); err != nil {
+		t.Fatalf("read body error: %s", err)
+	} else if got.S != want.S {
+		t.Errorf("got body %q, want %q", got.S, want.S)
+	}
+	if err := s.ReadRequestHeader(new(rpc.Request)); err != io.EOF {
+		t.Errorf("got header error %v, want io.EOF", err)
+	}
+}
+
+// TestCompressionBomb checks the size limit for decompression.
+func TestCompressionBomb(t *testing.T) {
+	conn := new(mockConn)
+	s := NewServerCodec(conn)
+
+	var z bytes.Buffer
+	w, _ := flate.NewWriter(&z, flate.BestCompression)
+	w.Write(make([]byte, 1024*1024))
+	w.Close()
+
+	h := &internal.Header{
+		SeqID:       1,
+		BodySize:    uint32(z.Len()),
+		Compression: flateCompression,
+		RawSize:     100,
+	}
+	buf, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.buf.Write(buf)
+	conn.buf.Write(z.Bytes())
+
+	if err := s.ReadRequestHeader(new(rpc.Request)); err != nil {
+		t.Fatalf("read header error: %s", err)
+	}
+	err = s.ReadRequestBody(new(gen.O))
+	if want := "colfer/rpc: decompressed body exceeds 100 bytes"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
 // TestRequestBodySkip calls ReadRequestBody with nil.
 func TestRequestBodySkip(t *testing.T) {
 	conn := new(mockConn)
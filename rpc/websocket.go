@@ -0,0 +1,301 @@
+package rpc
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pascaldekloe/colfer/rpc/internal"
+)
+
+// WebSocketHandler returns a bridge to server for WebSocket clients, such as
+// the ColferRPC.js from colf(1). Each binary message holds exactly one request
+// or response, i.e., a header followed by the body.
+//
+// Browsers may connect from any web page. Requests with an Origin other than
+// the host requested are denied, unless permitted with WithOrigins.
+func WebSocketHandler(server *rpc.Server, options ...Option) http.Handler {
+	var config codec
+	for _, o := range options {
+		o(&config)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !config.originAllowed(r) {
+			http.Error(w, "WebSocket origin not allowed", http.StatusForbidden)
+			return
+		}
+		conn, err := upgradeWebSocket(w, r)
+		if err != nil {
+			return // error response sent
+		}
+		server.ServeCodec(NewServerCodec(conn, options...))
+	})
+}
+
+// WithOrigins permits WebSocket connections from web pages on other hosts.
+// Each origin is in the form of scheme "://" host [ ":" port ], as in the
+// Origin header. The wildcard "*" matches any origin. The option has no effect
+// on the codecs.
+func WithOrigins(origins ...string) Option {
+	return func(c *codec) {
+		c.origins = append(c.origins, origins...)
+	}
+}
+
+// OriginAllowed returns whether the request comes from either the same host,
+// an origin from WithOrigins, or a client other than a browser, i.e., without
+// an Origin header.
+func (c *codec) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, s := range c.origins {
+		if s == "*" || strings.EqualFold(s, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// webSocketGUID is the magic from RFC 6455, subsection 1.3.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket operation codes
+const (
+	wsContinuation = 0
+	wsText         = 1
+	wsBinary       = 2
+	wsClose        = 8
+	wsPing         = 9
+	wsPong         = 10
+)
+
+// WebSocketConn streams the payload of binary messages.
+type webSocketConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	// remaining is the number of payload bytes pending in the current frame.
+	remaining uint64
+	// mask is the key for the current frame.
+	mask [4]byte
+	// maskIndex is the position in mask for the next payload byte.
+	maskIndex int
+
+	// writeMutex serializes frames.
+	writeMutex sync.Mutex
+}
+
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*webSocketConn, error) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "WebSocket requires GET", http.StatusMethodNotAllowed)
+		return nil, errors.New("colfer/rpc: WebSocket method not GET")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("colfer/rpc: no WebSocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "WebSocket version 13 required", http.StatusUpgradeRequired)
+		return nil, errors.New("colfer/rpc: WebSocket version not supported")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "WebSocket key required", http.StatusBadRequest)
+		return nil, errors.New("colfer/rpc: no WebSocket key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("colfer/rpc: HTTP connection hijack not supported")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	digest := sha1.Sum([]byte(key + webSocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	rw.WriteString(base64.StdEncoding.EncodeToString(digest[:]))
+	rw.WriteString("\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &webSocketConn{conn: conn, r: rw.Reader}, nil
+}
+
+// HeaderHasToken returns whether the comma-separated list in the header
+// contains token, case-insensitive.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Read honors the io.Reader interface. Message boundaries are not preserved.
+func (c *webSocketConn) Read(buf []byte) (n int, err error) {
+	for c.remaining == 0 {
+		if err := c.nextFrame(); err != nil {
+			return 0, err
+		}
+	}
+
+	if uint64(len(buf)) > c.remaining {
+		buf = buf[:c.remaining]
+	}
+	n, err = c.r.Read(buf)
+	for i := range buf[:n] {
+		buf[i] ^= c.mask[c.maskIndex]
+		c.maskIndex = (c.maskIndex + 1) & 3
+	}
+	c.remaining -= uint64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// NextFrame reads up until the payload of the next data frame. Control frames
+// are dealt with in place.
+func (c *webSocketConn) nextFrame() error {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return err
+	}
+	opcode := head[0] & 0xf
+	if head[1]&0x80 == 0 {
+		c.closeWith(1002)
+		return errors.New("colfer/rpc: unmasked WebSocket frame from client")
+	}
+
+	size := uint64(head[1] & 0x7f)
+	switch size {
+	case 126:
+		var buf [2]byte
+		if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		size = uint64(binary.BigEndian.Uint16(buf[:]))
+	case 127:
+		var buf [8]byte
+		if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		size = binary.BigEndian.Uint64(buf[:])
+	}
+	if size > uint64(internal.ColferSizeMax) {
+		c.closeWith(1009)
+		return fmt.Errorf("colfer/rpc: WebSocket frame size %d exceeds %d bytes", size, internal.ColferSizeMax)
+	}
+	if _, err := io.ReadFull(c.r, c.mask[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	c.maskIndex = 0
+
+	switch opcode {
+	case wsContinuation, wsBinary:
+		c.remaining = size
+		return nil
+
+	case wsPing, wsPong, wsClose:
+		if size > 125 {
+			c.closeWith(1002)
+			return errors.New("colfer/rpc: WebSocket control frame exceeds 125 bytes")
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return unexpectedEOF(err)
+		}
+		for i := range payload {
+			payload[i] ^= c.mask[i&3]
+		}
+
+		switch opcode {
+		case wsPing:
+			return c.writeFrame(wsPong, payload)
+		case wsClose:
+			c.writeFrame(wsClose, payload)
+			return io.EOF
+		}
+		return nil
+
+	default:
+		c.closeWith(1003)
+		return fmt.Errorf("colfer/rpc: WebSocket opcode %d not supported", opcode)
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Write honors the io.Writer interface. Each call produces one binary message.
+func (c *webSocketConn) Write(buf []byte) (n int, err error) {
+	if err := c.writeFrame(wsBinary, buf); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	head := make([]byte, 2, 10+len(payload))
+	head[0] = 0x80 | opcode // FIN
+	switch l := len(payload); {
+	case l < 126:
+		head[1] = byte(l)
+	case l <= 0xffff:
+		head[1] = 126
+		head = append(head, byte(l>>8), byte(l))
+	default:
+		head[1] = 127
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(l))
+		head = append(head, buf[:]...)
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err := c.conn.Write(append(head, payload...))
+	return err
+}
+
+// CloseWith sends a closure with status and closes the connection.
+func (c *webSocketConn) closeWith(status uint16) error {
+	c.writeFrame(wsClose, []byte{byte(status >> 8), byte(status)})
+	return c.conn.Close()
+}
+
+// Close honors the io.Closer interface.
+func (c *webSocketConn) Close() error {
+	return c.closeWith(1000)
+}
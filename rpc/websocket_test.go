@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"testing"
+)
+
+func TestWebSocketOrigin(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	strict := httptest.NewServer(WebSocketHandler(server))
+	defer strict.Close()
+	permit := httptest.NewServer(WebSocketHandler(server, WithOrigins("https://example.com")))
+	defer permit.Close()
+
+	golden := []struct {
+		server *httptest.Server
+		origin string
+		want   int
+	}{
+		{strict, "", http.StatusSwitchingProtocols},
+		{strict, strict.URL, http.StatusSwitchingProtocols},
+		{strict, "https://example.com", http.StatusForbidden},
+		{strict, "null", http.StatusForbidden},
+		{permit, "https://example.com", http.StatusSwitchingProtocols},
+		{permit, "HTTPS://EXAMPLE.COM", http.StatusSwitchingProtocols},
+		{permit, "https://example.com:8443", http.StatusForbidden},
+		{permit, permit.URL, http.StatusSwitchingProtocols},
+	}
+	for _, gold := range golden {
+		req, err := http.NewRequest(http.MethodGet, gold.server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		if gold.origin != "" {
+			req.Header.Set("Origin", gold.origin)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != gold.want {
+			t.Errorf("origin %q got status %d, want %d", gold.origin, res.StatusCode, gold.want)
+		}
+	}
+}
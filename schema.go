@@ -6,9 +6,13 @@ import (
 	"go/ast"
 	"go/format"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"path"
+	"strings"
 )
 
 // FormatFile normalizes the structure.
@@ -19,7 +23,7 @@ func FormatFile(path string) (changed bool, err error) {
 		return false, err
 	}
 
-	clean, err := format.Source(orig)
+	clean, err := Format(orig)
 	if err != nil {
 		return false, fmt.Errorf("colfer: format %q: %s", path, err)
 	}
@@ -34,14 +38,98 @@ func FormatFile(path string) (changed bool, err error) {
 	return true, nil
 }
 
-// ParseFiles returns the schema definitions.
+// Format returns the normalized structure of a schema.
+// The content is expected to be syntactically correct.
+func Format(src []byte) ([]byte, error) {
+	return format.Source(src)
+}
+
+// schemaSource is a named input for the parser.
+type schemaSource struct {
+	path string
+	// src is the content as accepted by parser.ParseFile.
+	// Nil reads from path.
+	src interface{}
+}
+
+// ParseFiles returns the schema definitions. Errors in the schema are reported
+// as a scanner.ErrorList, with an entry per issue found.
 func ParseFiles(paths ...string) (Packages, error) {
+	sources := make([]schemaSource, len(paths))
+	for i, path := range paths {
+		sources[i].path = path
+	}
+	return parse(sources)
+}
+
+// ParseReader returns the schema definitions from r. The name is used for
+// error reporting and for the Package SchemaFiles.
+func ParseReader(name string, r io.Reader) (Packages, error) {
+	return parse([]schemaSource{{path: name, src: r}})
+}
+
+// ParseFS returns the schema definitions from all files in fsys which match
+// any of the patterns, in the syntax of fs.Glob. Without patterns, all files
+// with a .colf extension in the root directory are used. Each pattern must
+// match at least one file.
+func ParseFS(fsys fs.FS, patterns ...string) (Packages, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"*.colf"}
+	}
+
+	var sources []schemaSource
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("colfer: pattern %q matches no files", pattern)
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			src, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, schemaSource{path: name, src: src})
+		}
+	}
+	return parse(sources)
+}
+
+// Parse returns the schema definitions. Any errors are collected as a
+// scanner.ErrorList, with the file positions.
+func parse(sources []schemaSource) (Packages, error) {
 	var packages Packages
+	var errs scanner.ErrorList
+
+	// Definitions from files with syntax errors are missing. References
+	// into such packages are not resolved, to prevent error cascades.
+	// The empty string applies to any package.
+	incomplete := make(map[string]bool)
+	// invalid has the fields with an error reported already.
+	invalid := make(map[*Field]bool)
 
 	fileSet := token.NewFileSet()
-	for _, schemaPath := range paths {
-		fileAST, err := parser.ParseFile(fileSet, schemaPath, nil, parser.ParseComments|parser.AllErrors)
+	for _, source := range sources {
+		schemaPath := source.path
+		fileAST, err := parser.ParseFile(fileSet, schemaPath, source.src, parser.ParseComments|parser.AllErrors)
 		if err != nil {
+			if list, ok := err.(scanner.ErrorList); ok {
+				errs = append(errs, list...)
+				if fileAST != nil && fileAST.Name != nil && fileAST.Name.Name != "_" {
+					incomplete[fileAST.Name.Name] = true
+				} else {
+					incomplete[""] = true
+				}
+				continue
+			}
 			return nil, err
 		}
 
@@ -53,7 +141,7 @@ func ParseFiles(paths ...string) (Packages, error) {
 			}
 		}
 		if pkg == nil {
-			pkg = &Package{Name: fileAST.Name.Name}
+			pkg = &Package{Name: fileAST.Name.Name, Pos: fileSet.Position(fileAST.Name.Pos())}
 			packages = append(packages, pkg)
 		}
 
@@ -61,16 +149,16 @@ func ParseFiles(paths ...string) (Packages, error) {
 
 		pkg.Docs = append(pkg.Docs, docs(fileAST.Doc)...)
 
+		m := &schemaMapper{fileSet: fileSet, errs: &errs, invalid: invalid, schemaPath: schemaPath}
+
 		// switch through the AST types
 		for _, decl := range fileAST.Decls {
 			switch decl := decl.(type) {
 			default:
-				return nil, fmt.Errorf("colfer: unsupported declaration type %T", decl)
+				m.errorf(decl, "unsupported declaration type %T", decl)
 			case *ast.GenDecl:
 				for _, spec := range decl.Specs {
-					if err := addSpec(pkg, decl, spec, schemaPath); err != nil {
-						return nil, err
-					}
+					m.addSpec(pkg, decl, spec)
 				}
 			}
 		}
@@ -81,7 +169,8 @@ func ParseFiles(paths ...string) (Packages, error) {
 		for _, t := range pkg.Structs {
 			qname := t.String()
 			if dupe, ok := names[qname]; ok {
-				return nil, fmt.Errorf("colfer: duplicate struct definition %q in file %s and %s", qname, dupe.SchemaFile, t.SchemaFile)
+				errs.Add(t.Pos, fmt.Sprintf("duplicate struct definition %q; previous declaration at %s", qname, dupe.Pos))
+				continue
 			}
 			names[qname] = t
 		}
@@ -90,6 +179,9 @@ func ParseFiles(paths ...string) (Packages, error) {
 	for _, pkg := range packages {
 		for _, t := range pkg.Structs {
 			for _, f := range t.Fields {
+				if invalid[f] {
+					continue
+				}
 				_, ok := datatypes[f.Type]
 				if ok {
 					if f.TypeList {
@@ -98,7 +190,7 @@ func ParseFiles(paths ...string) (Packages, error) {
 							fmt.Println("colfer: WARNING: integer lists are Go only at the moment")
 						case "float32", "float64", "text", "binary":
 						default:
-							return nil, fmt.Errorf("colfer: unsupported lists type %q for field %s", t, f)
+							errs.Add(f.Pos, fmt.Sprintf("unsupported lists type %q for field %s", f.Type, f))
 						}
 					}
 					continue
@@ -109,57 +201,100 @@ func ParseFiles(paths ...string) (Packages, error) {
 				if f.TypeRef, ok = names[pkg.Name+"."+f.Type]; ok {
 					continue
 				}
-				return nil, fmt.Errorf("colfer: unknown datatype %q for field %s", f.Type, f)
+				refPkg := pkg.Name
+				if i := strings.LastIndexByte(f.Type, '.'); i >= 0 {
+					refPkg = f.Type[:i]
+				}
+				if incomplete[refPkg] || incomplete[""] {
+					continue
+				}
+				errs.Add(f.Pos, fmt.Sprintf("unknown datatype %q for field %s", f.Type, f))
 			}
 		}
 	}
 
+	if len(errs) != 0 {
+		errs.Sort()
+		return nil, errs
+	}
 	return packages, nil
 }
 
-func addSpec(pkg *Package, decl *ast.GenDecl, spec ast.Spec, schemaPath string) error {
+// SchemaMapper collects the errors from a schema file.
+type schemaMapper struct {
+	fileSet    *token.FileSet
+	errs       *scanner.ErrorList
+	invalid    map[*Field]bool
+	schemaPath string
+}
+
+func (m *schemaMapper) errorf(node ast.Node, format string, args ...interface{}) {
+	m.errs.Add(m.fileSet.Position(node.Pos()), fmt.Sprintf(format, args...))
+}
+
+// FieldErrorf is errorf with the exclusion of f from further checks.
+func (m *schemaMapper) fieldErrorf(f *Field, node ast.Node, format string, args ...interface{}) {
+	m.invalid[f] = true
+	m.errorf(node, format, args...)
+}
+
+func (m *schemaMapper) addSpec(pkg *Package, decl *ast.GenDecl, spec ast.Spec) {
 	switch spec := spec.(type) {
 	default:
-		return fmt.Errorf("colfer: unsupported specification type %T", spec)
+		m.errorf(spec, "unsupported specification type %T", spec)
 	case *ast.TypeSpec:
 		switch specType := spec.Type.(type) {
 		default:
-			return fmt.Errorf("colfer: unsupported data type %T", specType)
+			m.errorf(spec.Type, "unsupported data type %T", specType)
 		case *ast.StructType:
-			t := &Struct{Pkg: pkg, Name: spec.Name.Name, SchemaFile: path.Base(schemaPath)}
+			t := &Struct{Pkg: pkg, Name: spec.Name.Name, SchemaFile: path.Base(m.schemaPath), Pos: m.fileSet.Position(spec.Name.Pos())}
 			for _, pt := range pkg.Structs {
 				if pt.Name == t.Name {
-					return fmt.Errorf("colfer: duplicate %s declaration", pt)
+					m.errorf(spec.Name, "duplicate %s declaration; previous declaration at %s", pt, pt.Pos)
+					return
 				}
 			}
 			pkg.Structs = append(pkg.Structs, t)
 
 			t.Docs = append(docs(decl.Doc), docs(spec.Doc)...)
-			if err := mapStruct(t, specType); err != nil {
-				return err
-			}
+			m.mapStruct(t, specType)
 		}
 	}
-
-	return nil
 }
 
-func mapStruct(dst *Struct, src *ast.StructType) error {
+// FieldMax is the upper limit for the number of fields per struct, as the
+// index must fit the 7-bit header while 127 marks the end of the serial.
+const fieldMax = 127
+
+// MapStruct reads the fields from src. Struct references need no recursion
+// check, as they are optional (a pointer or a nullable) in all languages.
+func (m *schemaMapper) mapStruct(dst *Struct, src *ast.StructType) {
 	if len(src.Fields.List) == 0 {
-		return fmt.Errorf("colfer: %s has no fields", dst)
+		m.errorf(src, "%s has no fields", dst)
+		return
+	}
+	if len(src.Fields.List) > fieldMax {
+		m.errorf(src.Fields.List[fieldMax], "%s has %d fields, which exceeds the maximum of %d", dst, len(src.Fields.List), fieldMax)
 	}
 
+	names := make(map[string]*Field, len(src.Fields.List))
 	for i, f := range src.Fields.List {
-		field := &Field{Struct: dst, Index: i}
-		dst.Fields = append(dst.Fields, field)
-
 		if len(f.Names) == 0 {
-			return fmt.Errorf("colfer: field %d from %s has no name", i, dst)
+			m.errorf(f, "field %d from %s has no name", i, dst)
+			continue
 		}
+		field := &Field{Struct: dst, Index: i, Pos: m.fileSet.Position(f.Pos())}
+		dst.Fields = append(dst.Fields, field)
+
 		field.Name = f.Names[0].Name
+		if dupe, ok := names[field.Name]; ok {
+			m.fieldErrorf(field, f, "duplicate field %s; previous declaration at %s", field, dupe.Pos)
+		} else {
+			names[field.Name] = field
+		}
 
 		if f.Tag != nil {
-			return fmt.Errorf("colfer: illegal tag %s on %s", f.Tag.Value, field)
+			m.fieldErrorf(field, f.Tag, "illegal tag %s on %s", f.Tag.Value, field)
 		}
 
 		field.Docs = docs(f.Doc)
@@ -178,16 +313,14 @@ func mapStruct(dst *Struct, src *ast.StructType) error {
 				case *ast.Ident:
 					field.Type = pkgIdent.Name + "." + t.Sel.Name
 				default:
-					return fmt.Errorf("colfer: unknown datatype selector expression %T for field %s", pkgIdent, field)
+					m.fieldErrorf(field, t.X, "unknown datatype selector expression %T for field %s", pkgIdent, field)
 				}
 			default:
-				return fmt.Errorf("colfer: unknown datatype declaration %T for field %s", t, field)
+				m.fieldErrorf(field, expr, "unknown datatype declaration %T for field %s", t, field)
 			}
 			break
 		}
 	}
-
-	return nil
 }
 
 func docs(g *ast.CommentGroup) []string {